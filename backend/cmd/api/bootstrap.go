@@ -0,0 +1,59 @@
+package main
+
+import (
+	"crypto/rand"
+	"dbapp/internal/config"
+	"dbapp/internal/service"
+	"dbapp/pkg/logger"
+	"fmt"
+	"math/big"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// passwordAlphabet 生成密码使用的字符，去掉了容易混淆的 0/O、1/l/I
+const passwordAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// bootstrapAdmin 数据库中没有管理员时创建管理员。
+// 未配置密码时生成随机密码，直接输出到标准错误而不写入日志，避免密码进入日志系统，且只在创建时输出这一次。
+func bootstrapAdmin(cfg config.BootstrapAdminConfig, userService *service.UserService) error {
+	password := cfg.Password
+	generated := password == ""
+	if generated {
+		var err error
+		if password, err = generatePassword(20); err != nil {
+			return fmt.Errorf("生成管理员密码失败: %w", err)
+		}
+	}
+
+	created, err := userService.EnsureAdmin(cfg.Username, cfg.Email, password)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return nil
+	}
+
+	logger.Info("管理员用户创建成功", zap.String("username", cfg.Username))
+	if generated {
+		fmt.Fprintf(os.Stderr, "\n==================================================\n"+
+			"已创建管理员账户，密码只显示这一次，请登录后立即修改:\n"+
+			"  用户名: %s\n  密码:   %s\n"+
+			"==================================================\n\n", cfg.Username, password)
+	}
+	return nil
+}
+
+func generatePassword(length int) (string, error) {
+	b := make([]byte, length)
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
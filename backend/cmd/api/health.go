@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"dbapp/internal/config"
+	"dbapp/pkg/database"
+	"dbapp/pkg/health"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newHealthChecker 注册就绪检查的依赖项
+func newHealthChecker(cfg *config.Config, db *gorm.DB) (*health.Checker, error) {
+	// 迁移执行器在启动时创建一次，每次检查只执行一条只读查询
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := health.NewChecker(2 * time.Second)
+
+	checker.Add("database", true, func(ctx context.Context) (string, error) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return "", err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return "", err
+		}
+		stats := sqlDB.Stats()
+		return fmt.Sprintf("open=%d in_use=%d idle=%d", stats.OpenConnections, stats.InUse, stats.Idle), nil
+	})
+
+	// 有未执行的迁移时表结构与代码不一致，不接收流量
+	checker.Add("migrations", true, func(ctx context.Context) (string, error) {
+		version, err := migrator.AppliedVersion(ctx)
+		if err != nil {
+			return "", err
+		}
+		detail := fmt.Sprintf("version=%d latest=%d", version, migrator.Latest())
+		if version < migrator.Latest() {
+			return detail, fmt.Errorf("存在未执行的迁移")
+		}
+		return detail, nil
+	})
+
+	// Redis 不可用时缓存退化为内存缓存、限流放行，服务仍可用
+	if cfg.Cache.Driver == "redis" || (cfg.RateLimit.Enabled && cfg.RateLimit.Driver == "redis") {
+		checker.Add("redis", false, func(ctx context.Context) (string, error) {
+			client := database.GetRedis()
+			if client == nil {
+				return "", fmt.Errorf("Redis未初始化")
+			}
+			return "", client.Ping(ctx).Err()
+		})
+	}
+
+	uploadPath := cfg.File.UploadPath
+	if uploadPath == "" {
+		uploadPath = "./uploads"
+	}
+	checker.Add("upload_dir", true, func(ctx context.Context) (string, error) {
+		if err := os.MkdirAll(uploadPath, 0755); err != nil {
+			return "", fmt.Errorf("创建上传目录失败: %w", err)
+		}
+		f, err := os.CreateTemp(uploadPath, ".readyz-*")
+		if err != nil {
+			return "", fmt.Errorf("上传目录不可写: %w", err)
+		}
+		name := f.Name()
+		f.Close()
+		return uploadPath, os.Remove(name)
+	})
+
+	return checker, nil
+}
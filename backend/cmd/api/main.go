@@ -1,29 +1,51 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"dbapp/internal/config"
 	"dbapp/internal/handler"
 	"dbapp/internal/middleware"
 	"dbapp/internal/model"
 	"dbapp/internal/repository"
 	"dbapp/internal/service"
+	"dbapp/pkg/cache"
 	"dbapp/pkg/database"
 	"dbapp/pkg/logger"
+	"dbapp/pkg/metrics"
+	"dbapp/pkg/ratelimit"
+	"dbapp/pkg/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func main() {
 	// 加载配置
+	// 此时日志尚未初始化，配置错误直接输出到标准错误
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		panic("加载配置失败: " + err.Error())
+		fmt.Fprintln(os.Stderr, "加载配置失败:", err)
+		os.Exit(1)
 	}
 
 	// 初始化日志
 	logger.Init(cfg.Server.Mode)
+	if err := logger.SetLevel(cfg.Log.Level, cfg.Server.Mode); err != nil {
+		logger.Warn("设置日志级别失败", zap.String("error", err.Error()))
+	}
+
+	// 运行时可重新加载的配置（限流规则、上传限制、日志级别等）
+	reloader := config.NewReloader(cfg)
+
+	if cfg.JWT.Secret == config.DefaultJWTSecret {
+		logger.Warn("未配置 JWT_SECRET，使用默认密钥，仅限开发环境使用")
+	}
 
 	// 设置Gin模式
 	gin.SetMode(cfg.Server.Mode)
@@ -34,118 +56,265 @@ func main() {
 		logger.Fatal("初始化数据库失败", zap.String("error", err.Error()))
 	}
 
-	// 自动迁移数据库表结构
-	// 可以通过环境变量 AUTO_MIGRATE=false 来禁用自动迁移
+	// 数据库连接池和SQL耗时指标
+	if err := database.RegisterMetrics(db, metrics.Default); err != nil {
+		logger.Warn("注册数据库指标失败", zap.String("error", err.Error()))
+	}
+
+	// migrate 子命令: api migrate up|down [n]|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			logger.Fatal("数据库迁移失败", zap.String("error", err.Error()))
+		}
+		return
+	}
+
+	// reconcile 子命令: api reconcile，修正计数字段
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		if err := runReconcile(db); err != nil {
+			logger.Fatal("校准计数失败", zap.String("error", err.Error()))
+		}
+		return
+	}
+
+	// 启动时执行未执行的迁移
+	// 可以通过环境变量 AUTO_MIGRATE=false 来禁用，改为部署时单独执行 api migrate up
 	autoMigrate := os.Getenv("AUTO_MIGRATE")
 	if autoMigrate != "false" {
 		logger.Info("开始执行数据库迁移...")
-		if err := db.AutoMigrate(
-			&model.User{},
-			&model.Article{},
-			&model.Category{},
-			&model.Tag{},
-			&model.Comment{},
-			&model.Like{},
-			&model.ArticleImage{},
-		); err != nil {
+		if err := runMigrate(db, []string{"up"}); err != nil {
 			logger.Fatal("数据库迁移失败", zap.String("error", err.Error()))
-		} else {
-			logger.Info("数据库迁移成功")
 		}
 	}
 
-	// 初始化Repository
-	userRepo := repository.NewUserRepository(db)
-
-	// 创建默认管理员用户
-	_, err = userRepo.GetByUsername("root")
+	// 链路追踪
+	shutdownTracing, err := tracing.Init(cfg.Tracing)
 	if err != nil {
-		// 管理员用户不存在，创建它
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte("qweasdzxc"), bcrypt.DefaultCost)
-		if err != nil {
-			logger.Error("创建管理员用户失败：密码加密失败", zap.String("error", err.Error()))
-		} else {
-			adminUser := &model.User{
-				Username:     "root",
-				Email:        "admin@dbapp.local",
-				PasswordHash: string(hashedPassword),
-				Nickname:     "管理员",
-				Role:         "admin",
-				Status:       "active",
-			}
-			if err := userRepo.Create(adminUser); err != nil {
-				logger.Error("创建管理员用户失败", zap.String("error", err.Error()))
-			} else {
-				logger.Info("管理员用户创建成功", zap.String("username", "root"))
-			}
+		logger.Fatal("初始化链路追踪失败", zap.String("error", err.Error()))
+	}
+	if cfg.Tracing.Enabled {
+		if err := database.RegisterTracing(db); err != nil {
+			logger.Warn("注册SQL追踪失败", zap.String("error", err.Error()))
 		}
-	} else {
-		logger.Info("管理员用户已存在", zap.String("username", "root"))
+		logger.Info("链路追踪已启用", zap.String("exporter", cfg.Tracing.Exporter))
 	}
+
+	// 初始化Repository
+	userRepo := repository.NewUserRepository(db)
+
 	articleRepo := repository.NewArticleRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
 	tagRepo := repository.NewTagRepository(db)
 	commentRepo := repository.NewCommentRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
+	fileRepo := repository.NewFileRepository(db)
+	settingRepo := repository.NewSettingRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	trashRepo := repository.NewTrashRepository(db)
+	seriesRepo := repository.NewSeriesRepository(db)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	readingListRepo := repository.NewReadingListRepository(db)
+
+	// 缓存和限流使用同一个Redis连接
+	var redisClient *redis.Client
+	if cfg.Cache.Driver == "redis" || (cfg.RateLimit.Enabled && cfg.RateLimit.Driver == "redis") {
+		redisClient, err = database.InitRedis(cfg.Redis)
+		if err != nil {
+			logger.Warn("Redis不可用，缓存和限流使用进程内存储", zap.String("error", err.Error()))
+		} else if cfg.Tracing.Enabled {
+			database.TraceRedis(redisClient)
+		}
+	}
+
+	// 初始化缓存，Redis不可用时退化为进程内缓存
+	cacheTTL := time.Duration(cfg.Cache.TTL) * time.Second
+	var appCache cache.Cache = cache.NewMemoryCache(cacheTTL)
+	if cfg.Cache.Driver == "redis" && redisClient != nil {
+		appCache = cache.NewRedisCache(redisClient, "dbapp:cache:", cacheTTL)
+	}
+
+	// 接口限流，Redis不可用时每个实例单独计数
+	var limiter ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		limiter = ratelimit.NewMemoryLimiter()
+		if cfg.RateLimit.Driver == "redis" && redisClient != nil {
+			limiter = ratelimit.NewRedisLimiter(redisClient, "dbapp:ratelimit:")
+		}
+	}
+	rateLimit := func(name string) gin.HandlerFunc {
+		return middleware.RateLimitMiddleware(limiter, name, func() ratelimit.Rule {
+			rule := reloader.Current().RateLimit.Rules[name]
+			return ratelimit.Rule{
+				Requests: rule.Requests,
+				Period:   time.Duration(rule.Period) * time.Second,
+				Burst:    rule.Burst,
+			}
+		})
+	}
+
+	// 浏览计数器，定期批量写入数据库
+	viewCounter := service.NewViewCounter(articleRepo, time.Duration(cfg.View.DedupeWindow)*time.Second)
+	viewCounter.Start(time.Duration(cfg.View.FlushInterval) * time.Second)
 
 	// 初始化Service
-	userService := service.NewUserService(userRepo)
-	articleService := service.NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
-	tagService := service.NewTagService(tagRepo)
-	commentService := service.NewCommentService(commentRepo, articleRepo, likeRepo)
-	likeService := service.NewLikeService(likeRepo, articleRepo, commentRepo)
+	loginGuard := service.NewLoginGuard(appCache, cfg.RateLimit.Lockout)
+	auditService := service.NewAuditService(auditLogRepo)
+	settingService := service.NewSettingService(settingRepo, appCache, cfg.App.Name)
+	userService := service.NewUserService(userRepo, loginGuard, settingService, auditService)
+	articleService := service.NewArticleService(service.ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		BookmarkRepo:     bookmarkRepo,
+		ArticleImageRepo: articleImageRepo,
+		SeriesRepo:       seriesRepo,
+		Cache:            appCache,
+		ViewCounter:      viewCounter,
+		Settings:         settingService,
+		Audit:            auditService,
+	})
+	categoryService := service.NewCategoryService(categoryRepo, appCache, auditService)
+	tagService := service.NewTagService(tagRepo, appCache, auditService)
+	commentService := service.NewCommentService(commentRepo, articleRepo, likeRepo, appCache, settingService, auditService)
+	commentService.UpdateConfig(cfg.Comment)
+	likeService := service.NewLikeService(likeRepo, articleRepo, commentRepo, appCache)
+	uploadService := service.NewUploadService(cfg.File, fileRepo, auditService)
+	trashService := service.NewTrashService(userRepo, articleRepo, commentRepo, trashRepo, appCache,
+		time.Duration(cfg.Trash.RetentionDays)*24*time.Hour, auditService)
+	seriesService := service.NewSeriesService(seriesRepo, articleRepo, auditService)
+	bookmarkService := service.NewBookmarkService(bookmarkRepo, readingListRepo, articleRepo, likeRepo)
+
+	// 首次启动时创建管理员
+	if err := bootstrapAdmin(cfg.BootstrapAdmin, userService); err != nil {
+		logger.Fatal("创建管理员失败", zap.String("error", err.Error()))
+	}
+
+	// 配置重新加载后更新各组件
+	reloader.OnChange(func(old, new *config.Config) {
+		loginGuard.SetConfig(new.RateLimit.Lockout)
+		uploadService.UpdateConfig(new.File)
+		commentService.UpdateConfig(new.Comment)
+		if err := logger.SetLevel(new.Log.Level, new.Server.Mode); err != nil {
+			logger.Warn("设置日志级别失败", zap.String("error", err.Error()))
+		}
+	})
+	stopConfigWatch := watchConfig(reloader)
+
+	// 定期清理过期的分片上传会话
+	stopUploadCleanup := uploadService.StartCleanup(time.Hour)
+
+	// 定期彻底删除回收站中超过保留时间的记录
+	stopTrashPurge := trashService.StartPurge(time.Duration(cfg.Trash.PurgeInterval) * time.Second)
 
 	// 初始化Handler
-	authHandler := handler.NewAuthHandler(userService)
+	authHandler := handler.NewAuthHandler(userService, cfg.Security.Session)
 	articleHandler := handler.NewArticleHandler(articleService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 	tagHandler := handler.NewTagHandler(tagService)
 	commentHandler := handler.NewCommentHandler(commentService)
 	likeHandler := handler.NewLikeHandler(likeService)
-	fileHandler := handler.NewFileHandler(cfg)
+	fileHandler := handler.NewFileHandler(uploadService)
+	settingHandler := handler.NewSettingHandler(settingService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	trashHandler := handler.NewTrashHandler(trashService)
+	seriesHandler := handler.NewSeriesHandler(seriesService)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkService)
+	healthChecker, err := newHealthChecker(cfg, db)
+	if err != nil {
+		logger.Fatal("初始化健康检查失败", zap.String("error", err.Error()))
+	}
+	healthHandler := handler.NewHealthHandler(healthChecker)
 
 	// 初始化路由
-	router := gin.Default()
+	// 请求日志和panic恢复由自定义中间件处理，不使用 gin.Default() 自带的
+	router := gin.New()
+	// 只信任配置的反向代理转发的 X-Forwarded-For，否则客户端可以伪造IP绕过限流
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Fatal("可信代理配置错误", zap.String("error", err.Error()))
+	}
 
 	// 中间件
-	router.Use(middleware.CORSMiddleware())
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.TracingMiddleware())
+	router.Use(middleware.MetricsMiddleware())
 	router.Use(middleware.LoggerMiddleware())
 	router.Use(middleware.RecoveryMiddleware())
+	router.Use(middleware.CORSMiddleware(cfg.CORS))
+	router.Use(middleware.SecurityHeadersMiddleware(cfg.Security.Headers))
 
 	// 健康检查
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-		})
-	})
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	router.GET("/version", healthHandler.Version)
+	// Prometheus指标，仅供内网采集，Nginx不转发
+	router.GET("/metrics", gin.WrapH(metrics.Default.Handler()))
+	// 兼容旧的健康检查地址
+	router.GET("/health", healthHandler.Livez)
 
 	// API路由
 	api := router.Group("/api/v1")
+	api.Use(rateLimit("default"))
+	api.Use(middleware.AuditMiddleware())
 	{
 		// 认证路由
 		auth := api.Group("/auth")
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
+			auth.POST("/register", rateLimit("register"), authHandler.Register)
+			auth.POST("/login", rateLimit("login"), authHandler.Login)
+			auth.POST("/logout", authHandler.Logout)
 			auth.GET("/me", middleware.AuthMiddleware(), authHandler.GetMe)
+			auth.GET("/csrf", middleware.AuthMiddleware(), authHandler.GetCSRFToken)
 		}
 
 		// 文章路由
 		articles := api.Group("/articles")
 		{
-			articles.GET("", articleHandler.GetArticleList)
+			articles.GET("", middleware.OptionalAuthMiddleware(), articleHandler.GetArticleList)
 			// 评论路由（必须在/:id之前，避免路由冲突）
-			articles.GET("/:id/comments", commentHandler.GetCommentList)
-			articles.POST("/:id/comments", middleware.AuthMiddleware(), commentHandler.CreateComment)
+			articles.GET("/:id/comments", middleware.OptionalAuthMiddleware(), commentHandler.GetCommentList)
+			articles.POST("/:id/comments", middleware.AuthMiddleware(), rateLimit("comment"), commentHandler.CreateComment)
 			articles.POST("/:id/like", middleware.AuthMiddleware(), likeHandler.ToggleArticleLike)
-			articles.GET("/:id", articleHandler.GetArticleDetail)
+			articles.GET("/:id", middleware.OptionalAuthMiddleware(), articleHandler.GetArticleDetail)
 			articles.POST("", middleware.AuthMiddleware(), articleHandler.CreateArticle)
 			articles.PUT("/:id", middleware.AuthMiddleware(), articleHandler.UpdateArticle)
 			articles.DELETE("/:id", middleware.AuthMiddleware(), articleHandler.DeleteArticle)
 		}
 
+		// 文章系列路由
+		series := api.Group("/series")
+		{
+			series.GET("", seriesHandler.GetSeriesList)
+			series.GET("/:id", middleware.OptionalAuthMiddleware(), seriesHandler.GetSeriesDetail)
+			series.POST("", middleware.AuthMiddleware(), seriesHandler.CreateSeries)
+			series.PUT("/:id", middleware.AuthMiddleware(), seriesHandler.UpdateSeries)
+			series.DELETE("/:id", middleware.AuthMiddleware(), seriesHandler.DeleteSeries)
+			series.PUT("/:id/articles", middleware.AuthMiddleware(), seriesHandler.SetSeriesArticles)
+			series.POST("/:id/articles", middleware.AuthMiddleware(), seriesHandler.AddSeriesArticle)
+			series.DELETE("/:id/articles/:article_id", middleware.AuthMiddleware(), seriesHandler.RemoveSeriesArticle)
+		}
+
+		// 收藏路由
+		bookmarks := api.Group("/bookmarks", middleware.AuthMiddleware())
+		{
+			bookmarks.GET("", bookmarkHandler.GetBookmarks)
+			bookmarks.POST("", bookmarkHandler.CreateBookmark)
+			bookmarks.PUT("/:article_id", bookmarkHandler.MoveBookmark)
+			bookmarks.DELETE("/:article_id", bookmarkHandler.DeleteBookmark)
+		}
+
+		// 阅读列表路由，公开的列表未登录也可以查看
+		readingLists := api.Group("/reading-lists")
+		{
+			readingLists.GET("", middleware.OptionalAuthMiddleware(), bookmarkHandler.GetReadingLists)
+			readingLists.GET("/:id", middleware.OptionalAuthMiddleware(), bookmarkHandler.GetReadingListDetail)
+			readingLists.GET("/:id/bookmarks", middleware.OptionalAuthMiddleware(), bookmarkHandler.GetReadingListBookmarks)
+			readingLists.POST("", middleware.AuthMiddleware(), bookmarkHandler.CreateReadingList)
+			readingLists.PUT("/:id", middleware.AuthMiddleware(), bookmarkHandler.UpdateReadingList)
+			readingLists.DELETE("/:id", middleware.AuthMiddleware(), bookmarkHandler.DeleteReadingList)
+			readingLists.PUT("/:id/order", middleware.AuthMiddleware(), bookmarkHandler.ReorderReadingList)
+		}
+
 		// 分类路由
 		categories := api.Group("/categories")
 		{
@@ -179,7 +348,48 @@ func main() {
 		// 文件上传路由
 		files := api.Group("/files")
 		{
-			files.POST("/upload", middleware.AuthMiddleware(), fileHandler.UploadFile)
+			files.POST("/upload", middleware.AuthMiddleware(), rateLimit("upload"), fileHandler.UploadFile)
+			files.GET("/quota", middleware.AuthMiddleware(), fileHandler.GetQuota)
+
+			// 分片上传（断点续传）
+			files.POST("/uploads", middleware.AuthMiddleware(), rateLimit("upload"), fileHandler.InitUpload)
+			files.GET("/uploads/:id", middleware.AuthMiddleware(), fileHandler.GetUpload)
+			files.PUT("/uploads/:id", middleware.AuthMiddleware(), fileHandler.UploadChunk)
+			files.POST("/uploads/:id/complete", middleware.AuthMiddleware(), fileHandler.CompleteUpload)
+			files.DELETE("/uploads/:id", middleware.AuthMiddleware(), fileHandler.AbortUpload)
+		}
+
+		// 回收站，管理员可查看和恢复所有人的记录
+		trash := api.Group("/trash", middleware.AuthMiddleware())
+		{
+			trash.GET("/articles", trashHandler.GetDeletedArticles)
+			trash.POST("/articles/:id/restore", trashHandler.RestoreArticle)
+			trash.GET("/comments", trashHandler.GetDeletedComments)
+			trash.POST("/comments/:id/restore", trashHandler.RestoreComment)
+		}
+
+		// 公开的站点设置
+		api.GET("/settings", settingHandler.GetPublicSettings)
+
+		// 管理员路由
+		admin := api.Group("/admin", middleware.AuthMiddleware(), middleware.RequireRole(model.RoleAdmin))
+		{
+			admin.GET("/settings", settingHandler.GetSettings)
+			admin.PUT("/settings", settingHandler.UpdateSettings)
+			admin.GET("/settings/history", settingHandler.GetSettingHistory)
+
+			admin.GET("/comments/pending", commentHandler.GetPendingComments)
+			admin.POST("/comments/:id/approve", commentHandler.ApproveComment)
+			admin.POST("/comments/:id/reject", commentHandler.RejectComment)
+
+			admin.GET("/audit-logs", auditHandler.GetAuditLogs)
+			admin.GET("/audit-logs/export", auditHandler.ExportAuditLogs)
+
+			// 删除的用户进入回收站，可以恢复
+			admin.DELETE("/users/:id", trashHandler.DeleteUser)
+			admin.GET("/trash/users", trashHandler.GetDeletedUsers)
+			admin.POST("/trash/users/:id/restore", trashHandler.RestoreUser)
+			admin.POST("/trash/purge", trashHandler.Purge)
 		}
 	}
 
@@ -188,13 +398,57 @@ func main() {
 	if uploadPath == "" {
 		uploadPath = "./uploads"
 	}
-	router.Static("/uploads", uploadPath)
+	uploads := router.Group("/uploads", middleware.UploadsSecurityMiddleware(cfg.Security.Headers))
+	uploads.Static("/", uploadPath)
 
 	// 启动服务
-	addr := ":" + cfg.Server.Port
-	logger.Info("服务器启动", zap.String("address", addr))
-	if err := router.Run(addr); err != nil {
-		logger.Fatal("启动服务器失败", zap.String("error", err.Error()))
+	srv := &http.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      router,
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
+		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout) * time.Second,
 	}
-}
+	go func() {
+		logger.Info("服务器启动", zap.String("address", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("启动服务器失败", zap.String("error", err.Error()))
+		}
+	}()
 
+	// 等待退出信号
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	logger.Info("收到退出信号，开始关闭服务", zap.String("signal", sig.String()))
+
+	// 停止接收新请求，等待进行中的请求完成
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("等待请求完成超时，强制关闭", zap.String("error", err.Error()))
+	}
+
+	// 后台任务必须在数据库连接关闭前停止，浏览计数器会把剩余计数写入数据库
+	if err := viewCounter.Stop(); err != nil {
+		logger.Error("写入浏览数失败", zap.String("error", err.Error()))
+	}
+	stopUploadCleanup()
+	stopTrashPurge()
+	stopConfigWatch()
+
+	tracingCtx, cancelTracing := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelTracing()
+	if err := shutdownTracing(tracingCtx); err != nil {
+		logger.Error("导出剩余追踪数据失败", zap.String("error", err.Error()))
+	}
+
+	if err := database.CloseRedis(); err != nil {
+		logger.Error("关闭Redis连接失败", zap.String("error", err.Error()))
+	}
+	if err := database.Close(); err != nil {
+		logger.Error("关闭数据库连接失败", zap.String("error", err.Error()))
+	}
+	logger.Info("服务已关闭")
+	logger.Sync()
+}
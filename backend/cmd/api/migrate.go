@@ -0,0 +1,79 @@
+package main
+
+import (
+	"dbapp/migrations"
+	"dbapp/pkg/logger"
+	"dbapp/pkg/migrate"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "用法: api migrate up | down [n] | status"
+
+// newMigrator 创建使用内嵌迁移文件的迁移执行器
+func newMigrator(db *gorm.DB) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库实例失败: %w", err)
+	}
+	return migrate.New(sqlDB, db.Dialector.Name(), migrations.FS)
+}
+
+// runMigrate 执行 migrate 子命令
+func runMigrate(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(migrateUsage)
+	}
+
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		version, _ := migrator.Version()
+		logger.Info("数据库迁移成功", zap.Int("applied", n), zap.Int64("version", version))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("回滚步数无效: %s", args[1])
+			}
+		}
+		n, err := migrator.Down(steps)
+		if err != nil {
+			return err
+		}
+		version, _ := migrator.Version()
+		logger.Info("数据库回滚成功", zap.Int("reverted", n), zap.Int64("version", version))
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		return fmt.Errorf(migrateUsage)
+	}
+	return nil
+}
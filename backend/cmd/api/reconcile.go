@@ -0,0 +1,36 @@
+package main
+
+import (
+	"dbapp/internal/repository"
+	"dbapp/pkg/logger"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// runReconcile 执行 reconcile 子命令：删除重复的点赞，再根据源数据表重新计算文章、评论、分类和标签的冗余计数字段。
+// 用于修复历史数据或异常中断导致的计数偏差，可重复执行
+func runReconcile(db *gorm.DB) error {
+	removed, err := repository.NewLikeRepository(db).DeleteDuplicates()
+	if err != nil {
+		return fmt.Errorf("删除重复点赞失败: %w", err)
+	}
+	logger.Info("删除重复点赞", zap.Int64("rows", removed))
+
+	fixed, err := repository.NewCounterRepository(db).Reconcile()
+	if err != nil {
+		return fmt.Errorf("重新计算计数失败: %w", err)
+	}
+
+	names := make([]string, 0, len(fixed))
+	for name := range fixed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		logger.Info("计数已修正", zap.String("field", name), zap.Int64("rows", fixed[name]))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"dbapp/internal/config"
+	"dbapp/pkg/logger"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// watchConfig 收到 SIGHUP 或配置文件变化时重新加载配置，返回停止监听的函数。
+// 编辑器保存文件时可能连续触发多次事件，合并 reloadDebounce 内的事件后只加载一次。
+func watchConfig(reloader *config.Reloader) func() {
+	const reloadDebounce = 500 * time.Millisecond
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	fileChanged := make(chan struct{}, 1)
+	if file := viper.ConfigFileUsed(); file != "" {
+		viper.OnConfigChange(func(fsnotify.Event) {
+			select {
+			case fileChanged <- struct{}{}:
+			default:
+			}
+		})
+		viper.WatchConfig()
+		logger.Info("监听配置文件变化", zap.String("file", file))
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-hup:
+				reloadConfig(reloader, "SIGHUP")
+			case <-fileChanged:
+				debounce = time.After(reloadDebounce)
+			case <-debounce:
+				debounce = nil
+				reloadConfig(reloader, "文件变化")
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(hup)
+		close(stop)
+		<-done
+	}
+}
+
+func reloadConfig(reloader *config.Reloader, trigger string) {
+	result, err := reloader.Reload()
+	if err != nil {
+		logger.Error("重新加载配置失败，继续使用当前配置",
+			zap.String("trigger", trigger), zap.String("error", err.Error()))
+		return
+	}
+	for _, change := range result.Changes {
+		logger.Info("配置已更新",
+			zap.String("key", change.Key), zap.String("old", change.Old), zap.String("new", change.New))
+	}
+	if len(result.RestartRequired) > 0 {
+		logger.Warn("以下配置需要重启后生效", zap.Strings("keys", result.RestartRequired))
+	}
+	if len(result.Changes) == 0 && len(result.RestartRequired) == 0 {
+		logger.Info("重新加载配置，没有变化", zap.String("trigger", trigger))
+	}
+}
@@ -2,18 +2,28 @@ package config
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	File     FileConfig     `mapstructure:"file"`
-	App      AppConfig      `mapstructure:"app"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Cache     CacheConfig     `mapstructure:"cache"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	File      FileConfig      `mapstructure:"file"`
+	App       AppConfig       `mapstructure:"app"`
+	View      ViewConfig      `mapstructure:"view"`
+	Trash     TrashConfig     `mapstructure:"trash"`
+	Comment   CommentConfig   `mapstructure:"comment"`
+	Tracing   TracingConfig   `mapstructure:"tracing"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	CORS      CORSConfig      `mapstructure:"cors"`
+	Security  SecurityConfig  `mapstructure:"security"`
+	Log       LogConfig       `mapstructure:"log"`
+
+	BootstrapAdmin BootstrapAdminConfig `mapstructure:"bootstrap_admin"`
 }
 
 type ServerConfig struct {
@@ -21,6 +31,13 @@ type ServerConfig struct {
 	Mode         string `mapstructure:"mode"`
 	ReadTimeout  int    `mapstructure:"read_timeout"`
 	WriteTimeout int    `mapstructure:"write_timeout"`
+	// IdleTimeout keep-alive 连接的空闲超时（秒）
+	IdleTimeout int `mapstructure:"idle_timeout"`
+	// ShutdownTimeout 收到退出信号后等待进行中请求完成的最长时间（秒）
+	ShutdownTimeout int `mapstructure:"shutdown_timeout"`
+	// TrustedProxies 可信的反向代理IP或网段，只有来自这些地址的请求才读取 X-Forwarded-For 作为客户端IP；
+	// 为空时不信任任何代理，客户端IP取连接的对端地址
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
@@ -40,6 +57,11 @@ type RedisConfig struct {
 	DB       int    `mapstructure:"db"`
 }
 
+type CacheConfig struct {
+	Driver string `mapstructure:"driver"` // redis 或 memory，Redis不可用时退化为 memory
+	TTL    int    `mapstructure:"ttl"`    // 缓存过期秒数
+}
+
 type JWTConfig struct {
 	Secret    string `mapstructure:"secret"`
 	ExpiresIn int    `mapstructure:"expires_in"`
@@ -47,9 +69,22 @@ type JWTConfig struct {
 }
 
 type FileConfig struct {
-	UploadPath  string   `mapstructure:"upload_path"`
-	MaxSize     int64    `mapstructure:"max_size"`
-	AllowedExt  []string `mapstructure:"allowed_ext"`
+	UploadPath     string   `mapstructure:"upload_path"`
+	MaxSize        int64    `mapstructure:"max_size"`
+	AllowedExt     []string `mapstructure:"allowed_ext"`
+	TempPath       string   `mapstructure:"temp_path"`        // 分片上传临时目录
+	ChunkSize      int64    `mapstructure:"chunk_size"`       // 单个分片最大字节数
+	MaxChunkedSize int64    `mapstructure:"max_chunked_size"` // 分片上传文件最大字节数
+	SessionTTL     int      `mapstructure:"session_ttl"`      // 未完成的上传会话保留秒数
+
+	// 按角色配置的上传配额，未配置的角色使用 user 的配额
+	Quotas map[string]QuotaConfig `mapstructure:"quotas"`
+}
+
+// QuotaConfig 上传配额，0 表示不限制
+type QuotaConfig struct {
+	Storage      int64 `mapstructure:"storage"`       // 存储空间上限（字节）
+	DailyUploads int   `mapstructure:"daily_uploads"` // 每日上传次数上限
 }
 
 type AppConfig struct {
@@ -58,43 +93,174 @@ type AppConfig struct {
 	Debug bool   `mapstructure:"debug"`
 }
 
+// ViewConfig 文章浏览计数配置
+type ViewConfig struct {
+	FlushInterval int `mapstructure:"flush_interval"` // 浏览数写入数据库的间隔秒数
+	DedupeWindow  int `mapstructure:"dedupe_window"`  // 同一用户/IP重复浏览不计数的秒数
+}
+
+// CommentConfig 评论审核配置，可在运行时重新加载
+type CommentConfig struct {
+	// ModerationLinks 非管理员的评论包含的链接数达到该值时需审核后展示，0 表示不按链接数审核。
+	// 与站点设置 comment_moderation 独立，开启先审后发时所有评论都需审核
+	ModerationLinks int `mapstructure:"moderation_links"`
+}
+
+// TrashConfig 回收站配置
+type TrashConfig struct {
+	RetentionDays int `mapstructure:"retention_days"` // 删除的文章、评论保留的天数，超过后彻底删除
+	PurgeInterval int `mapstructure:"purge_interval"` // 检查并彻底删除过期记录的间隔秒数
+}
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"` // stdout 或 otlp
+	Endpoint    string  `mapstructure:"endpoint"` // OTLP/HTTP 地址，如 localhost:4318
+	Insecure    bool    `mapstructure:"insecure"` // OTLP 使用 HTTP 而非 HTTPS
+	ServiceName string  `mapstructure:"service_name"`
+	SampleRatio float64 `mapstructure:"sample_ratio"` // 采样比例，上游已采样的请求始终采样
+}
+
+// RateLimitConfig 接口限流和登录锁定配置
+type RateLimitConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Driver  string `mapstructure:"driver"` // redis 或 memory，Redis不可用时退化为 memory
+	// Rules 按路由组配置的令牌桶规则，default 作用于所有接口，按用户（已登录）或IP计数
+	Rules   map[string]RateLimitRule `mapstructure:"rules"`
+	Lockout LockoutConfig            `mapstructure:"lockout"`
+}
+
+// RateLimitRule 每 Period 秒允许 Requests 次请求，Burst 为突发容量，0 表示等于 Requests
+type RateLimitRule struct {
+	Requests int `mapstructure:"requests"`
+	Period   int `mapstructure:"period"`
+	Burst    int `mapstructure:"burst"`
+}
+
+// LockoutConfig 登录失败锁定配置，锁定时长从 BaseDuration 开始每多失败一次翻倍，最长 MaxDuration
+type LockoutConfig struct {
+	MaxAttempts  int `mapstructure:"max_attempts"`  // 连续失败多少次后锁定
+	BaseDuration int `mapstructure:"base_duration"` // 首次锁定秒数
+	MaxDuration  int `mapstructure:"max_duration"`  // 最长锁定秒数
+	Window       int `mapstructure:"window"`        // 失败次数在最后一次失败后保留的秒数
+}
+
+// CORSConfig 跨域配置。AllowedOrigins 支持精确的 Origin、https://*.example.com 形式的子域名通配和 *，
+// 启用 AllowCredentials 时不能使用 *
+type CORSConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`
+	AllowedMethods   []string `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string `mapstructure:"exposed_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	MaxAge           int      `mapstructure:"max_age"` // 预检结果缓存秒数
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level string `mapstructure:"level"` // debug、info、warn 或 error，为空时 release 模式为 info，其他为 debug
+}
+
+// BootstrapAdminConfig 首次启动时创建的管理员，数据库中已有管理员时不生效。
+// Password 为空时生成随机密码并只在启动日志中输出一次
+type BootstrapAdminConfig struct {
+	Username string `mapstructure:"username"`
+	Email    string `mapstructure:"email"`
+	Password string `mapstructure:"password"`
+}
+
+// SecurityConfig 安全响应头和 Cookie 会话配置
+type SecurityConfig struct {
+	Headers SecurityHeadersConfig `mapstructure:"headers"`
+	Session SessionConfig         `mapstructure:"session"`
+}
+
+// SecurityHeadersConfig 安全响应头，值为空时不输出对应的响应头
+type SecurityHeadersConfig struct {
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"`
+	// UploadsContentSecurityPolicy 用户上传文件使用的 CSP，阻止上传的 SVG/HTML 执行脚本
+	UploadsContentSecurityPolicy string `mapstructure:"uploads_content_security_policy"`
+	FrameOptions                 string `mapstructure:"frame_options"`
+	ReferrerPolicy               string `mapstructure:"referrer_policy"`
+	HSTSMaxAge                   int    `mapstructure:"hsts_max_age"` // 秒，0 表示不输出，只应在全站 HTTPS 时启用
+}
+
+// SessionConfig Cookie 会话配置。启用后登录时可选择把 Token 写入 HttpOnly Cookie，
+// 使用 Cookie 认证的写操作需要通过 X-CSRF-Token 请求头提交与 CSRF Cookie 相同的值
+type SessionConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	CookieName     string `mapstructure:"cookie_name"`
+	CSRFCookieName string `mapstructure:"csrf_cookie_name"`
+	Domain         string `mapstructure:"domain"`
+	Secure         bool   `mapstructure:"secure"`
+	SameSite       string `mapstructure:"same_site"` // lax、strict 或 none，none 要求 secure
+}
+
 var GlobalConfig *Config
 
+// LoadConfig 加载并校验配置，保存到 GlobalConfig
 func LoadConfig() (*Config, error) {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("./config")
-	viper.AddConfigPath("../config")
+	config, err := load(viper.GetViper())
+	if err != nil {
+		return nil, err
+	}
+	GlobalConfig = config
+	return config, nil
+}
+
+// load 从配置文件和环境变量读取配置，填充默认值并校验
+func load(v *viper.Viper) (*Config, error) {
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	v.AddConfigPath("./config")
+	v.AddConfigPath("../config")
 
 	// 环境变量支持
-	viper.SetEnvPrefix("APP")
-	viper.AutomaticEnv()
+	v.SetEnvPrefix("APP")
+	v.AutomaticEnv()
 
 	// 从环境变量读取配置
-	viper.BindEnv("server.port", "SERVER_PORT")
-	viper.BindEnv("server.mode", "SERVER_MODE")
-	viper.BindEnv("database.host", "DB_HOST")
-	viper.BindEnv("database.port", "DB_PORT")
-	viper.BindEnv("database.user", "DB_USER")
-	viper.BindEnv("database.password", "DB_PASSWORD")
-	viper.BindEnv("database.db_name", "DB_NAME")
-	viper.BindEnv("redis.host", "REDIS_HOST")
-	viper.BindEnv("redis.port", "REDIS_PORT")
-	viper.BindEnv("redis.password", "REDIS_PASSWORD")
-	viper.BindEnv("jwt.secret", "JWT_SECRET")
-	viper.BindEnv("jwt.expires_in", "JWT_EXPIRES_IN")
-	viper.BindEnv("file.upload_path", "FILE_UPLOAD_PATH")
-	viper.BindEnv("file.max_size", "FILE_MAX_SIZE")
-
-	if err := viper.ReadInConfig(); err != nil {
+	v.BindEnv("server.port", "SERVER_PORT")
+	v.BindEnv("server.mode", "SERVER_MODE")
+	v.BindEnv("server.trusted_proxies", "TRUSTED_PROXIES")
+	v.BindEnv("database.host", "DB_HOST")
+	v.BindEnv("database.port", "DB_PORT")
+	v.BindEnv("database.user", "DB_USER")
+	v.BindEnv("database.password", "DB_PASSWORD")
+	v.BindEnv("database.db_name", "DB_NAME")
+	v.BindEnv("redis.host", "REDIS_HOST")
+	v.BindEnv("redis.port", "REDIS_PORT")
+	v.BindEnv("redis.password", "REDIS_PASSWORD")
+	v.BindEnv("cache.driver", "CACHE_DRIVER")
+	v.BindEnv("jwt.secret", "JWT_SECRET")
+	v.BindEnv("jwt.expires_in", "JWT_EXPIRES_IN")
+	v.BindEnv("file.upload_path", "FILE_UPLOAD_PATH")
+	v.BindEnv("file.max_size", "FILE_MAX_SIZE")
+	v.BindEnv("file.temp_path", "FILE_TEMP_PATH")
+	v.BindEnv("tracing.enabled", "TRACING_ENABLED")
+	v.BindEnv("tracing.exporter", "TRACING_EXPORTER")
+	v.BindEnv("tracing.endpoint", "TRACING_ENDPOINT")
+	v.BindEnv("rate_limit.enabled", "RATE_LIMIT_ENABLED")
+	v.BindEnv("rate_limit.driver", "RATE_LIMIT_DRIVER")
+	v.BindEnv("cors.allowed_origins", "CORS_ALLOWED_ORIGINS")
+	v.BindEnv("security.session.enabled", "SESSION_COOKIE_ENABLED")
+	v.BindEnv("security.session.secure", "SESSION_COOKIE_SECURE")
+	v.BindEnv("security.headers.hsts_max_age", "SECURITY_HSTS_MAX_AGE")
+	v.BindEnv("log.level", "LOG_LEVEL")
+	v.BindEnv("bootstrap_admin.username", "ADMIN_USERNAME")
+	v.BindEnv("bootstrap_admin.email", "ADMIN_EMAIL")
+	v.BindEnv("bootstrap_admin.password", "ADMIN_PASSWORD")
+
+	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("读取配置文件失败: %w", err)
 		}
 	}
 
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("解析配置失败: %w", err)
 	}
 
@@ -105,8 +271,122 @@ func LoadConfig() (*Config, error) {
 	if config.Server.Mode == "" {
 		config.Server.Mode = "debug"
 	}
+	if config.Server.ReadTimeout == 0 {
+		config.Server.ReadTimeout = 30
+	}
+	if config.Server.WriteTimeout == 0 {
+		config.Server.WriteTimeout = 30
+	}
+	if config.Server.IdleTimeout == 0 {
+		config.Server.IdleTimeout = 60
+	}
+	if config.Server.ShutdownTimeout == 0 {
+		config.Server.ShutdownTimeout = 15
+	}
+	if config.Cache.Driver == "" {
+		config.Cache.Driver = "memory"
+	}
+	if config.Cache.TTL == 0 {
+		config.Cache.TTL = 300
+	}
+	if config.Tracing.Exporter == "" {
+		config.Tracing.Exporter = "otlp"
+	}
+	if config.Tracing.Endpoint == "" {
+		config.Tracing.Endpoint = "localhost:4318"
+	}
+	if config.Tracing.ServiceName == "" {
+		config.Tracing.ServiceName = "dbapp-api"
+	}
+	if config.Tracing.SampleRatio <= 0 {
+		config.Tracing.SampleRatio = 1
+	}
+	if !v.IsSet("rate_limit.enabled") {
+		config.RateLimit.Enabled = true
+	}
+	if config.RateLimit.Driver == "" {
+		config.RateLimit.Driver = config.Cache.Driver
+	}
+	if config.RateLimit.Rules == nil {
+		config.RateLimit.Rules = map[string]RateLimitRule{}
+	}
+	for name, rule := range defaultRateLimitRules {
+		if _, ok := config.RateLimit.Rules[name]; !ok {
+			config.RateLimit.Rules[name] = rule
+		}
+	}
+	if config.RateLimit.Lockout.MaxAttempts == 0 {
+		config.RateLimit.Lockout.MaxAttempts = 5
+	}
+	if config.RateLimit.Lockout.BaseDuration == 0 {
+		config.RateLimit.Lockout.BaseDuration = 60
+	}
+	if config.RateLimit.Lockout.MaxDuration == 0 {
+		config.RateLimit.Lockout.MaxDuration = 3600
+	}
+	if config.RateLimit.Lockout.Window == 0 {
+		config.RateLimit.Lockout.Window = 15 * 60
+	}
+	if len(config.CORS.AllowedMethods) == 0 {
+		config.CORS.AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	}
+	if len(config.CORS.AllowedHeaders) == 0 {
+		config.CORS.AllowedHeaders = []string{"Authorization", "Content-Type", "Accept", "X-Requested-With", "X-Request-ID", "X-CSRF-Token"}
+	}
+	if len(config.CORS.ExposedHeaders) == 0 {
+		config.CORS.ExposedHeaders = []string{"X-Request-ID", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining"}
+	}
+	if !v.IsSet("cors.max_age") {
+		config.CORS.MaxAge = 600
+	}
+	production := config.App.Env == "production"
+	headers := &config.Security.Headers
+	if !v.IsSet("security.headers.content_security_policy") {
+		headers.ContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+	}
+	if !v.IsSet("security.headers.uploads_content_security_policy") {
+		headers.UploadsContentSecurityPolicy = "default-src 'none'; img-src 'self'; media-src 'self'; style-src 'unsafe-inline'; sandbox"
+	}
+	if !v.IsSet("security.headers.frame_options") {
+		headers.FrameOptions = "DENY"
+	}
+	if !v.IsSet("security.headers.referrer_policy") {
+		headers.ReferrerPolicy = "strict-origin-when-cross-origin"
+	}
+	if !v.IsSet("security.headers.hsts_max_age") && production {
+		headers.HSTSMaxAge = 365 * 24 * 3600
+	}
+	session := &config.Security.Session
+	if session.CookieName == "" {
+		session.CookieName = "dbapp_session"
+	}
+	if session.CSRFCookieName == "" {
+		session.CSRFCookieName = "dbapp_csrf"
+	}
+	if !v.IsSet("security.session.secure") {
+		session.Secure = production
+	}
+	if session.SameSite == "" {
+		session.SameSite = "lax"
+	}
+	if config.View.FlushInterval == 0 {
+		config.View.FlushInterval = 10
+	}
+	if config.View.DedupeWindow == 0 {
+		config.View.DedupeWindow = 30 * 60
+	}
+	if config.Trash.RetentionDays == 0 {
+		config.Trash.RetentionDays = 30
+	}
+	if config.Trash.PurgeInterval == 0 {
+		config.Trash.PurgeInterval = 3600
+	}
+	// 开发环境未配置密钥时使用默认密钥，生产环境由 Validate 拒绝启动
 	if config.JWT.Secret == "" {
-		config.JWT.Secret = "default-secret-key-change-in-production"
+		config.JWT.Secret = DefaultJWTSecret
+	}
+	if config.JWT.ExpiresIn == 0 {
+		config.JWT.ExpiresIn = 3600
 	}
 	// 文件上传默认值
 	if config.File.UploadPath == "" {
@@ -118,48 +398,55 @@ func LoadConfig() (*Config, error) {
 	if len(config.File.AllowedExt) == 0 {
 		config.File.AllowedExt = []string{"jpg", "jpeg", "png", "gif", "webp", "svg", "pdf"}
 	}
+	if config.File.TempPath == "" {
+		config.File.TempPath = "./tmp/uploads"
+	}
+	if config.File.ChunkSize == 0 {
+		config.File.ChunkSize = 5 * 1024 * 1024 // 默认5MB
+	}
+	if config.File.MaxChunkedSize == 0 {
+		config.File.MaxChunkedSize = 500 * 1024 * 1024 // 默认500MB
+	}
+	if config.File.SessionTTL == 0 {
+		config.File.SessionTTL = 24 * 3600 // 默认保留24小时
+	}
+	if len(config.File.Quotas) == 0 {
+		config.File.Quotas = map[string]QuotaConfig{
+			"user":  {Storage: 500 * 1024 * 1024, DailyUploads: 50},
+			"admin": {},
+		}
+	}
+
+	if config.BootstrapAdmin.Username == "" {
+		config.BootstrapAdmin.Username = "admin"
+	}
+	if config.BootstrapAdmin.Email == "" {
+		config.BootstrapAdmin.Email = "admin@dbapp.local"
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
-	GlobalConfig = &config
 	return &config, nil
 }
 
+// defaultRateLimitRules 未在配置文件中出现的规则使用的默认值
+var defaultRateLimitRules = map[string]RateLimitRule{
+	"default":  {Requests: 100, Period: 60},
+	"login":    {Requests: 5, Period: 60},
+	"register": {Requests: 3, Period: 3600},
+	"comment":  {Requests: 10, Period: 60},
+	"upload":   {Requests: 10, Period: 60},
+}
+
+// GetConfig 返回已加载的配置，尚未加载时调用 LoadConfig。
+// 配置无效时直接 panic，不使用内置默认值启动，避免以公开的默认 JWT 密钥签发令牌
 func GetConfig() *Config {
 	if GlobalConfig == nil {
-		// 尝试从环境变量加载
-		config, err := LoadConfig()
-		if err != nil {
-			// 使用默认配置
-			config = &Config{
-				Server: ServerConfig{
-					Port: getEnv("SERVER_PORT", "8080"),
-					Mode: getEnv("SERVER_MODE", "debug"),
-				},
-				Database: DatabaseConfig{
-					Host:     getEnv("DB_HOST", "localhost"),
-					Port:     5432,
-					User:     getEnv("DB_USER", "dbapp"),
-					Password: getEnv("DB_PASSWORD", "dbapp123"),
-					DBName:   getEnv("DB_NAME", "dbapp"),
-				},
-				Redis: RedisConfig{
-					Host: getEnv("REDIS_HOST", "localhost"),
-					Port: 6379,
-				},
-				JWT: JWTConfig{
-					Secret:    getEnv("JWT_SECRET", "default-secret-key"),
-					ExpiresIn: 3600,
-				},
-			}
+		if _, err := LoadConfig(); err != nil {
+			panic(fmt.Sprintf("加载配置失败: %v", err))
 		}
-		GlobalConfig = config
 	}
 	return GlobalConfig
 }
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+)
+
+// reloadableField 可在运行时重新加载的配置项
+type reloadableField struct {
+	key string
+	get func(c *Config) interface{}
+	set func(dst, src *Config)
+}
+
+var reloadableFields = []reloadableField{
+	{"rate_limit.rules",
+		func(c *Config) interface{} { return c.RateLimit.Rules },
+		func(dst, src *Config) { dst.RateLimit.Rules = src.RateLimit.Rules }},
+	{"rate_limit.lockout",
+		func(c *Config) interface{} { return c.RateLimit.Lockout },
+		func(dst, src *Config) { dst.RateLimit.Lockout = src.RateLimit.Lockout }},
+	{"file.allowed_ext",
+		func(c *Config) interface{} { return c.File.AllowedExt },
+		func(dst, src *Config) { dst.File.AllowedExt = src.File.AllowedExt }},
+	{"file.max_size",
+		func(c *Config) interface{} { return c.File.MaxSize },
+		func(dst, src *Config) { dst.File.MaxSize = src.File.MaxSize }},
+	{"file.max_chunked_size",
+		func(c *Config) interface{} { return c.File.MaxChunkedSize },
+		func(dst, src *Config) { dst.File.MaxChunkedSize = src.File.MaxChunkedSize }},
+	{"file.quotas",
+		func(c *Config) interface{} { return c.File.Quotas },
+		func(dst, src *Config) { dst.File.Quotas = src.File.Quotas }},
+	{"comment.moderation_links",
+		func(c *Config) interface{} { return c.Comment.ModerationLinks },
+		func(dst, src *Config) { dst.Comment.ModerationLinks = src.Comment.ModerationLinks }},
+	{"log.level",
+		func(c *Config) interface{} { return c.Log.Level },
+		func(dst, src *Config) { dst.Log.Level = src.Log.Level }},
+}
+
+// Change 一项配置的变更
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+// ReloadResult 一次重新加载的结果
+type ReloadResult struct {
+	// Changes 已生效的变更
+	Changes []Change
+	// RestartRequired 有变更但需要重启才能生效的配置段，不包含具体取值，避免密钥写入日志
+	RestartRequired []string
+}
+
+// Reloader 持有当前生效的配置，支持在运行时重新加载部分配置。
+// 读取配置的一方通过 Current 获取快照，或通过 OnChange 在配置变化时更新自身状态。
+// GlobalConfig 不随重新加载更新，其中只应读取不可重新加载的配置。
+type Reloader struct {
+	mu        sync.Mutex
+	current   atomic.Pointer[Config]
+	listeners []func(old, new *Config)
+	// load 读取最新配置，测试时替换
+	load func() (*Config, error)
+}
+
+func NewReloader(cfg *Config) *Reloader {
+	r := &Reloader{load: func() (*Config, error) { return load(viper.New()) }}
+	r.current.Store(cfg)
+	return r
+}
+
+// Current 返回当前生效的配置，返回值不能修改
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// OnChange 注册配置变更回调，在新配置生效后按注册顺序同步调用
+func (r *Reloader) OnChange(fn func(old, new *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Reload 重新读取配置文件和环境变量。新配置校验失败时返回错误，当前配置保持不变；
+// 校验通过后只应用可重新加载的配置项，其余变更需要重启生效。
+func (r *Reloader) Reload() (*ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loaded, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	old := r.current.Load()
+	next := *old
+	result := &ReloadResult{}
+	for _, field := range reloadableFields {
+		oldValue, newValue := field.get(old), field.get(loaded)
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		field.set(&next, loaded)
+		result.Changes = append(result.Changes, Change{
+			Key: field.key,
+			Old: formatValue(oldValue),
+			New: formatValue(newValue),
+		})
+	}
+	result.RestartRequired = restartRequired(old, loaded)
+
+	if len(result.Changes) == 0 {
+		return result, nil
+	}
+	r.current.Store(&next)
+	for _, fn := range r.listeners {
+		fn(old, &next)
+	}
+	return result, nil
+}
+
+// restartRequired 比较不可重新加载的配置段，返回有变化的配置段名称
+func restartRequired(old, loaded *Config) []string {
+	a, b := *old, *loaded
+	for _, field := range reloadableFields {
+		field.set(&a, &b)
+	}
+	var keys []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	t := va.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			keys = append(keys, t.Field(i).Tag.Get("mapstructure"))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatValue(v interface{}) string {
+	if v == "" {
+		return `""`
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReloader_AppliesReloadableFields(t *testing.T) {
+	current := validConfig()
+	current.File.AllowedExt = []string{"jpg"}
+	r := NewReloader(current)
+
+	loaded := *current
+	loaded.File.AllowedExt = []string{"jpg", "png"}
+	loaded.Log.Level = "warn"
+	loaded.Comment.ModerationLinks = 2
+	loaded.Server.Port = "9090" // 需要重启生效
+	r.load = func() (*Config, error) { return &loaded, nil }
+
+	var notified *Config
+	r.OnChange(func(old, new *Config) {
+		if old != current {
+			t.Error("回调应收到变更前的配置")
+		}
+		notified = new
+	})
+
+	result, err := r.Reload()
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	if len(result.Changes) != 3 {
+		t.Fatalf("期望 3 项变更, 得到 %v", result.Changes)
+	}
+	if result.Changes[0].Key != "file.allowed_ext" || result.Changes[0].Old != "[jpg]" || result.Changes[0].New != "[jpg png]" {
+		t.Errorf("变更记录不正确: %+v", result.Changes[0])
+	}
+	if len(result.RestartRequired) != 1 || result.RestartRequired[0] != "server" {
+		t.Errorf("端口变更应提示需要重启: %v", result.RestartRequired)
+	}
+
+	cfg := r.Current()
+	if notified != cfg {
+		t.Error("回调应收到新配置")
+	}
+	if len(cfg.File.AllowedExt) != 2 || cfg.Log.Level != "warn" || cfg.Comment.ModerationLinks != 2 {
+		t.Error("可重新加载的配置应生效")
+	}
+	if cfg.Server.Port != "8080" {
+		t.Error("不可重新加载的配置不应生效")
+	}
+	if len(current.File.AllowedExt) != 1 {
+		t.Error("不应修改旧配置")
+	}
+}
+
+func TestReloader_KeepsCurrentOnError(t *testing.T) {
+	current := validConfig()
+	r := NewReloader(current)
+	r.load = func() (*Config, error) { return nil, errors.New("配置校验失败") }
+
+	called := false
+	r.OnChange(func(old, new *Config) { called = true })
+
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("应返回错误")
+	}
+	if r.Current() != current || called {
+		t.Error("校验失败时不应替换配置")
+	}
+}
+
+func TestReloader_NoChanges(t *testing.T) {
+	current := validConfig()
+	r := NewReloader(current)
+	loaded := *current
+	r.load = func() (*Config, error) { return &loaded, nil }
+
+	result, err := r.Reload()
+	if err != nil || len(result.Changes) != 0 || len(result.RestartRequired) != 0 {
+		t.Errorf("没有变化时不应有变更: %+v %v", result, err)
+	}
+	if r.Current() != current {
+		t.Error("没有变化时不应替换配置")
+	}
+}
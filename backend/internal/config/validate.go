@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultJWTSecret 未配置 JWT 密钥时使用的开发环境密钥，release 模式下禁止使用
+const DefaultJWTSecret = "default-secret-key-change-in-production"
+
+// insecureSecrets 示例配置和默认配置中出现过的密钥，release 模式下禁止使用
+var insecureSecrets = map[string]bool{
+	DefaultJWTSecret:                       true,
+	"default-secret-key":                   true,
+	"your-secret-key-change-in-production": true,
+	"dbapp123":                             true,
+	"qweasdzxc":                            true,
+}
+
+// minSecretLength release 模式下 JWT 密钥的最小长度
+const minSecretLength = 32
+
+// ValidationError 配置校验失败，包含全部问题以便一次改完
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "配置校验失败:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// IsRelease 是否为生产环境，server.mode 为 release 或 app.env 为 production 时启用生产环境检查
+func (c *Config) IsRelease() bool {
+	return c.Server.Mode == "release" || c.App.Env == "production"
+}
+
+// Validate 校验配置，返回包含全部问题的 *ValidationError
+func (c *Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		addf("%s 必须是 %s 之一，当前为 %q", key, strings.Join(allowed, "、"), value)
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port <= 0 || port > 65535 {
+		addf("server.port 必须是 1-65535 之间的端口号，当前为 %q", c.Server.Port)
+	}
+	oneOf("server.mode", c.Server.Mode, "debug", "release", "test")
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 || c.Server.ShutdownTimeout < 0 {
+		addf("server 的超时时间不能为负数")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				addf("server.trusted_proxies 中的 %q 不是有效的IP或网段", proxy)
+			}
+		}
+	}
+	oneOf("cache.driver", c.Cache.Driver, "redis", "memory")
+	if c.Log.Level != "" {
+		oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+	}
+
+	if c.JWT.ExpiresIn <= 0 {
+		addf("jwt.expires_in 必须大于0")
+	}
+	if c.File.MaxSize <= 0 {
+		addf("file.max_size 必须大于0")
+	}
+	if c.File.ChunkSize <= 0 || c.File.MaxChunkedSize < c.File.ChunkSize {
+		addf("file.chunk_size 必须大于0且不大于 file.max_chunked_size")
+	}
+
+	if c.Trash.RetentionDays < 0 || c.Trash.PurgeInterval < 0 {
+		addf("trash.retention_days 和 trash.purge_interval 不能为负数")
+	}
+	if c.Comment.ModerationLinks < 0 {
+		addf("comment.moderation_links 不能为负数")
+	}
+
+	if c.RateLimit.Enabled {
+		oneOf("rate_limit.driver", c.RateLimit.Driver, "redis", "memory")
+	}
+	for name, rule := range c.RateLimit.Rules {
+		if rule.Requests < 0 || rule.Period < 0 || rule.Burst < 0 {
+			addf("rate_limit.rules.%s 的取值不能为负数", name)
+		}
+	}
+	if c.RateLimit.Lockout.MaxDuration < c.RateLimit.Lockout.BaseDuration {
+		addf("rate_limit.lockout.max_duration 不能小于 base_duration")
+	}
+
+	if c.Tracing.Enabled {
+		oneOf("tracing.exporter", c.Tracing.Exporter, "stdout", "otlp")
+	}
+	if c.CORS.AllowCredentials {
+		for _, origin := range c.CORS.AllowedOrigins {
+			if origin == "*" {
+				addf("cors.allowed_origins 包含 * 时不能启用 allow_credentials")
+			}
+		}
+	}
+	oneOf("security.session.same_site", strings.ToLower(c.Security.Session.SameSite), "lax", "strict", "none")
+	if strings.ToLower(c.Security.Session.SameSite) == "none" && !c.Security.Session.Secure {
+		addf("security.session.same_site 为 none 时必须启用 secure")
+	}
+
+	if c.BootstrapAdmin.Password != "" && len(c.BootstrapAdmin.Password) < 8 {
+		addf("bootstrap_admin.password 至少需要8个字符")
+	}
+
+	// 生产环境禁止使用默认密钥
+	if c.IsRelease() {
+		if insecureSecrets[c.JWT.Secret] || len(c.JWT.Secret) < minSecretLength {
+			addf("生产环境必须通过 JWT_SECRET 设置至少 %d 个字符的随机密钥，不能使用默认值", minSecretLength)
+		}
+		if c.Database.Password == "" || insecureSecrets[c.Database.Password] {
+			addf("生产环境必须通过 DB_PASSWORD 设置数据库密码，不能使用默认值")
+		}
+		if insecureSecrets[c.BootstrapAdmin.Password] {
+			addf("生产环境不能使用默认的管理员密码，请修改 ADMIN_PASSWORD 或留空自动生成")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func validConfig() *Config {
+	return &Config{
+		Server:   ServerConfig{Port: "8080", Mode: "debug"},
+		Database: DatabaseConfig{Password: "dbapp123"},
+		Cache:    CacheConfig{Driver: "memory"},
+		JWT:      JWTConfig{Secret: DefaultJWTSecret, ExpiresIn: 3600},
+		File:     FileConfig{MaxSize: 1024, ChunkSize: 1024, MaxChunkedSize: 4096},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Driver:  "memory",
+			Lockout: LockoutConfig{BaseDuration: 60, MaxDuration: 3600},
+		},
+		Security: SecurityConfig{Session: SessionConfig{SameSite: "lax"}},
+	}
+}
+
+func TestValidate_DebugAllowsDefaults(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("开发环境允许使用默认密钥: %v", err)
+	}
+}
+
+func TestValidate_ReleaseRejectsDefaultSecrets(t *testing.T) {
+	cfg := validConfig()
+	cfg.Server.Mode = "release"
+	cfg.BootstrapAdmin.Password = "qweasdzxc"
+
+	err := cfg.Validate()
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("期望 *ValidationError, 得到 %v", err)
+	}
+	// 所有问题一次性返回
+	if len(verr.Problems) != 3 {
+		t.Fatalf("期望 3 个问题, 得到 %d: %v", len(verr.Problems), verr.Problems)
+	}
+	for i, want := range []string{"JWT_SECRET", "DB_PASSWORD", "ADMIN_PASSWORD"} {
+		if !strings.Contains(verr.Problems[i], want) {
+			t.Errorf("问题 %d 应提到 %s: %s", i, want, verr.Problems[i])
+		}
+	}
+
+	cfg.JWT.Secret = strings.Repeat("s", minSecretLength)
+	cfg.Database.Password = "a-real-password"
+	cfg.BootstrapAdmin.Password = ""
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("配置了安全密钥后应通过校验: %v", err)
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.Server.Port = "http"
+	cfg.Cache.Driver = "memcached"
+	cfg.CORS = CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+	cfg.Security.Session.SameSite = "none"
+	cfg.BootstrapAdmin.Password = "short"
+	cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "nginx"}
+
+	err := cfg.Validate()
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("期望 *ValidationError, 得到 %v", err)
+	}
+	if len(verr.Problems) != 6 {
+		t.Errorf("期望 6 个问题, 得到 %d: %v", len(verr.Problems), verr.Problems)
+	}
+	if !strings.Contains(err.Error(), "server.port") || !strings.Contains(err.Error(), "cache.driver") {
+		t.Errorf("错误信息应包含所有问题: %s", err.Error())
+	}
+}
+
+func TestGetConfig_PanicsOnInvalidConfig(t *testing.T) {
+	previous := GlobalConfig
+	GlobalConfig = nil
+	defer func() { GlobalConfig = previous }()
+
+	// 生产环境未配置 JWT 密钥时不能退回内置默认密钥
+	t.Setenv("SERVER_MODE", "release")
+	t.Setenv("JWT_SECRET", "default-secret-key")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("配置无效时 GetConfig 应 panic")
+		}
+		if GlobalConfig != nil {
+			t.Error("配置无效时不应保存到 GlobalConfig")
+		}
+	}()
+	GetConfig()
+}
@@ -7,7 +7,8 @@ type CreateArticleRequest struct {
 	CoverImageURL string   `json:"cover_image_url"`
 	CategoryIDs   []uint64 `json:"category_ids"`
 	TagIDs        []uint64 `json:"tag_ids"`
-	Status        string   `json:"status" binding:"oneof=draft published"`
+	Status        string   `json:"status" binding:"omitempty,oneof=draft published"` // 为空时使用站点设置的默认状态
+	Visibility    string   `json:"visibility" binding:"omitempty,oneof=public members private unlisted"` // 为空时为 public
 }
 
 type UpdateArticleRequest struct {
@@ -18,17 +19,37 @@ type UpdateArticleRequest struct {
 	CategoryIDs   []uint64 `json:"category_ids"`
 	TagIDs        []uint64 `json:"tag_ids"`
 	Status        string   `json:"status" binding:"oneof=draft published archived"`
+	Visibility    string   `json:"visibility" binding:"omitempty,oneof=public members private unlisted"`
+	// ResetShareToken 重新生成不公开文章的分享链接，旧链接失效
+	ResetShareToken bool `json:"reset_share_token"`
 }
 
 type ListArticleRequest struct {
-	Page       int    `form:"page"`
-	PageSize   int    `form:"page_size"`
+	Page       int     `form:"page"`
+	PageSize   int     `form:"page_size"`
+	Cursor     *string `form:"cursor"` // 带有该参数时使用游标分页，值为空表示第一页
 	CategoryID uint64 `form:"category_id"`
 	TagID      uint64 `form:"tag_id"`
 	AuthorID   uint64 `form:"author_id"`
 	Status     string `form:"status"`
 	Keyword    string `form:"keyword"`
 	Sort       string `form:"sort"`
-	Order      string `form:"order"`
+	Order      string `form:"order" binding:"omitempty,oneof=asc desc ASC DESC"`
+
+	// 多个分类、标签通过重复参数传递，如 category_ids=1&category_ids=2
+	CategoryIDs     []uint64 `form:"category_ids"`
+	CategoryMatch   string   `form:"category_match" binding:"omitempty,oneof=any all"`
+	IncludeChildren bool     `form:"include_children"` // 同时匹配所选分类的子孙分类
+	TagIDs          []uint64 `form:"tag_ids"`
+	TagMatch        string   `form:"tag_match" binding:"omitempty,oneof=any all"`
+
+	// 时间范围，RFC3339 时间或 2006-01-02 日期
+	CreatedFrom   string `form:"created_from"`
+	CreatedTo     string `form:"created_to"`
+	PublishedFrom string `form:"published_from"`
+	PublishedTo   string `form:"published_to"`
+
+	Featured *bool `form:"featured"`
+	Locked   *bool `form:"locked"`
 }
 
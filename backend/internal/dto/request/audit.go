@@ -0,0 +1,12 @@
+package request
+
+type ListAuditLogRequest struct {
+	UserID     uint64 `form:"user_id"`
+	Action     string `form:"action"`
+	TargetType string `form:"target_type"`
+	TargetID   uint64 `form:"target_id"`
+	From       string `form:"from"` // RFC3339 时间或 2006-01-02 日期，包含
+	To         string `form:"to"`   // RFC3339 时间或 2006-01-02 日期，不包含；为日期时包含当天
+	Page       int    `form:"page"`
+	PageSize   int    `form:"page_size"`
+}
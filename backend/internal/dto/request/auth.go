@@ -10,5 +10,7 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	// Cookie 为 true 且启用了 Cookie 会话时，Token 写入 HttpOnly Cookie 而不在响应中返回
+	Cookie bool `json:"cookie"`
 }
 
@@ -0,0 +1,37 @@
+package request
+
+type CreateBookmarkRequest struct {
+	ArticleID     uint64  `json:"article_id" binding:"required"`
+	ReadingListID *uint64 `json:"reading_list_id"` // 放入的阅读列表，不指定时不放入列表
+}
+
+// MoveBookmarkRequest 把收藏移到另一个阅读列表的末尾，ReadingListID 为空时移出列表
+type MoveBookmarkRequest struct {
+	ReadingListID *uint64 `json:"reading_list_id"`
+}
+
+type ListBookmarkRequest struct {
+	Page     int `form:"page"`
+	PageSize int `form:"page_size"`
+}
+
+type CreateReadingListRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=100"`
+	Description string `json:"description"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+type UpdateReadingListRequest struct {
+	Name        string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description"`
+	IsPublic    *bool   `json:"is_public"`
+}
+
+type ListReadingListRequest struct {
+	UserID uint64 `form:"user_id"` // 不指定时查询当前用户的阅读列表
+}
+
+// ReorderReadingListRequest 按给定顺序把这些收藏排在阅读列表最前面，列表中其余收藏保持原有顺序排在之后
+type ReorderReadingListRequest struct {
+	ArticleIDs []uint64 `json:"article_ids" binding:"required,min=1"`
+}
@@ -11,7 +11,8 @@ type UpdateCommentRequest struct {
 }
 
 type ListCommentRequest struct {
-	Page     int `form:"page"`
-	PageSize int `form:"page_size"`
+	Page     int     `form:"page"`
+	PageSize int     `form:"page_size"`
+	Cursor   *string `form:"cursor"` // 带有该参数时使用游标分页，值为空表示第一页
 }
 
@@ -0,0 +1,7 @@
+package request
+
+type InitUploadRequest struct {
+	Filename string `json:"filename" binding:"required,min=1,max=255"`
+	Size     int64  `json:"size" binding:"required,min=1"`
+	Checksum string `json:"checksum" binding:"required,len=64,hexadecimal"` // 整个文件的SHA-256（十六进制）
+}
@@ -0,0 +1,29 @@
+package request
+
+type CreateSeriesRequest struct {
+	Title       string   `json:"title" binding:"required,min=1,max=200"`
+	Description string   `json:"description"`
+	ArticleIDs  []uint64 `json:"article_ids"` // 按顺序排列的文章ID
+}
+
+type UpdateSeriesRequest struct {
+	Title       string  `json:"title" binding:"omitempty,min=1,max=200"`
+	Description *string `json:"description"`
+}
+
+type ListSeriesRequest struct {
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+	AuthorID uint64 `form:"author_id"`
+}
+
+// SetSeriesArticlesRequest 按顺序给出系列中的全部文章，用于调整顺序以及批量添加、移除文章
+type SetSeriesArticlesRequest struct {
+	ArticleIDs []uint64 `json:"article_ids" binding:"required"`
+}
+
+// AddSeriesArticleRequest 向系列添加一篇文章，Position 为插入位置（从 1 开始），不指定时添加到末尾
+type AddSeriesArticleRequest struct {
+	ArticleID uint64 `json:"article_id" binding:"required"`
+	Position  int    `json:"position" binding:"omitempty,min=1"`
+}
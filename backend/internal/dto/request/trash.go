@@ -0,0 +1,6 @@
+package request
+
+type ListTrashRequest struct {
+	Page     int `form:"page"`
+	PageSize int `form:"page_size"`
+}
@@ -19,7 +19,11 @@ type ArticleResponse struct {
 	CommentCount  int            `json:"comment_count"`
 	IsFeatured    bool           `json:"is_featured"`
 	IsLiked       bool           `json:"is_liked,omitempty"`
+	IsBookmarked  bool           `json:"is_bookmarked,omitempty"`
 	Status        string         `json:"status"`
+	Visibility    string         `json:"visibility"`
+	ShareToken    string         `json:"share_token,omitempty"` // 仅作者和编辑可见
+	Series        *SeriesNavigation `json:"series,omitempty"`      // 所在系列及前后篇，仅详情返回
 	PublishedAt   *time.Time     `json:"published_at"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
@@ -0,0 +1,23 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLogResponse struct {
+	ID         uint64          `json:"id"`
+	User       *UserResponse   `json:"user"` // 未登录用户或系统操作时为 null
+	IP         string          `json:"ip"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   uint64          `json:"target_id"`
+	Changes    json.RawMessage `json:"changes"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AuditLogListResponse struct {
+	Items      []AuditLogResponse `json:"items"`
+	Pagination Pagination         `json:"pagination"`
+}
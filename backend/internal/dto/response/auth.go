@@ -1,7 +1,7 @@
 package response
 
 type LoginResponse struct {
-	Token        string       `json:"token"`
+	Token        string       `json:"token,omitempty"` // Cookie 会话登录时不返回
 	RefreshToken string       `json:"refresh_token,omitempty"`
 	ExpiresIn    int          `json:"expires_in"`
 	User         *UserResponse `json:"user"`
@@ -0,0 +1,27 @@
+package response
+
+import "time"
+
+type BookmarkResponse struct {
+	ID            uint64           `json:"id"`
+	ReadingListID *uint64          `json:"reading_list_id"`
+	Position      int              `json:"position"` // 在阅读列表中的顺序，不在列表中时为 0
+	Article       *ArticleResponse `json:"article"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+type BookmarkListResponse struct {
+	Items      []*BookmarkResponse `json:"items"`
+	Pagination Pagination          `json:"pagination"`
+}
+
+type ReadingListResponse struct {
+	ID            uint64        `json:"id"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description"`
+	IsPublic      bool          `json:"is_public"`
+	User          *UserResponse `json:"user"`
+	BookmarkCount int64         `json:"bookmark_count"` // 列表中当前用户可见的文章数
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
@@ -5,6 +5,8 @@ type Pagination struct {
 	PageSize   int   `json:"page_size"`
 	Total      int64 `json:"total"`
 	TotalPages int   `json:"total_pages"`
+	// NextCursor 游标分页时下一页的游标，没有下一页时为空
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type UserResponse struct {
@@ -0,0 +1,27 @@
+package response
+
+import "time"
+
+type FileResponse struct {
+	URL  string `json:"url"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+type UploadSessionResponse struct {
+	UploadID  string    `json:"upload_id"`
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"`     // 已接收的字节数，续传时从此处开始
+	ChunkSize int64     `json:"chunk_size"` // 单个分片允许的最大字节数
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type QuotaResponse struct {
+	Role              string    `json:"role"`
+	StorageUsed       int64     `json:"storage_used"`
+	StorageLimit      int64     `json:"storage_limit"` // 0 表示不限制
+	DailyUploads      int64     `json:"daily_uploads"`
+	DailyUploadsLimit int       `json:"daily_uploads_limit"` // 0 表示不限制
+	ResetsAt          time.Time `json:"resets_at"`           // 每日上传次数重置时间
+}
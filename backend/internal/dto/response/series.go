@@ -0,0 +1,46 @@
+package response
+
+import "time"
+
+type SeriesResponse struct {
+	ID          uint64                  `json:"id"`
+	Title       string                  `json:"title"`
+	Description string                  `json:"description"`
+	Author      *UserResponse           `json:"author"`
+	Articles    []SeriesArticleResponse `json:"articles,omitempty"` // 仅详情返回，只包含当前用户可见的文章
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+type SeriesArticleResponse struct {
+	ID            uint64     `json:"id"`
+	Title         string     `json:"title"`
+	Slug          string     `json:"slug"`
+	Summary       string     `json:"summary"`
+	CoverImageURL string     `json:"cover_image_url"`
+	Position      int        `json:"position"` // 在系列中的序号，从 1 开始
+	Status        string     `json:"status"`
+	Visibility    string     `json:"visibility"`
+	PublishedAt   *time.Time `json:"published_at"`
+}
+
+type SeriesListResponse struct {
+	Items      []*SeriesResponse `json:"items"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+// SeriesNavigation 文章所在系列及系列中的前后篇，序号和总数只计算当前用户可见的文章
+type SeriesNavigation struct {
+	ID       uint64             `json:"id"`
+	Title    string             `json:"title"`
+	Position int                `json:"position"`
+	Total    int                `json:"total"`
+	Prev     *SeriesArticleLink `json:"prev"`
+	Next     *SeriesArticleLink `json:"next"`
+}
+
+type SeriesArticleLink struct {
+	ID    uint64 `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
@@ -0,0 +1,20 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SettingChangeResponse struct {
+	ID        uint64          `json:"id"`
+	Key       string          `json:"key"`
+	OldValue  json.RawMessage `json:"old_value"` // 修改前使用默认值时为 null
+	NewValue  json.RawMessage `json:"new_value"`
+	ChangedBy UserResponse    `json:"changed_by"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type SettingChangeListResponse struct {
+	Items      []SettingChangeResponse `json:"items"`
+	Pagination Pagination              `json:"pagination"`
+}
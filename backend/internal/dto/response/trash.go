@@ -0,0 +1,50 @@
+package response
+
+import "time"
+
+type DeletedArticleResponse struct {
+	ID        uint64        `json:"id"`
+	Title     string        `json:"title"`
+	Slug      string        `json:"slug"`
+	Summary   string        `json:"summary"`
+	Author    *UserResponse `json:"author"`
+	Status    string        `json:"status"`
+	DeletedAt time.Time     `json:"deleted_at"`
+	PurgeAt   time.Time     `json:"purge_at"` // 超过保留时间后彻底删除，不可恢复
+}
+
+type DeletedArticleListResponse struct {
+	Items      []DeletedArticleResponse `json:"items"`
+	Pagination Pagination               `json:"pagination"`
+}
+
+type DeletedCommentResponse struct {
+	ID        uint64        `json:"id"`
+	ArticleID uint64        `json:"article_id"`
+	ParentID  *uint64       `json:"parent_id"`
+	Content   string        `json:"content"`
+	User      *UserResponse `json:"user"`
+	Status    string        `json:"status"`
+	DeletedAt time.Time     `json:"deleted_at"`
+	PurgeAt   time.Time     `json:"purge_at"`
+}
+
+type DeletedCommentListResponse struct {
+	Items      []DeletedCommentResponse `json:"items"`
+	Pagination Pagination               `json:"pagination"`
+}
+
+// DeletedUserResponse 回收站中的用户，用户不会被彻底删除，没有 purge_at
+type DeletedUserResponse struct {
+	ID        uint64    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Nickname  string    `json:"nickname"`
+	Role      string    `json:"role"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type DeletedUserListResponse struct {
+	Items      []DeletedUserResponse `json:"items"`
+	Pagination Pagination            `json:"pagination"`
+}
@@ -1,14 +1,21 @@
 package errors
 
 import (
+	"dbapp/pkg/logger"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"math"
+	"strconv"
+	"time"
 )
 
 type AppError struct {
 	Code    int
 	Message string
 	Err     error
+	// RetryAfter 客户端应等待的时间，大于0时通过 Retry-After 响应头返回
+	RetryAfter time.Duration
 }
 
 func (e *AppError) Error() string {
@@ -18,6 +25,22 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// WithCause 记录底层错误，底层错误只写入日志，不返回给客户端
+func (e *AppError) WithCause(err error) *AppError {
+	e.Err = err
+	return e
+}
+
+// WithRetryAfter 设置客户端重试前应等待的时间，用于 429 等可重试的错误
+func (e *AppError) WithRetryAfter(d time.Duration) *AppError {
+	e.RetryAfter = d
+	return e
+}
+
 func NewBadRequestError(message string) *AppError {
 	return &AppError{Code: 400, Message: message}
 }
@@ -34,21 +57,55 @@ func NewNotFoundError(message string) *AppError {
 	return &AppError{Code: 404, Message: message}
 }
 
+func NewConflictError(message string) *AppError {
+	return &AppError{Code: 409, Message: message}
+}
+
+func NewPayloadTooLargeError(message string) *AppError {
+	return &AppError{Code: 413, Message: message}
+}
+
+func NewTooManyRequestsError(message string) *AppError {
+	return &AppError{Code: 429, Message: message}
+}
+
 func NewInternalError(message string) *AppError {
 	return &AppError{Code: 500, Message: message}
 }
 
+// HandleError 返回错误响应，服务端错误会连同底层错误和请求ID写入日志
 func HandleError(c *gin.Context, err error) {
-	if appErr, ok := err.(*AppError); ok {
-		c.JSON(appErr.Code, gin.H{
-			"code":    appErr.Code,
-			"message": appErr.Message,
-		})
-	} else {
-		c.JSON(500, gin.H{
-			"code":    500,
-			"message": "服务器内部错误",
-		})
+	requestID := c.GetString(logger.RequestIDKey)
+	appErr, ok := err.(*AppError)
+	if !ok {
+		appErr = NewInternalError("服务器内部错误").WithCause(err)
+	}
+
+	if appErr.Code >= 500 {
+		fields := []zap.Field{
+			zap.Int("code", appErr.Code),
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+		}
+		if appErr.Err != nil {
+			fields = append(fields, zap.String("error", appErr.Err.Error()))
+		}
+		logger.FromContext(c.Request.Context()).Error(appErr.Message, fields...)
+	}
+
+	body := gin.H{
+		"code":    appErr.Code,
+		"message": appErr.Message,
+	}
+	if requestID != "" {
+		body["request_id"] = requestID
+	}
+	if appErr.RetryAfter > 0 {
+		// Retry-After 只支持整秒，向上取整避免客户端过早重试
+		seconds := int(math.Ceil(appErr.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		body["retry_after"] = seconds
 	}
+	c.JSON(appErr.Code, body)
 }
 
@@ -32,7 +32,7 @@ func NewArticleHandler(articleService *service.ArticleService) *ArticleHandler {
 func (h *ArticleHandler) GetArticleList(c *gin.Context) {
 	var req request.ListArticleRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
 		return
 	}
 
@@ -41,7 +41,7 @@ func (h *ArticleHandler) GetArticleList(c *gin.Context) {
 		userID = uid.(uint64)
 	}
 
-	result, err := h.articleService.List(&req, userID)
+	result, err := h.articleService.WithContext(c.Request.Context()).List(&req, userID, c.GetString("role"))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -59,6 +59,7 @@ func (h *ArticleHandler) GetArticleList(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "文章ID"
+// @Param share_token query string false "不公开文章的分享令牌"
 // @Success 200 {object} response.ArticleResponse
 // @Router /api/v1/articles/{id} [get]
 func (h *ArticleHandler) GetArticleDetail(c *gin.Context) {
@@ -73,12 +74,15 @@ func (h *ArticleHandler) GetArticleDetail(c *gin.Context) {
 		userID = uid.(uint64)
 	}
 
-	article, err := h.articleService.GetByID(id, userID)
+	article, err := h.articleService.WithContext(c.Request.Context()).GetByID(id, userID, c.GetString("role"), c.Query("share_token"))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
 	}
 
+	// 增加浏览次数
+	h.articleService.RecordView(id, userID, c.ClientIP(), c.Request.UserAgent())
+
 	c.JSON(200, gin.H{
 		"code": 200,
 		"data": article,
@@ -104,7 +108,7 @@ func (h *ArticleHandler) CreateArticle(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDUint := userID.(uint64)
 
-	article, err := h.articleService.Create(&req, userIDUint)
+	article, err := h.articleService.WithContext(c.Request.Context()).Create(&req, userIDUint)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -143,7 +147,7 @@ func (h *ArticleHandler) UpdateArticle(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDUint := userID.(uint64)
 
-	article, err := h.articleService.Update(id, &req, userIDUint)
+	article, err := h.articleService.WithContext(c.Request.Context()).Update(id, &req, userIDUint)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -175,7 +179,7 @@ func (h *ArticleHandler) DeleteArticle(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDUint := userID.(uint64)
 
-	if err := h.articleService.Delete(id, userIDUint); err != nil {
+	if err := h.articleService.WithContext(c.Request.Context()).Delete(id, userIDUint); err != nil {
 		errors.HandleError(c, err)
 		return
 	}
@@ -8,11 +8,13 @@ import (
 	"dbapp/internal/repository"
 	"dbapp/internal/service"
 	"dbapp/internal/test"
+	"dbapp/pkg/cache"
 	"dbapp/pkg/utils"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -35,7 +37,13 @@ func TestArticleHandler_GetArticleList(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := service.NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := service.NewArticleService(service.ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 	articleHandler := NewArticleHandler(articleService)
 
 	// 创建测试数据
@@ -65,7 +73,13 @@ func TestArticleHandler_GetArticleDetail(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := service.NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := service.NewArticleService(service.ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 	articleHandler := NewArticleHandler(articleService)
 
 	// 创建测试数据
@@ -90,7 +104,13 @@ func TestArticleHandler_CreateArticle(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := service.NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := service.NewArticleService(service.ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 	articleHandler := NewArticleHandler(articleService)
 
 	// 创建测试用户
@@ -128,7 +148,13 @@ func TestArticleHandler_CreateArticle_Unauthorized(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := service.NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := service.NewArticleService(service.ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 	articleHandler := NewArticleHandler(articleService)
 
 	router := setupRouter()
@@ -149,4 +175,3 @@ func TestArticleHandler_CreateArticle_Unauthorized(t *testing.T) {
 
 	assert.Equal(t, 401, w.Code)
 }
-
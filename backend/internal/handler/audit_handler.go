@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"dbapp/internal/dto/request"
+	"dbapp/internal/errors"
+	"dbapp/internal/service"
+	"dbapp/pkg/logger"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// GetAuditLogs 查询审计日志
+// @Summary 查询审计日志（管理员）
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "操作者ID"
+// @Param action query string false "操作类型"
+// @Param target_type query string false "目标类型"
+// @Param target_id query int false "目标ID"
+// @Param from query string false "开始时间，RFC3339 或 2006-01-02"
+// @Param to query string false "结束时间，RFC3339 或 2006-01-02"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.AuditLogListResponse
+// @Router /api/v1/admin/audit-logs [get]
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	var req request.ListAuditLogRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		return
+	}
+
+	result, err := h.auditService.WithContext(c.Request.Context()).List(&req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// ExportAuditLogs 导出审计日志
+// @Summary 按查询条件导出审计日志为CSV（管理员）
+// @Tags 管理
+// @Produce text/csv
+// @Security BearerAuth
+// @Param user_id query int false "操作者ID"
+// @Param action query string false "操作类型"
+// @Param target_type query string false "目标类型"
+// @Param target_id query int false "目标ID"
+// @Param from query string false "开始时间，RFC3339 或 2006-01-02"
+// @Param to query string false "结束时间，RFC3339 或 2006-01-02"
+// @Success 200 {file} file
+// @Router /api/v1/admin/audit-logs/export [get]
+func (h *AuditHandler) ExportAuditLogs(c *gin.Context) {
+	var req request.ListAuditLogRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		return
+	}
+
+	auditService := h.auditService.WithContext(c.Request.Context())
+	// 开始输出后无法再返回错误响应，先校验参数
+	if err := auditService.ValidateFilter(&req); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	filename := "audit-logs-" + time.Now().Format("20060102-150405") + ".csv"
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(200)
+	// UTF-8 BOM，使 Excel 正确识别中文
+	c.Writer.WriteString("\xEF\xBB\xBF")
+
+	if err := auditService.Export(&req, c.Writer); err != nil {
+		logger.FromContext(c.Request.Context()).Error("导出审计日志失败", zap.String("error", err.Error()))
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"dbapp/internal/config"
 	"dbapp/internal/dto/request"
 	"dbapp/internal/errors"
 	"dbapp/internal/service"
@@ -9,11 +10,13 @@ import (
 
 type AuthHandler struct {
 	userService *service.UserService
+	session     config.SessionConfig
 }
 
-func NewAuthHandler(userService *service.UserService) *AuthHandler {
+func NewAuthHandler(userService *service.UserService, session config.SessionConfig) *AuthHandler {
 	return &AuthHandler{
 		userService: userService,
+		session:     session,
 	}
 }
 
@@ -32,7 +35,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.Register(&req)
+	user, err := h.userService.WithContext(c.Request.Context()).Register(&req)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -60,18 +63,57 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	result, err := h.userService.Login(&req)
+	result, err := h.userService.WithContext(c.Request.Context()).Login(&req)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
 	}
 
+	// Cookie 会话：Token 只写入 HttpOnly Cookie，不在响应中返回
+	if req.Cookie && h.session.Enabled {
+		setSessionCookies(c, h.session, result.Token, result.ExpiresIn)
+		result.Token = ""
+	}
+
 	c.JSON(200, gin.H{
 		"code": 200,
 		"data": result,
 	})
 }
 
+// Logout 退出登录，清除会话 Cookie
+// @Summary 退出登录
+// @Tags 认证
+// @Produce json
+// @Success 200
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if h.session.Enabled {
+		clearSessionCookies(c, h.session)
+	}
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "登出成功",
+	})
+}
+
+// GetCSRFToken 重新签发 CSRF Token，用于 CSRF Cookie 丢失但会话仍有效的情况
+// @Summary 获取CSRF Token
+// @Tags 认证
+// @Produce json
+// @Success 200
+// @Router /api/v1/auth/csrf [get]
+func (h *AuthHandler) GetCSRFToken(c *gin.Context) {
+	if !h.session.Enabled {
+		errors.HandleError(c, errors.NewNotFoundError("未启用Cookie会话"))
+		return
+	}
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": gin.H{"csrf_token": setCSRFCookie(c, h.session)},
+	})
+}
+
 // GetMe 获取当前用户信息
 // @Summary 获取当前用户信息
 // @Tags 认证
@@ -84,7 +126,7 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDUint := userID.(uint64)
 
-	user, err := h.userService.GetByID(userIDUint)
+	user, err := h.userService.WithContext(c.Request.Context()).GetByID(userIDUint)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
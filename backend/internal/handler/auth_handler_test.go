@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"dbapp/internal/config"
 	"dbapp/internal/dto/request"
 	"dbapp/internal/repository"
 	"dbapp/internal/service"
@@ -25,8 +26,8 @@ func TestAuthHandler_Register(t *testing.T) {
 	defer test.TeardownTestDB(db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
-	authHandler := NewAuthHandler(userService)
+	userService := service.NewUserService(userRepo, nil, nil, nil)
+	authHandler := NewAuthHandler(userService, config.SessionConfig{})
 
 	router := setupRouter()
 	router.POST("/api/v1/auth/register", authHandler.Register)
@@ -57,8 +58,8 @@ func TestAuthHandler_Register_InvalidData(t *testing.T) {
 	defer test.TeardownTestDB(db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
-	authHandler := NewAuthHandler(userService)
+	userService := service.NewUserService(userRepo, nil, nil, nil)
+	authHandler := NewAuthHandler(userService, config.SessionConfig{})
 
 	router := setupRouter()
 	router.POST("/api/v1/auth/register", authHandler.Register)
@@ -83,8 +84,8 @@ func TestAuthHandler_Login(t *testing.T) {
 	defer test.TeardownTestDB(db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
-	authHandler := NewAuthHandler(userService)
+	userService := service.NewUserService(userRepo, nil, nil, nil)
+	authHandler := NewAuthHandler(userService, config.SessionConfig{})
 
 	// 先注册用户
 	registerReq := &request.RegisterRequest{
@@ -125,8 +126,8 @@ func TestAuthHandler_Login_InvalidCredentials(t *testing.T) {
 	defer test.TeardownTestDB(db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
-	authHandler := NewAuthHandler(userService)
+	userService := service.NewUserService(userRepo, nil, nil, nil)
+	authHandler := NewAuthHandler(userService, config.SessionConfig{})
 
 	router := setupRouter()
 	router.POST("/api/v1/auth/login", authHandler.Login)
@@ -146,3 +147,69 @@ func TestAuthHandler_Login_InvalidCredentials(t *testing.T) {
 	assert.Equal(t, 401, w.Code)
 }
 
+
+func TestAuthHandler_Login_CookieSession(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	userRepo := repository.NewUserRepository(db)
+	userService := service.NewUserService(userRepo, nil, nil, nil)
+	authHandler := NewAuthHandler(userService, config.SessionConfig{
+		Enabled:        true,
+		CookieName:     "dbapp_session",
+		CSRFCookieName: "dbapp_csrf",
+		SameSite:       "strict",
+	})
+
+	_, err := userService.Register(&request.RegisterRequest{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("注册用户失败: %v", err)
+	}
+
+	router := setupRouter()
+	router.POST("/api/v1/auth/login", authHandler.Login)
+	router.POST("/api/v1/auth/logout", authHandler.Logout)
+
+	jsonData, _ := json.Marshal(request.LoginRequest{
+		Username: "testuser",
+		Password: "password123",
+		Cookie:   true,
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	session := cookies["dbapp_session"]
+	csrf := cookies["dbapp_csrf"]
+	if assert.NotNil(t, session) && assert.NotNil(t, csrf) {
+		assert.True(t, session.HttpOnly)
+		assert.False(t, csrf.HttpOnly)
+		assert.Equal(t, http.SameSiteStrictMode, session.SameSite)
+		assert.NotEmpty(t, csrf.Value)
+	}
+
+	// Token 只在 Cookie 中，不在响应中返回
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	assert.NotContains(t, data, "token")
+
+	req, _ = http.NewRequest("POST", "/api/v1/auth/logout", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	for _, cookie := range w.Result().Cookies() {
+		assert.Equal(t, -1, cookie.MaxAge, cookie.Name)
+	}
+}
@@ -0,0 +1,355 @@
+package handler
+
+import (
+	"dbapp/internal/dto/request"
+	"dbapp/internal/errors"
+	"dbapp/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookmarkHandler struct {
+	bookmarkService *service.BookmarkService
+}
+
+func NewBookmarkHandler(bookmarkService *service.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{
+		bookmarkService: bookmarkService,
+	}
+}
+
+// GetBookmarks 获取当前用户的收藏
+// @Summary 获取当前用户的收藏，按收藏时间倒序
+// @Tags 收藏
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.BookmarkListResponse
+// @Router /api/v1/bookmarks [get]
+func (h *BookmarkHandler) GetBookmarks(c *gin.Context) {
+	var req request.ListBookmarkRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		return
+	}
+
+	result, err := h.bookmarkService.WithContext(c.Request.Context()).
+		List(&req, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// CreateBookmark 收藏文章
+// @Summary 收藏文章，可同时放入阅读列表
+// @Tags 收藏
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bookmark body request.CreateBookmarkRequest true "文章ID和阅读列表ID"
+// @Success 201 {object} response.BookmarkResponse
+// @Router /api/v1/bookmarks [post]
+func (h *BookmarkHandler) CreateBookmark(c *gin.Context) {
+	var req request.CreateBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
+		return
+	}
+
+	bookmark, err := h.bookmarkService.WithContext(c.Request.Context()).
+		Add(&req, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"code":    201,
+		"message": "收藏成功",
+		"data":    bookmark,
+	})
+}
+
+// MoveBookmark 移动收藏
+// @Summary 把收藏移到另一个阅读列表的末尾，reading_list_id 为空时移出列表
+// @Tags 收藏
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param article_id path int true "文章ID"
+// @Param bookmark body request.MoveBookmarkRequest true "阅读列表ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/bookmarks/{article_id} [put]
+func (h *BookmarkHandler) MoveBookmark(c *gin.Context) {
+	articleID, err := strconv.ParseUint(c.Param("article_id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的文章ID"))
+		return
+	}
+
+	var req request.MoveBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
+		return
+	}
+
+	if err := h.bookmarkService.WithContext(c.Request.Context()).
+		Move(articleID, &req, c.GetUint64("user_id")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "移动成功",
+	})
+}
+
+// DeleteBookmark 取消收藏
+// @Summary 取消收藏
+// @Tags 收藏
+// @Produce json
+// @Security BearerAuth
+// @Param article_id path int true "文章ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/bookmarks/{article_id} [delete]
+func (h *BookmarkHandler) DeleteBookmark(c *gin.Context) {
+	articleID, err := strconv.ParseUint(c.Param("article_id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的文章ID"))
+		return
+	}
+
+	if err := h.bookmarkService.WithContext(c.Request.Context()).
+		Remove(articleID, c.GetUint64("user_id")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "已取消收藏",
+	})
+}
+
+// GetReadingLists 获取阅读列表
+// @Summary 获取用户的阅读列表，查询其他用户时只返回公开的列表，不指定用户时返回当前用户的
+// @Tags 收藏
+// @Produce json
+// @Param user_id query int false "用户ID"
+// @Success 200 {array} response.ReadingListResponse
+// @Router /api/v1/reading-lists [get]
+func (h *BookmarkHandler) GetReadingLists(c *gin.Context) {
+	var req request.ListReadingListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		return
+	}
+
+	lists, err := h.bookmarkService.WithContext(c.Request.Context()).
+		ListLists(&req, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": lists,
+	})
+}
+
+// GetReadingListDetail 获取阅读列表详情
+// @Summary 获取阅读列表详情，其他用户的非公开列表返回404
+// @Tags 收藏
+// @Produce json
+// @Param id path int true "阅读列表ID"
+// @Success 200 {object} response.ReadingListResponse
+// @Router /api/v1/reading-lists/{id} [get]
+func (h *BookmarkHandler) GetReadingListDetail(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的阅读列表ID"))
+		return
+	}
+
+	list, err := h.bookmarkService.WithContext(c.Request.Context()).
+		GetList(id, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": list,
+	})
+}
+
+// GetReadingListBookmarks 获取阅读列表中的收藏
+// @Summary 按列表中的顺序获取阅读列表中的收藏，只包含当前用户可见的文章
+// @Tags 收藏
+// @Produce json
+// @Param id path int true "阅读列表ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.BookmarkListResponse
+// @Router /api/v1/reading-lists/{id}/bookmarks [get]
+func (h *BookmarkHandler) GetReadingListBookmarks(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的阅读列表ID"))
+		return
+	}
+
+	var req request.ListBookmarkRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		return
+	}
+
+	result, err := h.bookmarkService.WithContext(c.Request.Context()).
+		ListBookmarks(id, &req, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// CreateReadingList 创建阅读列表
+// @Summary 创建阅读列表
+// @Tags 收藏
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param list body request.CreateReadingListRequest true "阅读列表信息"
+// @Success 201 {object} response.ReadingListResponse
+// @Router /api/v1/reading-lists [post]
+func (h *BookmarkHandler) CreateReadingList(c *gin.Context) {
+	var req request.CreateReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
+		return
+	}
+
+	list, err := h.bookmarkService.WithContext(c.Request.Context()).CreateList(&req, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"code":    201,
+		"message": "创建成功",
+		"data":    list,
+	})
+}
+
+// UpdateReadingList 更新阅读列表
+// @Summary 更新阅读列表
+// @Tags 收藏
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "阅读列表ID"
+// @Param list body request.UpdateReadingListRequest true "阅读列表信息"
+// @Success 200 {object} response.ReadingListResponse
+// @Router /api/v1/reading-lists/{id} [put]
+func (h *BookmarkHandler) UpdateReadingList(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的阅读列表ID"))
+		return
+	}
+
+	var req request.UpdateReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
+		return
+	}
+
+	list, err := h.bookmarkService.WithContext(c.Request.Context()).UpdateList(id, &req, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "更新成功",
+		"data":    list,
+	})
+}
+
+// DeleteReadingList 删除阅读列表
+// @Summary 删除阅读列表，列表中的收藏保留
+// @Tags 收藏
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "阅读列表ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/reading-lists/{id} [delete]
+func (h *BookmarkHandler) DeleteReadingList(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的阅读列表ID"))
+		return
+	}
+
+	if err := h.bookmarkService.WithContext(c.Request.Context()).DeleteList(id, c.GetUint64("user_id")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "删除成功",
+	})
+}
+
+// ReorderReadingList 调整阅读列表顺序
+// @Summary 按给定顺序把这些收藏排在阅读列表最前面，其余收藏保持原有顺序排在之后
+// @Tags 收藏
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "阅读列表ID"
+// @Param order body request.ReorderReadingListRequest true "按顺序排列的文章ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/reading-lists/{id}/order [put]
+func (h *BookmarkHandler) ReorderReadingList(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的阅读列表ID"))
+		return
+	}
+
+	var req request.ReorderReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
+		return
+	}
+
+	if err := h.bookmarkService.WithContext(c.Request.Context()).Reorder(id, &req, c.GetUint64("user_id")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "更新成功",
+	})
+}
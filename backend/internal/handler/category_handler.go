@@ -35,7 +35,7 @@ func (h *CategoryHandler) GetCategoryList(c *gin.Context) {
 		return
 	}
 
-	categories, err := h.categoryService.List(&req)
+	categories, err := h.categoryService.WithContext(c.Request.Context()).List(&req)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -62,7 +62,7 @@ func (h *CategoryHandler) GetCategoryDetail(c *gin.Context) {
 		return
 	}
 
-	category, err := h.categoryService.GetByID(id)
+	category, err := h.categoryService.WithContext(c.Request.Context()).GetByID(id)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -89,7 +89,7 @@ func (h *CategoryHandler) GetCategoryBySlug(c *gin.Context) {
 		return
 	}
 
-	category, err := h.categoryService.GetBySlug(slug)
+	category, err := h.categoryService.WithContext(c.Request.Context()).GetBySlug(slug)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -117,7 +117,7 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := h.categoryService.Create(&req)
+	category, err := h.categoryService.WithContext(c.Request.Context()).Create(&req)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -153,7 +153,7 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := h.categoryService.Update(id, &req)
+	category, err := h.categoryService.WithContext(c.Request.Context()).Update(id, &req)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -182,7 +182,7 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := h.categoryService.Delete(id); err != nil {
+	if err := h.categoryService.WithContext(c.Request.Context()).Delete(id); err != nil {
 		errors.HandleError(c, err)
 		return
 	}
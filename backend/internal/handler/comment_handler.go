@@ -26,6 +26,7 @@ func NewCommentHandler(commentService *service.CommentService) *CommentHandler {
 // @Param article_id path int true "文章ID"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(20)
+// @Param share_token query string false "不公开文章的分享令牌"
 // @Success 200 {object} response.CommentListResponse
 // @Router /api/v1/articles/{article_id}/comments [get]
 func (h *CommentHandler) GetCommentList(c *gin.Context) {
@@ -46,7 +47,7 @@ func (h *CommentHandler) GetCommentList(c *gin.Context) {
 		userID = uid.(uint64)
 	}
 
-	result, err := h.commentService.ListByArticle(articleID, &req, userID)
+	result, err := h.commentService.WithContext(c.Request.Context()).ListByArticle(articleID, &req, userID, c.GetString("role"), c.Query("share_token"))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -66,6 +67,7 @@ func (h *CommentHandler) GetCommentList(c *gin.Context) {
 // @Security BearerAuth
 // @Param article_id path int true "文章ID"
 // @Param comment body request.CreateCommentRequest true "评论信息"
+// @Param share_token query string false "不公开文章的分享令牌"
 // @Success 201 {object} response.CommentResponse
 // @Router /api/v1/articles/{article_id}/comments [post]
 func (h *CommentHandler) CreateComment(c *gin.Context) {
@@ -87,15 +89,19 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDUint := userID.(uint64)
 
-	comment, err := h.commentService.Create(&req, userIDUint)
+	comment, err := h.commentService.WithContext(c.Request.Context()).Create(&req, userIDUint, c.GetString("role"), c.Query("share_token"))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
 	}
 
+	message := "评论成功"
+	if comment.Status == "pending" {
+		message = "评论已提交，审核通过后展示"
+	}
 	c.JSON(201, gin.H{
 		"code":    201,
-		"message": "评论成功",
+		"message": message,
 		"data":    comment,
 	})
 }
@@ -126,7 +132,7 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDUint := userID.(uint64)
 
-	comment, err := h.commentService.Update(id, &req, userIDUint)
+	comment, err := h.commentService.WithContext(c.Request.Context()).Update(id, &req, userIDUint)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -158,7 +164,7 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDUint := userID.(uint64)
 
-	if err := h.commentService.Delete(id, userIDUint); err != nil {
+	if err := h.commentService.WithContext(c.Request.Context()).Delete(id, userIDUint); err != nil {
 		errors.HandleError(c, err)
 		return
 	}
@@ -169,3 +175,87 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	})
 }
 
+
+// GetPendingComments 获取待审核评论列表
+// @Summary 获取待审核评论列表（管理员）
+// @Tags 评论
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.CommentListResponse
+// @Router /api/v1/admin/comments/pending [get]
+func (h *CommentHandler) GetPendingComments(c *gin.Context) {
+	var req request.ListCommentRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		return
+	}
+
+	result, err := h.commentService.WithContext(c.Request.Context()).ListPending(&req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// ApproveComment 审核通过评论
+// @Summary 审核通过评论（管理员）
+// @Tags 评论
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "评论ID"
+// @Success 200 {object} response.CommentResponse
+// @Router /api/v1/admin/comments/{id}/approve [post]
+func (h *CommentHandler) ApproveComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的评论ID"))
+		return
+	}
+
+	comment, err := h.commentService.WithContext(c.Request.Context()).Approve(id)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "审核通过",
+		"data":    comment,
+	})
+}
+
+// RejectComment 拒绝待审核评论
+// @Summary 拒绝待审核评论（管理员）
+// @Tags 评论
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "评论ID"
+// @Success 200 {object} response.CommentResponse
+// @Router /api/v1/admin/comments/{id}/reject [post]
+func (h *CommentHandler) RejectComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的评论ID"))
+		return
+	}
+
+	comment, err := h.commentService.WithContext(c.Request.Context()).Reject(id)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "已拒绝",
+		"data":    comment,
+	})
+}
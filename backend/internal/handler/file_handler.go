@@ -1,26 +1,23 @@
 package handler
 
 import (
-	"dbapp/internal/config"
+	"dbapp/internal/dto/request"
 	"dbapp/internal/errors"
-	"fmt"
-	"io"
-	"os"
-	"path/filepath"
-	"strings"
-	"time"
+	"dbapp/internal/service"
+	"dbapp/pkg/logger"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type FileHandler struct {
-	cfg *config.Config
+	uploadService *service.UploadService
 }
 
-func NewFileHandler(cfg *config.Config) *FileHandler {
+func NewFileHandler(uploadService *service.UploadService) *FileHandler {
 	return &FileHandler{
-		cfg: cfg,
+		uploadService: uploadService,
 	}
 }
 
@@ -34,73 +31,200 @@ func (h *FileHandler) UploadFile(c *gin.Context) {
 	}
 
 	// 检查文件大小
-	if file.Size > h.cfg.File.MaxSize {
-		errors.HandleError(c, errors.NewBadRequestError(fmt.Sprintf("文件大小不能超过 %d MB", h.cfg.File.MaxSize/1024/1024)))
+	if err := h.uploadService.CheckSize(file.Size); err != nil {
+		errors.HandleError(c, err)
 		return
 	}
 
 	// 检查文件扩展名
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	ext = strings.TrimPrefix(ext, ".")
-	allowed := false
-	for _, allowedExt := range h.cfg.File.AllowedExt {
-		if ext == strings.ToLower(allowedExt) {
-			allowed = true
-			break
-		}
-	}
-	if !allowed {
-		errors.HandleError(c, errors.NewBadRequestError(fmt.Sprintf("不支持的文件类型，允许的类型: %v", h.cfg.File.AllowedExt)))
+	if err := h.uploadService.CheckExt(file.Filename); err != nil {
+		errors.HandleError(c, err)
 		return
 	}
 
-	// 创建上传目录
-	uploadPath := h.cfg.File.UploadPath
-	if uploadPath == "" {
-		uploadPath = "./uploads"
-	}
-	if err := os.MkdirAll(uploadPath, 0755); err != nil {
-		zap.L().Error("创建上传目录失败", zap.String("error", err.Error()))
-		errors.HandleError(c, errors.NewInternalError("创建上传目录失败"))
+	// 检查上传配额
+	userID, _ := c.Get("user_id")
+	userIDUint := userID.(uint64)
+	if err := h.uploadService.WithContext(c.Request.Context()).CheckQuota(userIDUint, c.GetString("role"), file.Size); err != nil {
+		errors.HandleError(c, err)
 		return
 	}
 
-	// 生成唯一文件名
-	filename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), file.Filename)
-	filePath := filepath.Join(uploadPath, filename)
-
 	// 保存文件
 	src, err := file.Open()
 	if err != nil {
-		zap.L().Error("打开上传文件失败", zap.String("error", err.Error()))
+		logger.FromContext(c.Request.Context()).Error("打开上传文件失败", zap.String("error", err.Error()))
 		errors.HandleError(c, errors.NewInternalError("打开上传文件失败"))
 		return
 	}
 	defer src.Close()
 
-	dst, err := os.Create(filePath)
+	result, err := h.uploadService.WithContext(c.Request.Context()).Save(userIDUint, c.GetString("role"), src, file.Filename, file.Size)
 	if err != nil {
-		zap.L().Error("创建文件失败", zap.String("error", err.Error()))
-		errors.HandleError(c, errors.NewInternalError("创建文件失败"))
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// InitUpload 创建分片上传会话
+// @Summary 创建分片上传会话
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param upload body request.InitUploadRequest true "文件信息"
+// @Success 201 {object} response.UploadSessionResponse
+// @Router /api/v1/files/uploads [post]
+func (h *FileHandler) InitUpload(c *gin.Context) {
+	var req request.InitUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
 		return
 	}
-	defer dst.Close()
 
-	if _, err := io.Copy(dst, src); err != nil {
-		zap.L().Error("保存文件失败", zap.String("error", err.Error()))
-		errors.HandleError(c, errors.NewInternalError("保存文件失败"))
+	userID, _ := c.Get("user_id")
+	userIDUint := userID.(uint64)
+
+	session, err := h.uploadService.WithContext(c.Request.Context()).InitSession(userIDUint, c.GetString("role"), req.Filename, req.Size, req.Checksum)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"code":    201,
+		"message": "创建成功",
+		"data":    session,
+	})
+}
+
+// GetUpload 查询分片上传进度
+// @Summary 查询分片上传进度
+// @Tags 文件
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "上传会话ID"
+// @Success 200 {object} response.UploadSessionResponse
+// @Router /api/v1/files/uploads/{id} [get]
+func (h *FileHandler) GetUpload(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userIDUint := userID.(uint64)
+
+	session, err := h.uploadService.WithContext(c.Request.Context()).GetSession(c.Param("id"), userIDUint)
+	if err != nil {
+		errors.HandleError(c, err)
 		return
 	}
 
-	// 返回文件URL
-	fileURL := fmt.Sprintf("/uploads/%s", filename)
 	c.JSON(200, gin.H{
 		"code": 200,
-		"data": gin.H{
-			"url":  fileURL,
-			"name": file.Filename,
-			"size": file.Size,
-		},
+		"data": session,
 	})
 }
 
+// UploadChunk 上传一个分片
+// @Summary 上传一个分片
+// @Tags 文件
+// @Accept application/octet-stream
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "上传会话ID"
+// @Param offset query int true "分片在文件中的起始偏移"
+// @Success 200 {object} response.UploadSessionResponse
+// @Router /api/v1/files/uploads/{id} [put]
+func (h *FileHandler) UploadChunk(c *gin.Context) {
+	offset, err := strconv.ParseInt(c.Query("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		errors.HandleError(c, errors.NewBadRequestError("无效的分片偏移"))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	userIDUint := userID.(uint64)
+
+	session, err := h.uploadService.WithContext(c.Request.Context()).WriteChunk(c.Param("id"), userIDUint, offset, c.Request.Body)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": session,
+	})
+}
+
+// CompleteUpload 完成分片上传
+// @Summary 完成分片上传，校验文件并返回访问地址
+// @Tags 文件
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "上传会话ID"
+// @Success 200 {object} response.FileResponse
+// @Router /api/v1/files/uploads/{id}/complete [post]
+func (h *FileHandler) CompleteUpload(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userIDUint := userID.(uint64)
+
+	result, err := h.uploadService.WithContext(c.Request.Context()).Complete(c.Param("id"), userIDUint, c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// AbortUpload 取消分片上传
+// @Summary 取消分片上传
+// @Tags 文件
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "上传会话ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/files/uploads/{id} [delete]
+func (h *FileHandler) AbortUpload(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userIDUint := userID.(uint64)
+
+	if err := h.uploadService.WithContext(c.Request.Context()).Abort(c.Param("id"), userIDUint); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "已取消上传",
+	})
+}
+
+// GetQuota 查询当前用户的上传配额
+// @Summary 查询当前用户的上传配额与用量
+// @Tags 文件
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.QuotaResponse
+// @Router /api/v1/files/quota [get]
+func (h *FileHandler) GetQuota(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userIDUint := userID.(uint64)
+
+	quota, err := h.uploadService.WithContext(c.Request.Context()).GetQuota(userIDUint, c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": quota,
+	})
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"dbapp/pkg/buildinfo"
+	"dbapp/pkg/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+// Livez 存活检查，只表示进程能处理请求，不检查依赖
+// @Summary 存活检查
+// @Tags 运维
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /livez [get]
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": health.StatusUp,
+	})
+}
+
+// Readyz 就绪检查，关键依赖不可用时返回503
+// @Summary 就绪检查
+// @Tags 运维
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.checker.Run(c.Request.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// Version 构建版本信息
+// @Summary 构建版本信息
+// @Tags 运维
+// @Produce json
+// @Success 200 {object} buildinfo.Info
+// @Router /version [get]
+func (h *HealthHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, buildinfo.Get())
+}
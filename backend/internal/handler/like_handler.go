@@ -24,6 +24,7 @@ func NewLikeHandler(likeService *service.LikeService) *LikeHandler {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "文章ID"
+// @Param share_token query string false "不公开文章的分享令牌"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/articles/{id}/like [post]
 func (h *LikeHandler) ToggleArticleLike(c *gin.Context) {
@@ -36,7 +37,7 @@ func (h *LikeHandler) ToggleArticleLike(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDUint := userID.(uint64)
 
-	isLiked, err := h.likeService.ToggleLike(userIDUint, "article", id)
+	isLiked, err := h.likeService.WithContext(c.Request.Context()).ToggleLike(userIDUint, c.GetString("role"), "article", id, c.Query("share_token"))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -63,6 +64,7 @@ func (h *LikeHandler) ToggleArticleLike(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "评论ID"
+// @Param share_token query string false "不公开文章的分享令牌"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/comments/{id}/like [post]
 func (h *LikeHandler) ToggleCommentLike(c *gin.Context) {
@@ -75,7 +77,7 @@ func (h *LikeHandler) ToggleCommentLike(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userIDUint := userID.(uint64)
 
-	isLiked, err := h.likeService.ToggleLike(userIDUint, "comment", id)
+	isLiked, err := h.likeService.WithContext(c.Request.Context()).ToggleLike(userIDUint, c.GetString("role"), "comment", id, c.Query("share_token"))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"dbapp/internal/dto/request"
+	"dbapp/internal/errors"
+	"dbapp/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SeriesHandler struct {
+	seriesService *service.SeriesService
+}
+
+func NewSeriesHandler(seriesService *service.SeriesService) *SeriesHandler {
+	return &SeriesHandler{
+		seriesService: seriesService,
+	}
+}
+
+// GetSeriesList 获取系列列表
+// @Summary 获取系列列表
+// @Tags 系列
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param author_id query int false "作者ID"
+// @Success 200 {object} response.SeriesListResponse
+// @Router /api/v1/series [get]
+func (h *SeriesHandler) GetSeriesList(c *gin.Context) {
+	var req request.ListSeriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		return
+	}
+
+	result, err := h.seriesService.WithContext(c.Request.Context()).List(&req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// GetSeriesDetail 获取系列详情
+// @Summary 获取系列详情，文章按顺序排列，只包含当前用户可见的文章
+// @Tags 系列
+// @Produce json
+// @Param id path int true "系列ID"
+// @Success 200 {object} response.SeriesResponse
+// @Router /api/v1/series/{id} [get]
+func (h *SeriesHandler) GetSeriesDetail(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的系列ID"))
+		return
+	}
+
+	series, err := h.seriesService.WithContext(c.Request.Context()).
+		GetByID(id, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": series,
+	})
+}
+
+// CreateSeries 创建系列
+// @Summary 创建系列
+// @Tags 系列
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param series body request.CreateSeriesRequest true "系列信息"
+// @Success 201 {object} response.SeriesResponse
+// @Router /api/v1/series [post]
+func (h *SeriesHandler) CreateSeries(c *gin.Context) {
+	var req request.CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
+		return
+	}
+
+	series, err := h.seriesService.WithContext(c.Request.Context()).Create(&req, c.GetUint64("user_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"code":    201,
+		"message": "创建成功",
+		"data":    series,
+	})
+}
+
+// UpdateSeries 更新系列
+// @Summary 更新系列，系列作者和管理员可操作
+// @Tags 系列
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "系列ID"
+// @Param series body request.UpdateSeriesRequest true "系列信息"
+// @Success 200 {object} response.SeriesResponse
+// @Router /api/v1/series/{id} [put]
+func (h *SeriesHandler) UpdateSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的系列ID"))
+		return
+	}
+
+	var req request.UpdateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
+		return
+	}
+
+	series, err := h.seriesService.WithContext(c.Request.Context()).
+		Update(id, &req, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "更新成功",
+		"data":    series,
+	})
+}
+
+// DeleteSeries 删除系列
+// @Summary 删除系列，系列中的文章不受影响
+// @Tags 系列
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "系列ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/series/{id} [delete]
+func (h *SeriesHandler) DeleteSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的系列ID"))
+		return
+	}
+
+	if err := h.seriesService.WithContext(c.Request.Context()).
+		Delete(id, c.GetUint64("user_id"), c.GetString("role")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "删除成功",
+	})
+}
+
+// SetSeriesArticles 设置系列文章及顺序
+// @Summary 按给定顺序设置系列中的全部文章，未列出的文章从系列中移除
+// @Tags 系列
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "系列ID"
+// @Param articles body request.SetSeriesArticlesRequest true "按顺序排列的文章ID"
+// @Success 200 {object} response.SeriesResponse
+// @Router /api/v1/series/{id}/articles [put]
+func (h *SeriesHandler) SetSeriesArticles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的系列ID"))
+		return
+	}
+
+	var req request.SetSeriesArticlesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
+		return
+	}
+
+	series, err := h.seriesService.WithContext(c.Request.Context()).
+		SetArticles(id, req.ArticleIDs, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "更新成功",
+		"data":    series,
+	})
+}
+
+// AddSeriesArticle 向系列添加文章
+// @Summary 向系列添加文章，不指定位置时添加到末尾
+// @Tags 系列
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "系列ID"
+// @Param article body request.AddSeriesArticleRequest true "文章ID和插入位置"
+// @Success 200 {object} response.SeriesResponse
+// @Router /api/v1/series/{id}/articles [post]
+func (h *SeriesHandler) AddSeriesArticle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的系列ID"))
+		return
+	}
+
+	var req request.AddSeriesArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
+		return
+	}
+
+	series, err := h.seriesService.WithContext(c.Request.Context()).
+		AddArticle(id, req.ArticleID, req.Position, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "添加成功",
+		"data":    series,
+	})
+}
+
+// RemoveSeriesArticle 从系列中移除文章
+// @Summary 从系列中移除文章，文章本身不受影响
+// @Tags 系列
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "系列ID"
+// @Param article_id path int true "文章ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/series/{id}/articles/{article_id} [delete]
+func (h *SeriesHandler) RemoveSeriesArticle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的系列ID"))
+		return
+	}
+	articleID, err := strconv.ParseUint(c.Param("article_id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的文章ID"))
+		return
+	}
+
+	if err := h.seriesService.WithContext(c.Request.Context()).
+		RemoveArticle(id, articleID, c.GetUint64("user_id"), c.GetString("role")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "移除成功",
+	})
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"crypto/rand"
+	"dbapp/internal/config"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setSessionCookies 写入会话 Cookie 和 CSRF Cookie。
+// 会话 Cookie 为 HttpOnly，页面脚本无法读取；CSRF Cookie 需要由前端读取后放到 X-CSRF-Token 请求头中。
+func setSessionCookies(c *gin.Context, cfg config.SessionConfig, token string, maxAge int) string {
+	csrfToken := newCSRFToken()
+	http.SetCookie(c.Writer, sessionCookie(cfg, cfg.CookieName, token, maxAge, true))
+	http.SetCookie(c.Writer, sessionCookie(cfg, cfg.CSRFCookieName, csrfToken, maxAge, false))
+	return csrfToken
+}
+
+// setCSRFCookie 重新签发 CSRF Token，会话 Cookie 不变
+func setCSRFCookie(c *gin.Context, cfg config.SessionConfig) string {
+	csrfToken := newCSRFToken()
+	http.SetCookie(c.Writer, sessionCookie(cfg, cfg.CSRFCookieName, csrfToken, 0, false))
+	return csrfToken
+}
+
+func clearSessionCookies(c *gin.Context, cfg config.SessionConfig) {
+	http.SetCookie(c.Writer, sessionCookie(cfg, cfg.CookieName, "", -1, true))
+	http.SetCookie(c.Writer, sessionCookie(cfg, cfg.CSRFCookieName, "", -1, false))
+}
+
+func sessionCookie(cfg config.SessionConfig, name, value string, maxAge int, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: sameSiteMode(cfg.SameSite),
+	}
+}
+
+func sameSiteMode(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+func newCSRFToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
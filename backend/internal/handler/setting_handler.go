@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"dbapp/internal/errors"
+	"dbapp/internal/service"
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SettingHandler struct {
+	settingService *service.SettingService
+}
+
+func NewSettingHandler(settingService *service.SettingService) *SettingHandler {
+	return &SettingHandler{
+		settingService: settingService,
+	}
+}
+
+// GetPublicSettings 获取公开的站点设置
+// @Summary 获取公开的站点设置，供前端展示站点名称、公告等
+// @Tags 设置
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/settings [get]
+func (h *SettingHandler) GetPublicSettings(c *gin.Context) {
+	settings, err := h.settingService.WithContext(c.Request.Context()).Public()
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": settings,
+	})
+}
+
+// GetSettings 获取全部站点设置
+// @Summary 获取全部站点设置（管理员）
+// @Tags 设置
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/settings [get]
+func (h *SettingHandler) GetSettings(c *gin.Context) {
+	settings, err := h.settingService.WithContext(c.Request.Context()).All()
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": settings,
+	})
+}
+
+// UpdateSettings 修改站点设置
+// @Summary 修改站点设置（管理员），只修改请求中提交的键
+// @Tags 设置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param settings body map[string]interface{} true "要修改的设置"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/settings [put]
+func (h *SettingHandler) UpdateSettings(c *gin.Context) {
+	var req map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误: "+err.Error()))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	userIDUint := userID.(uint64)
+
+	settings, err := h.settingService.WithContext(c.Request.Context()).Update(req, userIDUint)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "保存成功",
+		"data":    settings,
+	})
+}
+
+// GetSettingHistory 获取站点设置修改记录
+// @Summary 获取站点设置修改记录（管理员）
+// @Tags 设置
+// @Produce json
+// @Security BearerAuth
+// @Param key query string false "设置键，为空时查询全部"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.SettingChangeListResponse
+// @Router /api/v1/admin/settings/history [get]
+func (h *SettingHandler) GetSettingHistory(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.settingService.WithContext(c.Request.Context()).History(c.Query("key"), page, pageSize)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
@@ -36,7 +36,7 @@ func (h *TagHandler) GetTagList(c *gin.Context) {
 		return
 	}
 
-	tags, err := h.tagService.List(&req)
+	tags, err := h.tagService.WithContext(c.Request.Context()).List(&req)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -63,7 +63,7 @@ func (h *TagHandler) GetTagDetail(c *gin.Context) {
 		return
 	}
 
-	tag, err := h.tagService.GetByID(id)
+	tag, err := h.tagService.WithContext(c.Request.Context()).GetByID(id)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -90,7 +90,7 @@ func (h *TagHandler) GetTagBySlug(c *gin.Context) {
 		return
 	}
 
-	tag, err := h.tagService.GetBySlug(slug)
+	tag, err := h.tagService.WithContext(c.Request.Context()).GetBySlug(slug)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -118,7 +118,7 @@ func (h *TagHandler) CreateTag(c *gin.Context) {
 		return
 	}
 
-	tag, err := h.tagService.Create(&req)
+	tag, err := h.tagService.WithContext(c.Request.Context()).Create(&req)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -154,7 +154,7 @@ func (h *TagHandler) UpdateTag(c *gin.Context) {
 		return
 	}
 
-	tag, err := h.tagService.Update(id, &req)
+	tag, err := h.tagService.WithContext(c.Request.Context()).Update(id, &req)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -183,7 +183,7 @@ func (h *TagHandler) DeleteTag(c *gin.Context) {
 		return
 	}
 
-	if err := h.tagService.Delete(id); err != nil {
+	if err := h.tagService.WithContext(c.Request.Context()).Delete(id); err != nil {
 		errors.HandleError(c, err)
 		return
 	}
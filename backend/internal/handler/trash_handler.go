@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"dbapp/internal/dto/request"
+	"dbapp/internal/errors"
+	"dbapp/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	trashService *service.TrashService
+}
+
+func NewTrashHandler(trashService *service.TrashService) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// GetDeletedArticles 查询回收站中的文章
+// @Summary 查询回收站中的文章，管理员可查看所有人的文章
+// @Tags 回收站
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.DeletedArticleListResponse
+// @Router /api/v1/trash/articles [get]
+func (h *TrashHandler) GetDeletedArticles(c *gin.Context) {
+	var req request.ListTrashRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		return
+	}
+
+	result, err := h.trashService.WithContext(c.Request.Context()).
+		ListArticles(&req, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// RestoreArticle 恢复文章
+// @Summary 恢复回收站中的文章，与文章一起删除的评论和点赞同时恢复
+// @Tags 回收站
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/trash/articles/{id}/restore [post]
+func (h *TrashHandler) RestoreArticle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的文章ID"))
+		return
+	}
+
+	if err := h.trashService.WithContext(c.Request.Context()).
+		RestoreArticle(id, c.GetUint64("user_id"), c.GetString("role")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "恢复成功",
+	})
+}
+
+// GetDeletedComments 查询回收站中的评论
+// @Summary 查询回收站中的评论，管理员可查看所有人的评论
+// @Tags 回收站
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.DeletedCommentListResponse
+// @Router /api/v1/trash/comments [get]
+func (h *TrashHandler) GetDeletedComments(c *gin.Context) {
+	var req request.ListTrashRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		return
+	}
+
+	result, err := h.trashService.WithContext(c.Request.Context()).
+		ListComments(&req, c.GetUint64("user_id"), c.GetString("role"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// RestoreComment 恢复评论
+// @Summary 恢复回收站中的评论及其点赞
+// @Tags 回收站
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "评论ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/trash/comments/{id}/restore [post]
+func (h *TrashHandler) RestoreComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的评论ID"))
+		return
+	}
+
+	if err := h.trashService.WithContext(c.Request.Context()).
+		RestoreComment(id, c.GetUint64("user_id"), c.GetString("role")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "恢复成功",
+	})
+}
+
+// DeleteUser 删除用户
+// @Summary 删除用户，用户的文章及文章的评论和点赞一起移入回收站（仅管理员）
+// @Tags 回收站
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/admin/users/{id} [delete]
+func (h *TrashHandler) DeleteUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的用户ID"))
+		return
+	}
+
+	if err := h.trashService.WithContext(c.Request.Context()).
+		DeleteUser(id, c.GetUint64("user_id")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "删除成功",
+	})
+}
+
+// GetDeletedUsers 查询回收站中的用户
+// @Summary 查询回收站中的用户（仅管理员）
+// @Tags 回收站
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.DeletedUserListResponse
+// @Router /api/v1/admin/trash/users [get]
+func (h *TrashHandler) GetDeletedUsers(c *gin.Context) {
+	var req request.ListTrashRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("参数错误"))
+		return
+	}
+
+	result, err := h.trashService.WithContext(c.Request.Context()).ListUsers(&req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// RestoreUser 恢复用户
+// @Summary 恢复回收站中的用户，与用户一起删除的文章同时恢复（仅管理员）
+// @Tags 回收站
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/admin/trash/users/{id}/restore [post]
+func (h *TrashHandler) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		errors.HandleError(c, errors.NewBadRequestError("无效的用户ID"))
+		return
+	}
+
+	if err := h.trashService.WithContext(c.Request.Context()).RestoreUser(id); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    200,
+		"message": "恢复成功",
+	})
+}
+
+// Purge 立即清理回收站
+// @Summary 立即彻底删除超过保留时间的文章、评论和点赞，返回各类记录删除的数量（仅管理员）
+// @Tags 回收站
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]int64
+// @Router /api/v1/admin/trash/purge [post]
+func (h *TrashHandler) Purge(c *gin.Context) {
+	purged, err := h.trashService.WithContext(c.Request.Context()).Purge()
+	if err != nil {
+		errors.HandleError(c, errors.NewInternalError("清理回收站失败").WithCause(err))
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 200,
+		"data": purged,
+	})
+}
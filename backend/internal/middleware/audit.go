@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"dbapp/pkg/audit"
+	"dbapp/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditMiddleware 在请求 context 中放入客户端IP和请求ID，供审计日志记录操作者。
+// 用户ID在认证通过后由 AuthMiddleware 补充，因此需放在 AuthMiddleware 之前。
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := audit.Actor{
+			IP:        c.ClientIP(),
+			RequestID: c.GetString(logger.RequestIDKey),
+		}
+		c.Request = c.Request.WithContext(audit.NewContext(c.Request.Context(), actor))
+		c.Next()
+	}
+}
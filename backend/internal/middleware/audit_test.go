@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"dbapp/internal/config"
+	"dbapp/pkg/audit"
+	"dbapp/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	config.GlobalConfig = &config.Config{
+		JWT: config.JWTConfig{
+			Secret:    "test-secret",
+			ExpiresIn: 3600,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), AuditMiddleware())
+
+	var actor audit.Actor
+	capture := func(c *gin.Context) {
+		actor = audit.FromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	}
+	router.POST("/public", capture)
+	router.POST("/private", AuthMiddleware(), capture)
+
+	req, _ := http.NewRequest("POST", "/public", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set(RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, audit.Actor{IP: "10.0.0.1", RequestID: "req-1"}, actor)
+
+	token, _ := utils.GenerateJWT(7, "testuser", "user")
+	req, _ = http.NewRequest("POST", "/private", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, uint64(7), actor.UserID)
+	assert.Equal(t, "10.0.0.1", actor.IP)
+}
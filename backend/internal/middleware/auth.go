@@ -1,32 +1,38 @@
 package middleware
 
 import (
+	"crypto/subtle"
+	"dbapp/internal/config"
 	"dbapp/internal/errors"
+	"dbapp/internal/model"
+	"dbapp/pkg/audit"
 	"dbapp/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"strings"
 )
 
+// CSRFHeader 使用 Cookie 会话时提交 CSRF Token 的请求头
+const CSRFHeader = "X-CSRF-Token"
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			errors.HandleError(c, errors.NewUnauthorizedError("未提供认证信息"))
+		token, fromCookie, err := requestToken(c)
+		if err != nil {
+			errors.HandleError(c, err)
 			c.Abort()
 			return
 		}
 
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			errors.HandleError(c, errors.NewUnauthorizedError("认证格式错误"))
+		claims, err := utils.ParseJWT(token)
+		if err != nil {
+			errors.HandleError(c, errors.NewUnauthorizedError("Token无效或已过期"))
 			c.Abort()
 			return
 		}
 
-		token := parts[1]
-		claims, err := utils.ParseJWT(token)
-		if err != nil {
-			errors.HandleError(c, errors.NewUnauthorizedError("Token无效或已过期"))
+		// Cookie 由浏览器自动携带，写操作需要校验 CSRF Token；Bearer Token 不受 CSRF 影响
+		if fromCookie && !safeMethod(c.Request.Method) && !validCSRF(c) {
+			errors.HandleError(c, errors.NewForbiddenError("CSRF Token无效"))
 			c.Abort()
 			return
 		}
@@ -34,8 +40,72 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		c.Request = c.Request.WithContext(audit.WithUser(c.Request.Context(), claims.UserID))
+
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware 带有有效认证信息时设置当前用户，否则按未登录用户继续处理，用于公开的读接口。
+// 只用于安全方法，不校验 CSRF Token。
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, _, err := requestToken(c)
+		if err == nil {
+			if claims, err := utils.ParseJWT(token); err == nil {
+				c.Set("user_id", claims.UserID)
+				c.Set("username", claims.Username)
+				c.Set("role", claims.Role)
+				c.Request = c.Request.WithContext(audit.WithUser(c.Request.Context(), claims.UserID))
+			}
+		}
+		c.Next()
+	}
+}
 
+// RequireRole 要求当前用户具有指定角色，需放在 AuthMiddleware 之后
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !model.HasRole(c.GetString("role"), role) {
+			errors.HandleError(c, errors.NewForbiddenError("权限不足"))
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
 
+// requestToken 读取请求中的 Token，优先使用 Authorization 请求头，未提供时读取会话 Cookie
+func requestToken(c *gin.Context) (token string, fromCookie bool, err error) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		session := config.GetConfig().Security.Session
+		if session.Enabled {
+			if cookie, err := c.Cookie(session.CookieName); err == nil && cookie != "" {
+				return cookie, true, nil
+			}
+		}
+		return "", false, errors.NewUnauthorizedError("未提供认证信息")
+	}
+
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", false, errors.NewUnauthorizedError("认证格式错误")
+	}
+	return parts[1], false, nil
+}
+
+// validCSRF 双重提交校验：请求头中的 Token 必须与 CSRF Cookie 一致。
+// 其他站点的页面无法读取本站 Cookie，因此无法构造出正确的请求头。
+func validCSRF(c *gin.Context) bool {
+	cookie, err := c.Cookie(config.GetConfig().Security.Session.CSRFCookieName)
+	header := c.GetHeader(CSRFHeader)
+	if err != nil || cookie == "" || header == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+func safeMethod(method string) bool {
+	return method == "GET" || method == "HEAD" || method == "OPTIONS"
+}
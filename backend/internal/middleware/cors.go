@@ -1,22 +1,110 @@
 package middleware
 
 import (
+	"dbapp/internal/config"
+	"strconv"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
-func CORSMiddleware() gin.HandlerFunc {
+// CORSMiddleware 按配置处理跨域请求。
+// 只有 Origin 在白名单中的请求才会返回 CORS 响应头，Access-Control-Allow-Origin 回显请求的 Origin，
+// 因此响应总是带有 Vary: Origin，避免缓存把一个 Origin 的响应返回给另一个 Origin。
+func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
+	allowOrigin := newOriginMatcher(cfg.AllowedOrigins)
+	allowMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := ""
+	if cfg.MaxAge > 0 {
+		maxAge = strconv.Itoa(cfg.MaxAge)
+	}
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == "OPTIONS" && c.GetHeader("Access-Control-Request-Method") != ""
+		if origin == "" {
+			c.Next()
+			return
+		}
+		if !allowOrigin(origin) {
+			// 不在白名单的预检请求直接拒绝，普通请求照常处理，由浏览器拦截响应
+			if preflight {
+				c.AbortWithStatus(403)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		header.Set("Access-Control-Allow-Origin", origin)
+		if cfg.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
 
-		if c.Request.Method == "OPTIONS" {
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", allowMethods)
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+			if maxAge != "" {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
 			c.AbortWithStatus(204)
 			return
 		}
 
+		if exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", exposeHeaders)
+		}
 		c.Next()
 	}
 }
 
+// newOriginMatcher 返回判断 Origin 是否允许的函数。
+// 支持精确匹配、* 匹配任意 Origin，以及 https://*.example.com 匹配任意层级的子域名（不含 example.com 本身）。
+func newOriginMatcher(patterns []string) func(origin string) bool {
+	exact := make(map[string]bool)
+	type wildcard struct{ prefix, suffix string }
+	var wildcards []wildcard
+	any := false
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimRight(strings.TrimSpace(pattern), "/"))
+		switch {
+		case pattern == "*":
+			any = true
+		case strings.Contains(pattern, "://*."):
+			i := strings.Index(pattern, "*.")
+			wildcards = append(wildcards, wildcard{prefix: pattern[:i], suffix: pattern[i+1:]})
+		case pattern != "":
+			exact[pattern] = true
+		}
+	}
+
+	return func(origin string) bool {
+		if any {
+			return true
+		}
+		origin = strings.ToLower(origin)
+		if exact[origin] {
+			return true
+		}
+		for _, w := range wildcards {
+			if len(origin) <= len(w.prefix)+len(w.suffix) ||
+				!strings.HasPrefix(origin, w.prefix) || !strings.HasSuffix(origin, w.suffix) {
+				continue
+			}
+			// 子域名部分不能包含路径、端口或用户信息
+			sub := origin[len(w.prefix) : len(origin)-len(w.suffix)]
+			if !strings.ContainsAny(sub, "/:@") {
+				return true
+			}
+		}
+		return false
+	}
+}
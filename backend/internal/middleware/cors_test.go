@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"dbapp/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSRouter(cfg config.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORSMiddleware(cfg))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	return router
+}
+
+func doCORSRequest(router *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, "/test", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if method == "OPTIONS" {
+		req.Header.Set("Access-Control-Request-Method", "GET")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSMiddleware_EchoAllowedOrigin(t *testing.T) {
+	router := newCORSRouter(config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+	})
+
+	w := doCORSRequest(router, "GET", "https://app.example.com")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "X-Request-ID", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+
+	w = doCORSRequest(router, "GET", "https://evil.com")
+	assert.Equal(t, 200, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+}
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	router := newCORSRouter(config.CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		MaxAge:         600,
+	})
+
+	w := doCORSRequest(router, "OPTIONS", "https://app.example.com")
+	assert.Equal(t, 204, w.Code)
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = doCORSRequest(router, "OPTIONS", "https://evil.com")
+	assert.Equal(t, 403, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestOriginMatcher(t *testing.T) {
+	match := newOriginMatcher([]string{"https://*.example.com", "http://localhost:3000/"})
+
+	cases := map[string]bool{
+		"https://app.example.com":          true,
+		"https://a.b.example.com":          true,
+		"HTTPS://App.Example.com":          true,
+		"http://localhost:3000":            true,
+		"https://example.com":              false,
+		"http://app.example.com":           false,
+		"https://app.example.com:8443":     false,
+		"https://evil.com/.example.com":    false,
+		"https://app.example.com.evil.com": false,
+		"http://localhost:3001":            false,
+	}
+	for origin, want := range cases {
+		assert.Equal(t, want, match(origin), origin)
+	}
+
+	assert.True(t, newOriginMatcher([]string{"*"})("https://any.site"))
+	assert.False(t, newOriginMatcher(nil)("https://any.site"))
+}
@@ -24,7 +24,7 @@ func LoggerMiddleware() gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		logger.Info("HTTP请求",
+		logger.FromContext(c.Request.Context()).Info("HTTP请求",
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.Int("status", statusCode),
@@ -33,4 +33,3 @@ func LoggerMiddleware() gin.HandlerFunc {
 		)
 	}
 }
-
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"dbapp/pkg/metrics"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	httpRequestsTotal = metrics.Default.NewCounterVec(
+		"http_requests_total", "HTTP请求数", "method", "route", "status")
+	httpRequestDuration = metrics.Default.NewHistogramVec(
+		"http_request_duration_seconds", "HTTP请求耗时（秒）", nil, "method", "route")
+	httpRequestsInFlight int64
+)
+
+func init() {
+	metrics.Default.NewGaugeFunc("http_requests_in_flight", "正在处理的HTTP请求数", func() float64 {
+		return float64(atomic.LoadInt64(&httpRequestsInFlight))
+	})
+}
+
+// MetricsMiddleware 按路由统计请求数、耗时和状态码类别。
+// 路由使用注册时的模板（如 /api/v1/articles/:id），未匹配的请求统一记为 unmatched，避免标签数量无限增长。
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		atomic.AddInt64(&httpRequestsInFlight, 1)
+		defer atomic.AddInt64(&httpRequestsInFlight, -1)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		status := strconv.Itoa(c.Writer.Status()/100) + "xx"
+
+		httpRequestsTotal.WithLabelValues(method, route, status).Inc()
+		httpRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
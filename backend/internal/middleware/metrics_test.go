@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"dbapp/pkg/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MetricsMiddleware())
+	router.GET("/api/v1/articles/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/api/v1/articles/1", "/api/v1/articles/2", "/not-found"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	var b strings.Builder
+	metrics.Default.WriteText(&b)
+	out := b.String()
+
+	// 路由使用模板，不同ID记入同一个标签
+	assert.Contains(t, out, `http_requests_total{method="GET",route="/api/v1/articles/:id",status="4xx"} 2`)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="unmatched",status="4xx"} 1`)
+	assert.Contains(t, out, `http_request_duration_seconds_count{method="GET",route="/api/v1/articles/:id"} 2`)
+	assert.Contains(t, out, "http_requests_in_flight 0")
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"dbapp/internal/errors"
+	"dbapp/pkg/logger"
+	"dbapp/pkg/metrics"
+	"dbapp/pkg/ratelimit"
+	"dbapp/pkg/utils"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var rateLimitedTotal = metrics.Default.NewCounterVec(
+	"http_rate_limited_total", "被限流拒绝的请求数", "rule")
+
+// RateLimitMiddleware 按令牌桶规则限流，已登录用户按用户ID计数，未登录按IP计数。
+// name 区分不同的规则，同一用户在不同规则下分别计数。rule 在每次请求时调用，配置重新加载后立即生效。
+// limiter 为 nil 或规则无效时不限流；限流器出错时放行，避免Redis故障导致接口不可用。
+func RateLimitMiddleware(limiter ratelimit.Limiter, name string, rule func() ratelimit.Rule) gin.HandlerFunc {
+	if limiter == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		current := rule()
+		if !current.Valid() {
+			c.Next()
+			return
+		}
+		result, err := limiter.Allow(name+":"+clientKey(c), current)
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Warn("限流检查失败，放行请求",
+				zap.String("rule", name), zap.String("error", err.Error()))
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			rateLimitedTotal.WithLabelValues(name).Inc()
+			errors.HandleError(c, errors.NewTooManyRequestsError("请求过于频繁，请稍后再试").WithRetryAfter(result.RetryAfter))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// clientKey 返回限流计数的主体。全局限流在认证中间件之前执行，此时从 Token 中解析用户，
+// Token 无效时按IP计数，由后续的认证中间件拒绝请求。
+func clientKey(c *gin.Context) string {
+	if userID, ok := c.Get("user_id"); ok {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	if token, _, err := requestToken(c); err == nil {
+		if claims, err := utils.ParseJWT(token); err == nil {
+			return fmt.Sprintf("user:%d", claims.UserID)
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
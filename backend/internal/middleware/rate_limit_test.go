@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"dbapp/internal/config"
+	"dbapp/pkg/ratelimit"
+	"dbapp/pkg/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRateLimitRouter(limiter ratelimit.Limiter, rule ratelimit.Rule) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimitMiddleware(limiter, "test", func() ratelimit.Rule { return rule }))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	return router
+}
+
+func doRateLimitRequest(router *gin.Engine, remoteAddr, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.RemoteAddr = remoteAddr
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddleware_RejectsWithRetryAfter(t *testing.T) {
+	router := newRateLimitRouter(ratelimit.NewMemoryLimiter(), ratelimit.Rule{Requests: 2, Period: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		w := doRateLimitRequest(router, "10.0.0.1:1234", "")
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	}
+
+	w := doRateLimitRequest(router, "10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	assert.Equal(t, float64(429), body["code"])
+	assert.Equal(t, float64(30), body["retry_after"])
+
+	// 其他IP不受影响
+	w = doRateLimitRequest(router, "10.0.0.2:1234", "")
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestRateLimitMiddleware_KeysByUser(t *testing.T) {
+	config.GlobalConfig = &config.Config{
+		JWT: config.JWTConfig{Secret: "test-secret", ExpiresIn: 3600},
+	}
+	router := newRateLimitRouter(ratelimit.NewMemoryLimiter(), ratelimit.Rule{Requests: 1, Period: time.Minute})
+	token, _ := utils.GenerateJWT(7, "testuser", "user")
+
+	// 同一用户换IP仍然共享计数
+	assert.Equal(t, 200, doRateLimitRequest(router, "10.0.0.1:1234", token).Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRateLimitRequest(router, "10.0.0.2:1234", token).Code)
+
+	// 未登录请求按IP单独计数
+	assert.Equal(t, 200, doRateLimitRequest(router, "10.0.0.1:1234", "").Code)
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(string, ratelimit.Rule) (ratelimit.Result, error) {
+	return ratelimit.Result{}, assert.AnError
+}
+
+func TestRateLimitMiddleware_FailOpen(t *testing.T) {
+	setupTestLogger(t)
+	router := newRateLimitRouter(failingLimiter{}, ratelimit.Rule{Requests: 1, Period: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, 200, doRateLimitRequest(router, "10.0.0.1:1234", "").Code)
+	}
+}
+
+func TestRateLimitMiddleware_IgnoresSpoofedForwardedFor(t *testing.T) {
+	router := newRateLimitRouter(ratelimit.NewMemoryLimiter(), ratelimit.Rule{Requests: 1, Period: time.Minute})
+	// 与 server.trusted_proxies 一致，只信任 Nginx 所在的地址
+	assert.NoError(t, router.SetTrustedProxies([]string{"10.0.0.100"}))
+
+	request := func(remoteAddr, forwardedFor string) int {
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 直连的客户端每次换一个伪造的 X-Forwarded-For，仍按连接地址计数
+	assert.Equal(t, 200, request("203.0.113.5:1234", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, request("203.0.113.5:1234", "198.51.100.2"))
+
+	// 经可信代理转发时按代理给出的客户端地址计数
+	assert.Equal(t, 200, request("10.0.0.100:1234", "198.51.100.3"))
+	assert.Equal(t, http.StatusTooManyRequests, request("10.0.0.100:1234", "198.51.100.3"))
+}
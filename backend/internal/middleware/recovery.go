@@ -4,24 +4,29 @@ import (
 	"dbapp/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"runtime/debug"
 )
 
 func RecoveryMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.Error("Panic recovered",
+				logger.FromContext(c.Request.Context()).Error("Panic recovered",
 					zap.Any("error", err),
+					zap.String("method", c.Request.Method),
 					zap.String("path", c.Request.URL.Path),
+					zap.String("stack", string(debug.Stack())),
 				)
-				c.JSON(500, gin.H{
+				body := gin.H{
 					"code":    500,
 					"message": "服务器内部错误",
-				})
-				c.Abort()
+				}
+				if requestID := c.GetString(logger.RequestIDKey); requestID != "" {
+					body["request_id"] = requestID
+				}
+				c.AbortWithStatusJSON(500, body)
 			}
 		}()
 		c.Next()
 	}
 }
-
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"dbapp/pkg/logger"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader 请求ID的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID 只接受上游传入的合法请求ID，避免日志注入和超长字段
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestIDMiddleware 为每个请求分配ID并写入响应头。
+// 上游（如Nginx）已传入 X-Request-ID 时沿用，便于跨服务关联日志。
+// 同时在请求 context 中放入带 request_id 字段的 logger，通过 logger.FromContext 获取。
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set(logger.RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		reqLogger := logger.FromContext(c.Request.Context()).With(zap.String(logger.RequestIDKey, requestID))
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), reqLogger))
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bytes"
+	"dbapp/internal/errors"
+	"dbapp/pkg/logger"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// setupTestLogger 将日志写入内存，返回日志缓冲区
+func setupTestLogger(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	old := logger.Log
+	logger.Log = zap.New(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(&buf),
+		zapcore.DebugLevel,
+	))
+	t.Cleanup(func() { logger.Log = old })
+	return &buf
+}
+
+func TestRequestIDMiddleware_Generate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(logger.RequestIDKey))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+	requestID := w.Header().Get(RequestIDHeader)
+	assert.Len(t, requestID, 32)
+	assert.Equal(t, requestID, w.Body.String())
+}
+
+func TestRequestIDMiddleware_Propagate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "upstream-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "upstream-123", w.Header().Get(RequestIDHeader))
+
+	// 非法的请求ID不沿用
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "bad\nid")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.NotEqual(t, "bad\nid", w.Header().Get(RequestIDHeader))
+	assert.Len(t, w.Header().Get(RequestIDHeader), 32)
+}
+
+func TestHandleError_IncludesRequestID(t *testing.T) {
+	buf := setupTestLogger(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/test", func(c *gin.Context) {
+		errors.HandleError(c, errors.NewInternalError("查询失败").WithCause(assert.AnError))
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "req-1", body["request_id"])
+	assert.Equal(t, "查询失败", body["message"])
+
+	// 底层错误只写日志，日志中带请求ID
+	assert.NotContains(t, w.Body.String(), assert.AnError.Error())
+	assert.Contains(t, buf.String(), `"request_id":"req-1"`)
+	assert.Contains(t, buf.String(), assert.AnError.Error())
+}
+
+func TestRecoveryMiddleware_LogsStack(t *testing.T) {
+	buf := setupTestLogger(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.Use(RecoveryMiddleware())
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-2")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), `"request_id":"req-2"`)
+	assert.Contains(t, buf.String(), `"request_id":"req-2"`)
+	assert.Contains(t, buf.String(), "request_id_test.go")
+}
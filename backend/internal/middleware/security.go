@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"dbapp/internal/config"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHeadersMiddleware 输出安全响应头，配置为空的响应头不输出
+func SecurityHeadersMiddleware(cfg config.SecurityHeadersConfig) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(cfg.HSTSMaxAge) + "; includeSubDomains"
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		if cfg.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		if cfg.FrameOptions != "" {
+			header.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+		if cfg.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		}
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+// UploadsSecurityMiddleware 用于 /uploads 静态文件，用上传文件专用的 CSP 覆盖全局 CSP。
+// 上传的 SVG、HTML 等文件在浏览器中直接打开时会在本站域名下执行脚本，sandbox 可以阻止这种情况。
+func UploadsSecurityMiddleware(cfg config.SecurityHeadersConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		if cfg.UploadsContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", cfg.UploadsContentSecurityPolicy)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"dbapp/internal/config"
+	"dbapp/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	headers := config.SecurityHeadersConfig{
+		ContentSecurityPolicy:        "default-src 'none'",
+		UploadsContentSecurityPolicy: "default-src 'none'; sandbox",
+		FrameOptions:                 "DENY",
+		ReferrerPolicy:               "no-referrer",
+		HSTSMaxAge:                   3600,
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SecurityHeadersMiddleware(headers))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	router.GET("/uploads/a.svg", UploadsSecurityMiddleware(headers), func(c *gin.Context) {
+		c.String(200, "<svg/>")
+	})
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "default-src 'none'", w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	assert.Equal(t, "max-age=3600; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+
+	req, _ = http.NewRequest("GET", "/uploads/a.svg", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "default-src 'none'; sandbox", w.Header().Get("Content-Security-Policy"))
+
+	// 未配置的响应头不输出
+	router = gin.New()
+	router.Use(SecurityHeadersMiddleware(config.SecurityHeadersConfig{}))
+	router.GET("/test", func(c *gin.Context) {})
+	req, _ = http.NewRequest("GET", "/test", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+}
+
+func TestAuthMiddleware_CookieSessionCSRF(t *testing.T) {
+	config.GlobalConfig = &config.Config{
+		JWT: config.JWTConfig{Secret: "test-secret", ExpiresIn: 3600},
+		Security: config.SecurityConfig{Session: config.SessionConfig{
+			Enabled:        true,
+			CookieName:     "dbapp_session",
+			CSRFCookieName: "dbapp_csrf",
+		}},
+	}
+	defer func() { config.GlobalConfig.Security = config.SecurityConfig{} }()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AuthMiddleware())
+	handler := func(c *gin.Context) {
+		c.JSON(200, gin.H{"user_id": c.GetUint64("user_id")})
+	}
+	router.GET("/test", handler)
+	router.POST("/test", handler)
+
+	token, _ := utils.GenerateJWT(1, "testuser", "user")
+	do := func(method, csrfCookie, csrfHeader string) int {
+		req, _ := http.NewRequest(method, "/test", nil)
+		req.AddCookie(&http.Cookie{Name: "dbapp_session", Value: token})
+		if csrfCookie != "" {
+			req.AddCookie(&http.Cookie{Name: "dbapp_csrf", Value: csrfCookie})
+		}
+		if csrfHeader != "" {
+			req.Header.Set(CSRFHeader, csrfHeader)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 读操作不需要 CSRF Token
+	assert.Equal(t, 200, do("GET", "", ""))
+	// 写操作需要请求头与 Cookie 一致
+	assert.Equal(t, 403, do("POST", "", ""))
+	assert.Equal(t, 403, do("POST", "abc", ""))
+	assert.Equal(t, 403, do("POST", "abc", "xyz"))
+	assert.Equal(t, 200, do("POST", "abc", "abc"))
+
+	// Bearer Token 不需要 CSRF Token
+	req, _ := http.NewRequest("POST", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+}
@@ -22,6 +22,8 @@ type Article struct {
 	EditCount     int            `gorm:"default:0" json:"edit_count"`
 	IsFeatured    bool           `gorm:"default:false" json:"is_featured"`
 	IsLocked      bool           `gorm:"default:false" json:"is_locked"`
+	Visibility    string         `gorm:"size:20;not null;default:'public'" json:"visibility"`
+	ShareToken    string         `gorm:"size:64" json:"-"` // 不公开（unlisted）文章的分享链接令牌
 	PublishedAt   *time.Time     `json:"published_at"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
@@ -34,6 +36,14 @@ type Article struct {
 	Tags       []Tag      `gorm:"many2many:article_tags;" json:"tags"`
 }
 
+// 文章可见范围，草稿等未发布的文章无论可见范围如何都只有作者和编辑可见
+const (
+	VisibilityPublic   = "public"   // 所有人可见
+	VisibilityMembers  = "members"  // 登录用户可见
+	VisibilityPrivate  = "private"  // 仅作者和编辑可见
+	VisibilityUnlisted = "unlisted" // 不出现在列表中，持有分享链接的人可见
+)
+
 func (Article) TableName() string {
 	return "articles"
 }
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// AuditLog 写操作的审计日志，只追加不修改
+type AuditLog struct {
+	ID         uint64    `gorm:"primaryKey" json:"id"`
+	UserID     *uint64   `gorm:"index" json:"user_id"` // 未登录用户（如注册）或系统操作时为空
+	IP         string    `gorm:"size:45" json:"ip"`
+	Action     string    `gorm:"size:50;not null;index" json:"action"`
+	TargetType string    `gorm:"size:50;not null;index:idx_audit_logs_target" json:"target_type"`
+	TargetID   uint64    `gorm:"index:idx_audit_logs_target" json:"target_id"`
+	Changes    string    `gorm:"type:text" json:"changes"` // 字段差异的JSON，格式为 {"字段": {"old": 旧值, "new": 新值}}
+	RequestID  string    `gorm:"size:64" json:"request_id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+
+	// 关联
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+)
+
+// ReadingList 阅读列表，用户把收藏的文章整理到命名的列表中，公开的列表其他用户也可以查看
+type ReadingList struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	UserID      uint64    `gorm:"not null;uniqueIndex:idx_reading_lists_user_name,priority:1" json:"user_id"`
+	Name        string    `gorm:"size:100;not null;uniqueIndex:idx_reading_lists_user_name,priority:2" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	IsPublic    bool      `gorm:"default:false" json:"is_public"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联
+	User User `gorm:"foreignKey:UserID" json:"user"`
+}
+
+func (ReadingList) TableName() string {
+	return "reading_lists"
+}
+
+// Bookmark 收藏记录，同一用户对同一文章只能收藏一次（idx_bookmarks_user_article 唯一索引）。
+// 收藏最多放入一个阅读列表，Position 为在列表中的顺序，未放入列表时为 0
+type Bookmark struct {
+	ID            uint64    `gorm:"primaryKey" json:"id"`
+	UserID        uint64    `gorm:"not null;uniqueIndex:idx_bookmarks_user_article,priority:1" json:"user_id"`
+	ArticleID     uint64    `gorm:"not null;index;uniqueIndex:idx_bookmarks_user_article,priority:2" json:"article_id"`
+	ReadingListID *uint64   `gorm:"index:idx_bookmarks_list_position,priority:1" json:"reading_list_id"`
+	Position      int       `gorm:"not null;default:0;index:idx_bookmarks_list_position,priority:2" json:"position"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// 关联
+	Article Article `gorm:"foreignKey:ArticleID" json:"article"`
+}
+
+func (Bookmark) TableName() string {
+	return "bookmarks"
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// File 上传的文件记录，用于统计每个用户的存储用量和上传次数
+type File struct {
+	ID             uint64    `gorm:"primaryKey" json:"id"`
+	Filename       string    `gorm:"size:255;not null" json:"filename"`        // 原始文件名
+	StoredFilename string    `gorm:"size:255;not null" json:"stored_filename"` // 存储文件名
+	FilePath       string    `gorm:"size:500;not null" json:"-"`
+	FileURL        string    `gorm:"size:500;not null" json:"file_url"`
+	FileSize       int64     `gorm:"not null" json:"file_size"`
+	MimeType       string    `gorm:"size:100;not null" json:"mime_type"`
+	FileType       string    `gorm:"size:20;not null;index" json:"file_type"` // image, document, other
+	UploaderID     uint64    `gorm:"not null;index" json:"uploader_id"`
+	UsageCount     int       `gorm:"default:0" json:"usage_count"`
+	IsPublic       bool      `gorm:"default:true" json:"is_public"`
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+
+	// 关联
+	Uploader User `gorm:"foreignKey:UploaderID" json:"-"`
+}
+
+func (File) TableName() string {
+	return "files"
+}
@@ -2,14 +2,18 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// Like 点赞记录，同一用户对同一目标只能有一条（idx_likes_user_target 唯一索引）
 type Like struct {
-	ID         uint64    `gorm:"primaryKey" json:"id"`
-	UserID     uint64    `gorm:"not null;index" json:"user_id"`
-	TargetType string    `gorm:"size:20;not null;index" json:"target_type"` // article, comment
-	TargetID   uint64    `gorm:"not null;index" json:"target_id"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         uint64         `gorm:"primaryKey" json:"id"`
+	UserID     uint64         `gorm:"not null;uniqueIndex:idx_likes_user_target,priority:1" json:"user_id"`
+	TargetType string         `gorm:"size:20;not null;index;uniqueIndex:idx_likes_user_target,priority:2" json:"target_type"` // article, comment
+	TargetID   uint64         `gorm:"not null;index;uniqueIndex:idx_likes_user_target,priority:3" json:"target_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"` // 点赞的文章或评论被删除时一起软删除
 
 	// 关联
 	User User `gorm:"foreignKey:UserID" json:"user"`
@@ -18,4 +22,3 @@ type Like struct {
 func (Like) TableName() string {
 	return "likes"
 }
-
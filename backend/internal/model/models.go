@@ -0,0 +1,23 @@
+package model
+
+// AllModels 返回所有需要建表的模型。
+// 生产环境的表结构由 migrations 目录下的SQL迁移维护，这里用于测试中的 AutoMigrate。
+func AllModels() []interface{} {
+	return []interface{}{
+		&User{},
+		&Article{},
+		&Category{},
+		&Tag{},
+		&Comment{},
+		&Like{},
+		&ArticleImage{},
+		&File{},
+		&Setting{},
+		&SettingChange{},
+		&AuditLog{},
+		&Series{},
+		&SeriesArticle{},
+		&ReadingList{},
+		&Bookmark{},
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+)
+
+// Series 文章系列，作者把多篇文章按顺序组织成一个专题
+type Series struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	Title       string    `gorm:"size:200;not null" json:"title"`
+	Description string    `gorm:"type:text" json:"description"`
+	AuthorID    uint64    `gorm:"not null;index" json:"author_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联
+	Author User `gorm:"foreignKey:AuthorID" json:"author"`
+}
+
+func (Series) TableName() string {
+	return "series"
+}
+
+// SeriesArticle 系列中的文章及其顺序，一篇文章最多属于一个系列
+type SeriesArticle struct {
+	SeriesID  uint64 `gorm:"primaryKey;index:idx_series_articles_position,priority:1" json:"series_id"`
+	ArticleID uint64 `gorm:"primaryKey;uniqueIndex:idx_series_articles_article_id" json:"article_id"`
+	Position  int    `gorm:"not null;index:idx_series_articles_position,priority:2" json:"position"`
+}
+
+func (SeriesArticle) TableName() string {
+	return "series_articles"
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+// Setting 管理员可修改的站点设置，值以JSON保存，类型和默认值由 SettingService 中的定义决定
+type Setting struct {
+	Key       string    `gorm:"primaryKey;size:100" json:"key"`
+	Value     string    `gorm:"type:text;not null" json:"value"`
+	UpdatedBy *uint64   `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Setting) TableName() string {
+	return "settings"
+}
+
+// SettingChange 站点设置的修改记录
+type SettingChange struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	Key       string    `gorm:"size:100;not null;index" json:"key"`
+	OldValue  *string   `gorm:"type:text" json:"old_value"` // 修改前未保存过时为空，表示使用默认值
+	NewValue  string    `gorm:"type:text;not null" json:"new_value"`
+	ChangedBy uint64    `gorm:"not null" json:"changed_by"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	// 关联
+	User User `gorm:"foreignKey:ChangedBy" json:"-"`
+}
+
+func (SettingChange) TableName() string {
+	return "setting_changes"
+}
@@ -5,6 +5,28 @@ import (
 	"gorm.io/gorm"
 )
 
+// 用户角色，权限从低到高
+const (
+	RoleUser     = "user"
+	RoleEditor   = "editor"
+	RoleAdmin    = "admin"
+	RoleSysadmin = "sysadmin"
+)
+
+var roleRanks = map[string]int{
+	RoleUser:     1,
+	RoleEditor:   2,
+	RoleAdmin:    3,
+	RoleSysadmin: 4,
+}
+
+// HasRole 判断 role 是否具有 required 角色的权限，高级角色拥有低级角色的全部权限，未知角色没有任何权限。
+// 所有按角色授权的检查都应使用该函数，保证同一用户在各接口上的权限一致
+func HasRole(role, required string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[required]
+}
+
 type User struct {
 	ID            uint64         `gorm:"primaryKey" json:"id"`
 	Username      string         `gorm:"uniqueIndex;size:50;not null" json:"username"`
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"dbapp/internal/model"
 	"gorm.io/gorm"
 )
@@ -15,6 +16,14 @@ func NewArticleImageRepository(db *gorm.DB) *ArticleImageRepository {
 	}
 }
 
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *ArticleImageRepository) WithContext(ctx context.Context) *ArticleImageRepository {
+	if r == nil {
+		return nil
+	}
+	return NewArticleImageRepository(r.db.WithContext(ctx))
+}
+
 // Create 创建文章图片记录
 func (r *ArticleImageRepository) Create(image *model.ArticleImage) error {
 	return r.db.Create(image).Error
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"context"
 	"dbapp/internal/model"
+	"errors"
 	"gorm.io/gorm"
+	"time"
 )
 
 type ArticleRepository struct {
@@ -15,6 +18,14 @@ func NewArticleRepository(db *gorm.DB) *ArticleRepository {
 	}
 }
 
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *ArticleRepository) WithContext(ctx context.Context) *ArticleRepository {
+	if r == nil {
+		return nil
+	}
+	return NewArticleRepository(r.db.WithContext(ctx))
+}
+
 func (r *ArticleRepository) Create(article *model.Article) error {
 	return r.db.Create(article).Error
 }
@@ -27,6 +38,14 @@ func (r *ArticleRepository) GetByID(id uint64) (*model.Article, error) {
 	return &article, err
 }
 
+// GetVisibility 只查询判断可见性所需的字段，不加载关联数据，用于评论、点赞前的权限检查
+func (r *ArticleRepository) GetVisibility(id uint64) (*model.Article, error) {
+	var article model.Article
+	err := r.db.Select("id", "author_id", "status", "visibility", "share_token").
+		First(&article, id).Error
+	return &article, err
+}
+
 func (r *ArticleRepository) GetBySlug(slug string) (*model.Article, error) {
 	var article model.Article
 	err := r.db.Where("slug = ?", slug).
@@ -36,53 +55,255 @@ func (r *ArticleRepository) GetBySlug(slug string) (*model.Article, error) {
 	return &article, err
 }
 
-func (r *ArticleRepository) List(page, pageSize int, conditions map[string]interface{}) ([]model.Article, int64, error) {
+// GetByIDs 批量查询未删除的文章，不加载关联数据，不存在的ID不在结果中
+func (r *ArticleRepository) GetByIDs(ids []uint64) ([]model.Article, error) {
 	var articles []model.Article
-	var total int64
+	if len(ids) == 0 {
+		return articles, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&articles).Error
+	return articles, err
+}
 
-	query := r.db.Model(&model.Article{})
+// ErrInvalidSort 排序字段不在 ArticleSortFields 中
+var ErrInvalidSort = errors.New("invalid sort field")
+
+// 多个分类或标签的匹配方式
+const (
+	MatchAny = "any" // 属于其中任意一个
+	MatchAll = "all" // 同时属于全部
+)
 
-	// 应用条件
-	if status, ok := conditions["status"]; ok && status != "" {
-		query = query.Where("status = ?", status)
+// ArticleSortFields 文章列表允许的排序字段，排序字段只能取这里的值，不能直接拼接请求参数
+var ArticleSortFields = map[string]keysetColumn{
+	"created_at":    {Column: "articles.created_at", IsTime: true},
+	"updated_at":    {Column: "articles.updated_at", IsTime: true},
+	"published_at":  {Column: "articles.published_at", IsTime: true, Nullable: true},
+	"view_count":    {Column: "articles.view_count"},
+	"like_count":    {Column: "articles.like_count"},
+	"comment_count": {Column: "articles.comment_count"},
+}
+
+// ArticleFilter 文章列表查询条件，零值字段不参与过滤
+type ArticleFilter struct {
+	Status   string
+	AuthorID uint64
+	Keyword  string
+
+	CategoryIDs   []uint64
+	CategoryMatch string // MatchAny（默认）或 MatchAll
+	// IncludeChildCategories 为 true 时，属于子孙分类的文章也视为属于该分类
+	IncludeChildCategories bool
+	TagIDs                 []uint64
+	TagMatch               string // MatchAny（默认）或 MatchAll
+
+	// 时间范围，From 包含，To 不包含
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	PublishedFrom *time.Time
+	PublishedTo   *time.Time
+
+	IsFeatured *bool
+	IsLocked   *bool
+
+	// Viewer 为 nil 时不按可见性过滤，仅用于后台任务等内部查询
+	Viewer *ArticleViewer
+
+	Sort string // ArticleSortFields 中的字段，为空时按 created_at
+	Desc bool
+}
+
+// ArticleViewer 查看文章列表的用户。
+// Privileged 为 true（编辑、管理员）时可以看到所有文章；否则只能看到自己的文章，
+// 以及其他人已发布且可见范围允许的文章，不公开（unlisted）的文章不出现在列表中。
+type ArticleViewer struct {
+	UserID     uint64 // 0 表示未登录
+	Privileged bool
+}
+
+func articleSortValue(article *model.Article, sort string) interface{} {
+	switch sort {
+	case "updated_at":
+		return article.UpdatedAt
+	case "view_count":
+		return article.ViewCount
+	case "like_count":
+		return article.LikeCount
+	case "comment_count":
+		return article.CommentCount
+	default:
+		return article.CreatedAt
 	}
-	if categoryID, ok := conditions["category_id"]; ok && categoryID.(uint64) > 0 {
-		query = query.Joins("JOIN article_categories ON articles.id = article_categories.article_id").
-			Where("article_categories.category_id = ?", categoryID)
+}
+
+// List 查询文章列表。按页码分页时返回总数；按游标分页时不统计总数，还有下一页时返回 next。
+// 排序字段不合法时返回 ErrInvalidSort，排序字段不支持游标分页或游标无效时返回 ErrInvalidCursor
+func (r *ArticleRepository) List(filter ArticleFilter, page PageQuery) (articles []model.Article, total int64, next *Cursor, err error) {
+	sort := filter.Sort
+	if sort == "" {
+		sort = "created_at"
 	}
-	if tagID, ok := conditions["tag_id"]; ok && tagID.(uint64) > 0 {
-		query = query.Joins("JOIN article_tags ON articles.id = article_tags.article_id").
-			Where("article_tags.tag_id = ?", tagID)
+	column, ok := ArticleSortFields[sort]
+	if !ok {
+		return nil, 0, nil, ErrInvalidSort
 	}
-	if authorID, ok := conditions["author_id"]; ok && authorID.(uint64) > 0 {
-		query = query.Where("author_id = ?", authorID)
+
+	query, err := r.filter(filter)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	withAssociations := func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Author").Preload("Categories").Preload("Tags")
 	}
-	if keyword, ok := conditions["keyword"]; ok && keyword != "" {
-		query = query.Where("title ILIKE ? OR content ILIKE ?", "%"+keyword.(string)+"%", "%"+keyword.(string)+"%")
+
+	if page.IsCursor() {
+		scope, err := keyset(page.Cursor, sort, column, filter.Desc, page.PageSize)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if err := query.Scopes(withAssociations, scope).Find(&articles).Error; err != nil {
+			return nil, 0, nil, err
+		}
+		if len(articles) > page.PageSize {
+			articles = articles[:page.PageSize]
+			last := &articles[len(articles)-1]
+			next = nextCursor(sort, filter.Desc, articleSortValue(last, sort), last.ID)
+		}
+		return articles, 0, next, nil
 	}
 
 	// 统计总数
 	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+		return nil, 0, nil, err
 	}
 
-	// 排序
-	sort := "created_at"
-	order := "DESC"
-	if s, ok := conditions["sort"]; ok && s != "" {
-		sort = s.(string)
-	}
-	if o, ok := conditions["order"]; ok && o != "" {
-		order = o.(string)
+	order := "ASC"
+	if filter.Desc {
+		order = "DESC"
 	}
 
-	// 分页查询
-	err := query.Preload("Author").Preload("Categories").Preload("Tags").
-		Scopes(r.Paginate(page, pageSize)).
-		Order(sort + " " + order).
+	// 分页查询，排序值相同时按ID排序，保证翻页结果稳定
+	err = query.Scopes(withAssociations, r.Paginate(page.Page, page.PageSize)).
+		Order(column.Column + " " + order).
+		Order("articles.id " + order).
 		Find(&articles).Error
 
-	return articles, total, err
+	return articles, total, nil, err
+}
+
+// filter 根据查询条件构造查询。分类和标签用子查询过滤，避免JOIN产生重复行
+func (r *ArticleRepository) filter(filter ArticleFilter) (*gorm.DB, error) {
+	query := r.db.Model(&model.Article{})
+
+	if filter.Status != "" {
+		query = query.Where("articles.status = ?", filter.Status)
+	}
+	if filter.AuthorID > 0 {
+		query = query.Where("articles.author_id = ?", filter.AuthorID)
+	}
+	if filter.Keyword != "" {
+		keyword := "%" + filter.Keyword + "%"
+		query = query.Where("(articles.title ILIKE ? OR articles.content ILIKE ?)", keyword, keyword)
+	}
+
+	if len(filter.CategoryIDs) > 0 {
+		// 每个请求的分类展开为一组ID（包含子孙分类时为整棵子树），文章需属于组内任一分类
+		groups := make([][]uint64, len(filter.CategoryIDs))
+		for i, id := range filter.CategoryIDs {
+			groups[i] = []uint64{id}
+			if filter.IncludeChildCategories {
+				ids, err := r.categorySubtree(id)
+				if err != nil {
+					return nil, err
+				}
+				groups[i] = ids
+			}
+		}
+		query = matchGroups(query, "article_categories", "category_id", groups, filter.CategoryMatch)
+	}
+	if len(filter.TagIDs) > 0 {
+		groups := make([][]uint64, len(filter.TagIDs))
+		for i, id := range filter.TagIDs {
+			groups[i] = []uint64{id}
+		}
+		query = matchGroups(query, "article_tags", "tag_id", groups, filter.TagMatch)
+	}
+
+	if filter.CreatedFrom != nil {
+		query = query.Where("articles.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("articles.created_at < ?", *filter.CreatedTo)
+	}
+	if filter.PublishedFrom != nil {
+		query = query.Where("articles.published_at >= ?", *filter.PublishedFrom)
+	}
+	if filter.PublishedTo != nil {
+		query = query.Where("articles.published_at < ?", *filter.PublishedTo)
+	}
+
+	if filter.IsFeatured != nil {
+		query = query.Where("articles.is_featured = ?", *filter.IsFeatured)
+	}
+	if filter.IsLocked != nil {
+		query = query.Where("articles.is_locked = ?", *filter.IsLocked)
+	}
+
+	return filter.Viewer.visible(query), nil
+}
+
+// visible 给包含 articles 表的查询加上可见性条件，viewer 为 nil 或有特权时不过滤
+func (v *ArticleViewer) visible(query *gorm.DB) *gorm.DB {
+	if v == nil || v.Privileged {
+		return query
+	}
+	if v.UserID > 0 {
+		return query.Where("(articles.author_id = ? OR (articles.status = ? AND articles.visibility IN ?))",
+			v.UserID, "published", []string{model.VisibilityPublic, model.VisibilityMembers})
+	}
+	return query.Where("articles.status = ? AND articles.visibility = ?", "published", model.VisibilityPublic)
+}
+
+// matchGroups 按关联表过滤文章：MatchAll 时文章需与每一组都有关联，否则与任意一组有关联即可
+func matchGroups(query *gorm.DB, table, column string, groups [][]uint64, match string) *gorm.DB {
+	subquery := "articles.id IN (SELECT article_id FROM " + table + " WHERE " + column + " IN ?)"
+	if match == MatchAll {
+		for _, ids := range groups {
+			query = query.Where(subquery, ids)
+		}
+		return query
+	}
+
+	var all []uint64
+	for _, ids := range groups {
+		all = append(all, ids...)
+	}
+	return query.Where(subquery, all)
+}
+
+// categorySubtree 返回分类及其所有子孙分类的ID
+func (r *ArticleRepository) categorySubtree(id uint64) ([]uint64, error) {
+	ids := []uint64{id}
+	seen := map[uint64]bool{id: true}
+	parents := []uint64{id}
+	// 逐层向下查找，seen 防止数据中存在环时死循环
+	for len(parents) > 0 {
+		var children []uint64
+		if err := r.db.Model(&model.Category{}).Where("parent_id IN ?", parents).
+			Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		parents = parents[:0]
+		for _, child := range children {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+				parents = append(parents, child)
+			}
+		}
+	}
+	return ids, nil
 }
 
 func (r *ArticleRepository) Update(article *model.Article) error {
@@ -93,11 +314,109 @@ func (r *ArticleRepository) Delete(id uint64) error {
 	return r.db.Delete(&model.Article{}, id).Error
 }
 
+// DeleteWithDependents 软删除文章，并在同一事务中软删除文章的评论以及文章和评论的点赞。
+// 级联删除的记录与文章使用相同的删除时间，Restore 据此只恢复与文章一起删除的记录
+func (r *ArticleRepository) DeleteWithDependents(id uint64) error {
+	now := deletionTime()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Article{}).Where("id = ?", id).UpdateColumn("deleted_at", now)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return deleteArticleDependents(tx, []uint64{id}, now)
+	})
+}
+
+// deleteArticleDependents 软删除文章的评论以及文章和评论的点赞，删除时间为 at
+func deleteArticleDependents(tx *gorm.DB, ids []uint64, at time.Time) error {
+	comments := tx.Model(&model.Comment{}).Select("id").Where("article_id IN ?", ids)
+	if err := tx.Model(&model.Like{}).
+		Where("(target_type = ? AND target_id IN ?) OR (target_type = ? AND target_id IN (?))", "article", ids, "comment", comments).
+		UpdateColumn("deleted_at", at).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.Comment{}).Where("article_id IN ?", ids).UpdateColumn("deleted_at", at).Error
+}
+
+// GetDeleted 查询已删除的文章
+func (r *ArticleRepository) GetDeleted(id uint64) (*model.Article, error) {
+	var article model.Article
+	err := r.db.Unscoped().Preload("Author").
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&article).Error
+	return &article, err
+}
+
+// ListDeleted 按删除时间倒序分页查询已删除的文章，authorID 为 0 时查询所有作者的文章
+func (r *ArticleRepository) ListDeleted(authorID uint64, page, pageSize int) ([]model.Article, int64, error) {
+	var articles []model.Article
+	var total int64
+
+	query := r.db.Unscoped().Model(&model.Article{}).Where("deleted_at IS NOT NULL")
+	if authorID > 0 {
+		query = query.Where("author_id = ?", authorID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Author").
+		Order("deleted_at DESC").
+		Scopes(r.Paginate(page, pageSize)).
+		Find(&articles).Error
+
+	return articles, total, err
+}
+
+// Restore 恢复已删除的文章，以及与文章一起删除的评论和点赞；文章已被恢复时返回 false
+func (r *ArticleRepository) Restore(article *model.Article) (bool, error) {
+	restored := false
+	deletedAt := article.DeletedAt.Time
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&model.Article{}).
+			Where("id = ? AND deleted_at = ?", article.ID, deletedAt).
+			UpdateColumn("deleted_at", nil)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		restored = true
+		return restoreArticleDependents(tx, []uint64{article.ID}, deletedAt)
+	})
+	return restored, err
+}
+
+// restoreArticleDependents 恢复删除时间为 at 的文章评论以及文章和评论的点赞，即与文章一起删除的记录
+func restoreArticleDependents(tx *gorm.DB, ids []uint64, at time.Time) error {
+	comments := tx.Unscoped().Model(&model.Comment{}).Select("id").Where("article_id IN ?", ids)
+	if err := tx.Unscoped().Model(&model.Like{}).
+		Where("deleted_at = ? AND ((target_type = ? AND target_id IN ?) OR (target_type = ? AND target_id IN (?)))",
+			at, "article", ids, "comment", comments).
+		UpdateColumn("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Model(&model.Comment{}).
+		Where("article_id IN ? AND deleted_at = ?", ids, at).
+		UpdateColumn("deleted_at", nil).Error
+}
+
 func (r *ArticleRepository) IncrementViewCount(id uint64) error {
 	return r.db.Model(&model.Article{}).Where("id = ?", id).
 		UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
 }
 
+// IncrementViewCounts 在一个事务中批量增加多篇文章的浏览数
+func (r *ArticleRepository) IncrementViewCounts(counts map[uint64]int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for id, n := range counts {
+			if err := tx.Model(&model.Article{}).Where("id = ?", id).
+				UpdateColumn("view_count", gorm.Expr("view_count + ?", n)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *ArticleRepository) IncrementLikeCount(id uint64) error {
 	return r.db.Model(&model.Article{}).Where("id = ?", id).
 		UpdateColumn("like_count", gorm.Expr("like_count + 1")).Error
@@ -105,7 +424,7 @@ func (r *ArticleRepository) IncrementLikeCount(id uint64) error {
 
 func (r *ArticleRepository) DecrementLikeCount(id uint64) error {
 	return r.db.Model(&model.Article{}).Where("id = ?", id).
-		UpdateColumn("like_count", gorm.Expr("GREATEST(like_count - 1, 0)")).Error
+		UpdateColumn("like_count", gorm.Expr("CASE WHEN like_count > 0 THEN like_count - 1 ELSE 0 END")).Error
 }
 
 func (r *ArticleRepository) IncrementCommentCount(id uint64) error {
@@ -115,7 +434,7 @@ func (r *ArticleRepository) IncrementCommentCount(id uint64) error {
 
 func (r *ArticleRepository) DecrementCommentCount(id uint64) error {
 	return r.db.Model(&model.Article{}).Where("id = ?", id).
-		UpdateColumn("comment_count", gorm.Expr("GREATEST(comment_count - 1, 0)")).Error
+		UpdateColumn("comment_count", gorm.Expr("CASE WHEN comment_count > 0 THEN comment_count - 1 ELSE 0 END")).Error
 }
 
 // UpdateCategories 更新文章的分类关联
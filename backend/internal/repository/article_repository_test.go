@@ -3,7 +3,9 @@ package repository
 import (
 	"dbapp/internal/model"
 	"dbapp/internal/test"
+	"fmt"
 	"testing"
+	"time"
 )
 
 func TestArticleRepository_Create(t *testing.T) {
@@ -100,10 +102,8 @@ func TestArticleRepository_List(t *testing.T) {
 	test.CreateTestArticle(db, user.ID, "文章3")
 
 	// 查询列表
-	conditions := map[string]interface{}{
-		"status": "published",
-	}
-	articles, total, err := repo.List(1, 10, conditions)
+	filter := ArticleFilter{Status: "published"}
+	articles, total, _, err := repo.List(filter, PageQuery{Page: 1, PageSize: 10})
 	if err != nil {
 		t.Fatalf("查询文章列表失败: %v", err)
 	}
@@ -130,10 +130,8 @@ func TestArticleRepository_ListWithPagination(t *testing.T) {
 	}
 
 	// 第一页
-	conditions := map[string]interface{}{
-		"status": "published",
-	}
-	articles, total, err := repo.List(1, 10, conditions)
+	filter := ArticleFilter{Status: "published"}
+	articles, total, _, err := repo.List(filter, PageQuery{Page: 1, PageSize: 10})
 	if err != nil {
 		t.Fatalf("查询文章列表失败: %v", err)
 	}
@@ -147,7 +145,7 @@ func TestArticleRepository_ListWithPagination(t *testing.T) {
 	}
 
 	// 第二页
-	articles2, _, err := repo.List(2, 10, conditions)
+	articles2, _, _, err := repo.List(filter, PageQuery{Page: 2, PageSize: 10})
 	if err != nil {
 		t.Fatalf("查询文章列表失败: %v", err)
 	}
@@ -171,11 +169,8 @@ func TestArticleRepository_ListWithFilter(t *testing.T) {
 	test.CreateTestArticle(db, user2.ID, "用户2的文章")
 
 	// 按作者筛选
-	conditions := map[string]interface{}{
-		"status":    "published",
-		"author_id": user1.ID,
-	}
-	articles, total, err := repo.List(1, 10, conditions)
+	filter := ArticleFilter{Status: "published", AuthorID: user1.ID}
+	articles, total, _, err := repo.List(filter, PageQuery{Page: 1, PageSize: 10})
 	if err != nil {
 		t.Fatalf("查询文章列表失败: %v", err)
 	}
@@ -266,3 +261,184 @@ func TestArticleRepository_Delete(t *testing.T) {
 	}
 }
 
+func TestArticleRepository_ListWithCursor(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	repo := NewArticleRepository(db)
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+
+	// 部分文章创建时间相同，验证按ID区分不会重复或遗漏
+	base := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	for i := 0; i < 10; i++ {
+		article := test.CreateTestArticle(db, user.ID, fmt.Sprintf("文章%d", i))
+		db.Model(article).UpdateColumn("created_at", base.Add(time.Duration(i/3)*time.Minute))
+	}
+
+	filter := ArticleFilter{Status: "published", Desc: true}
+	cursor := &Cursor{}
+	seen := make(map[uint64]bool)
+	var last *model.Article
+	for pages := 0; cursor != nil; pages++ {
+		if pages > 10 {
+			t.Fatal("翻页次数过多，游标没有前进")
+		}
+		articles, total, next, err := repo.List(filter, PageQuery{PageSize: 4, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("查询文章列表失败: %v", err)
+		}
+		if total != 0 {
+			t.Errorf("游标分页不应统计总数, 得到 %d", total)
+		}
+		for i := range articles {
+			article := articles[i]
+			if seen[article.ID] {
+				t.Errorf("文章 %d 重复出现", article.ID)
+			}
+			seen[article.ID] = true
+			if last != nil && (article.CreatedAt.After(last.CreatedAt) ||
+				(article.CreatedAt.Equal(last.CreatedAt) && article.ID > last.ID)) {
+				t.Errorf("文章 %d 排序错误", article.ID)
+			}
+			last = &article
+		}
+
+		// 翻页期间新增的文章不影响后续页
+		if pages == 0 {
+			test.CreateTestArticle(db, user.ID, "新文章")
+		}
+		cursor = next
+	}
+
+	if len(seen) != 10 {
+		t.Errorf("期望读取 10 篇文章, 得到 %d", len(seen))
+	}
+}
+
+func TestArticleRepository_ListWithCursor_Invalid(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	repo := NewArticleRepository(db)
+
+	// published_at 可能为空，不支持游标分页
+	_, _, _, err := repo.List(ArticleFilter{Sort: "published_at"}, PageQuery{PageSize: 10, Cursor: &Cursor{}})
+	if err != ErrInvalidCursor {
+		t.Errorf("不支持的排序字段应返回 ErrInvalidCursor, 得到 %v", err)
+	}
+
+	// 游标与排序参数不一致
+	cursor := &Cursor{Sort: "view_count", Order: "DESC", Value: "10", ID: 1}
+	_, _, _, err = repo.List(ArticleFilter{Sort: "created_at", Desc: true}, PageQuery{PageSize: 10, Cursor: cursor})
+	if err != ErrInvalidCursor {
+		t.Errorf("游标与排序不一致应返回 ErrInvalidCursor, 得到 %v", err)
+	}
+
+	if _, err := DecodeCursor("not-a-cursor!"); err != ErrInvalidCursor {
+		t.Errorf("无法解析的游标应返回 ErrInvalidCursor, 得到 %v", err)
+	}
+}
+
+func TestArticleRepository_ListWithTypedFilter(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	repo := NewArticleRepository(db)
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+
+	// 分类：后端 -> Go，前端
+	backend := &model.Category{Name: "后端", Slug: "backend"}
+	db.Create(backend)
+	golang := &model.Category{Name: "Go", Slug: "go", ParentID: &backend.ID}
+	db.Create(golang)
+	frontend := &model.Category{Name: "前端", Slug: "frontend"}
+	db.Create(frontend)
+	tagA := &model.Tag{Name: "A", Slug: "a"}
+	db.Create(tagA)
+	tagB := &model.Tag{Name: "B", Slug: "b"}
+	db.Create(tagB)
+
+	a1 := test.CreateTestArticle(db, user.ID, "后端文章")
+	a2 := test.CreateTestArticle(db, user.ID, "Go文章")
+	a3 := test.CreateTestArticle(db, user.ID, "前端文章")
+	repo.UpdateCategories(a1.ID, []uint64{backend.ID})
+	repo.UpdateCategories(a2.ID, []uint64{golang.ID, frontend.ID})
+	repo.UpdateCategories(a3.ID, []uint64{frontend.ID})
+	repo.UpdateTags(a1.ID, []uint64{tagA.ID, tagB.ID})
+	repo.UpdateTags(a2.ID, []uint64{tagA.ID})
+	db.Model(a3).UpdateColumns(map[string]interface{}{
+		"is_featured": true,
+		"created_at":  time.Now().AddDate(0, 0, -10),
+	})
+
+	ids := func(filter ArticleFilter) map[uint64]bool {
+		t.Helper()
+		articles, total, _, err := repo.List(filter, PageQuery{Page: 1, PageSize: 10})
+		if err != nil {
+			t.Fatalf("查询文章列表失败: %v", err)
+		}
+		if int(total) != len(articles) {
+			t.Errorf("总数 %d 与文章数量 %d 不一致", total, len(articles))
+		}
+		result := make(map[uint64]bool)
+		for _, article := range articles {
+			result[article.ID] = true
+		}
+		return result
+	}
+
+	// 任一分类
+	got := ids(ArticleFilter{CategoryIDs: []uint64{backend.ID, frontend.ID}})
+	if len(got) != 3 {
+		t.Errorf("任一分类期望 3 篇文章, 得到 %v", got)
+	}
+
+	// 同时属于全部分类，包含子分类时 Go文章 也属于后端
+	got = ids(ArticleFilter{CategoryIDs: []uint64{backend.ID, frontend.ID}, CategoryMatch: MatchAll})
+	if len(got) != 0 {
+		t.Errorf("不包含子分类时期望 0 篇文章, 得到 %v", got)
+	}
+	got = ids(ArticleFilter{CategoryIDs: []uint64{backend.ID, frontend.ID}, CategoryMatch: MatchAll, IncludeChildCategories: true})
+	if len(got) != 1 || !got[a2.ID] {
+		t.Errorf("包含子分类时期望只有 Go文章, 得到 %v", got)
+	}
+
+	// 标签
+	got = ids(ArticleFilter{TagIDs: []uint64{tagA.ID, tagB.ID}, TagMatch: MatchAll})
+	if len(got) != 1 || !got[a1.ID] {
+		t.Errorf("同时有两个标签期望只有 后端文章, 得到 %v", got)
+	}
+	got = ids(ArticleFilter{TagIDs: []uint64{tagA.ID, tagB.ID}})
+	if len(got) != 2 {
+		t.Errorf("任一标签期望 2 篇文章, 得到 %v", got)
+	}
+
+	// 推荐和时间范围
+	featured := true
+	got = ids(ArticleFilter{IsFeatured: &featured})
+	if len(got) != 1 || !got[a3.ID] {
+		t.Errorf("推荐文章期望只有 前端文章, 得到 %v", got)
+	}
+	from := time.Now().AddDate(0, 0, -1)
+	got = ids(ArticleFilter{CreatedFrom: &from})
+	if len(got) != 2 || got[a3.ID] {
+		t.Errorf("最近一天期望 2 篇文章, 得到 %v", got)
+	}
+}
+
+func TestArticleRepository_ListWithInvalidSort(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	repo := NewArticleRepository(db)
+
+	// 排序字段不能拼接任意SQL
+	_, _, _, err := repo.List(ArticleFilter{Sort: "id; DROP TABLE articles"}, PageQuery{Page: 1, PageSize: 10})
+	if err != ErrInvalidSort {
+		t.Errorf("不支持的排序字段应返回 ErrInvalidSort, 得到 %v", err)
+	}
+
+	if _, _, _, err := repo.List(ArticleFilter{Sort: "published_at", Desc: true}, PageQuery{Page: 1, PageSize: 10}); err != nil {
+		t.Errorf("按发布时间分页查询失败: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"dbapp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter 审计日志查询条件，零值字段不参与过滤
+type AuditLogFilter struct {
+	UserID     uint64
+	Action     string
+	TargetType string
+	TargetID   uint64
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditLogRepository struct {
+	*BaseRepository
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *AuditLogRepository) WithContext(ctx context.Context) *AuditLogRepository {
+	if r == nil {
+		return nil
+	}
+	return NewAuditLogRepository(r.db.WithContext(ctx))
+}
+
+func (r *AuditLogRepository) Create(log *model.AuditLog) error {
+	return r.db.Create(log).Error
+}
+
+func (r *AuditLogRepository) query(filter AuditLogFilter) *gorm.DB {
+	query := r.db.Model(&model.AuditLog{})
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID > 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
+
+// List 分页查询审计日志，按时间倒序
+func (r *AuditLogRepository) List(filter AuditLogFilter, page, pageSize int) ([]model.AuditLog, int64, error) {
+	var logs []model.AuditLog
+	var total int64
+
+	query := r.query(filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("User").
+		Order("id DESC").
+		Scopes(r.Paginate(page, pageSize)).
+		Find(&logs).Error
+
+	return logs, total, err
+}
+
+// Each 按时间倒序分批读取审计日志并依次回调，最多读取 limit 条，用于导出
+func (r *AuditLogRepository) Each(filter AuditLogFilter, batchSize, limit int, fn func([]model.AuditLog) error) error {
+	// 按ID游标分批，避免深分页的OFFSET越来越慢
+	var lastID uint64
+	read := 0
+	for read < limit {
+		size := batchSize
+		if limit-read < size {
+			size = limit - read
+		}
+
+		var logs []model.AuditLog
+		query := r.query(filter)
+		if lastID > 0 {
+			query = query.Where("id < ?", lastID)
+		}
+		if err := query.Preload("User").Order("id DESC").Limit(size).Find(&logs).Error; err != nil {
+			return err
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		if err := fn(logs); err != nil {
+			return err
+		}
+
+		read += len(logs)
+		lastID = logs[len(logs)-1].ID
+		if len(logs) < size {
+			return nil
+		}
+	}
+	return nil
+}
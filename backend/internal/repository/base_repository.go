@@ -16,6 +16,16 @@ func (r *BaseRepository) DB() *gorm.DB {
 	return r.db
 }
 
+// countArticles 统计关联表中与 id 关联且未删除的文章数
+func countArticles(db *gorm.DB, joinTable, column string, id uint64) int64 {
+	var count int64
+	db.Table(joinTable).
+		Joins("JOIN articles ON articles.id = "+joinTable+".article_id AND articles.deleted_at IS NULL").
+		Where(joinTable+"."+column+" = ?", id).
+		Count(&count)
+	return count
+}
+
 func (r *BaseRepository) Paginate(page, pageSize int) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
 		if page <= 0 {
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"dbapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type BookmarkRepository struct {
+	*BaseRepository
+}
+
+func NewBookmarkRepository(db *gorm.DB) *BookmarkRepository {
+	return &BookmarkRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *BookmarkRepository) WithContext(ctx context.Context) *BookmarkRepository {
+	if r == nil {
+		return nil
+	}
+	return NewBookmarkRepository(r.db.WithContext(ctx))
+}
+
+// Create 创建收藏，放入阅读列表时排在列表末尾
+func (r *BookmarkRepository) Create(bookmark *model.Bookmark) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		position, err := nextBookmarkPosition(tx, bookmark.ReadingListID)
+		if err != nil {
+			return err
+		}
+		bookmark.Position = position
+		return tx.Create(bookmark).Error
+	})
+}
+
+// Get 查询用户对文章的收藏，未收藏时返回 nil
+func (r *BookmarkRepository) Get(userID, articleID uint64) (*model.Bookmark, error) {
+	var bookmark model.Bookmark
+	err := r.db.Where("user_id = ? AND article_id = ?", userID, articleID).First(&bookmark).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &bookmark, err
+}
+
+// Delete 取消收藏，未收藏时返回 false
+func (r *BookmarkRepository) Delete(userID, articleID uint64) (bool, error) {
+	result := r.db.Where("user_id = ? AND article_id = ?", userID, articleID).Delete(&model.Bookmark{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Move 把收藏移到另一个阅读列表的末尾，listID 为 nil 时移出列表
+func (r *BookmarkRepository) Move(bookmark *model.Bookmark, listID *uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		position, err := nextBookmarkPosition(tx, listID)
+		if err != nil {
+			return err
+		}
+		bookmark.ReadingListID = listID
+		bookmark.Position = position
+		return tx.Model(bookmark).Select("reading_list_id", "position").Updates(bookmark).Error
+	})
+}
+
+// nextBookmarkPosition 返回阅读列表末尾的位置，不在列表中的收藏位置为 0
+func nextBookmarkPosition(tx *gorm.DB, listID *uint64) (int, error) {
+	if listID == nil {
+		return 0, nil
+	}
+	var max int
+	err := tx.Model(&model.Bookmark{}).Where("reading_list_id = ?", *listID).
+		Select("COALESCE(MAX(position), 0)").Scan(&max).Error
+	return max + 1, err
+}
+
+// BookmarkFilter 收藏列表的查询条件
+type BookmarkFilter struct {
+	UserID        uint64 // 收藏者，为 0 时不限
+	ReadingListID uint64 // 阅读列表，为 0 时不限；指定时按列表中的顺序排列，否则按收藏时间倒序
+	// Viewer 查看收藏的用户，只返回其可见的文章
+	Viewer *ArticleViewer
+}
+
+// List 分页查询收藏，只包含未删除且对 Viewer 可见的文章
+func (r *BookmarkRepository) List(filter BookmarkFilter, page, pageSize int) ([]model.Bookmark, int64, error) {
+	var bookmarks []model.Bookmark
+	var total int64
+
+	query := r.visible(filter.Viewer)
+	if filter.UserID > 0 {
+		query = query.Where("bookmarks.user_id = ?", filter.UserID)
+	}
+	if filter.ReadingListID > 0 {
+		query = query.Where("bookmarks.reading_list_id = ?", filter.ReadingListID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.ReadingListID > 0 {
+		query = query.Order("bookmarks.position ASC, bookmarks.id ASC")
+	} else {
+		query = query.Order("bookmarks.created_at DESC, bookmarks.id DESC")
+	}
+	err := query.Preload("Article.Author").Preload("Article.Categories").Preload("Article.Tags").
+		Scopes(r.Paginate(page, pageSize)).
+		Find(&bookmarks).Error
+
+	return bookmarks, total, err
+}
+
+// CountByList 统计各阅读列表中对 viewer 可见的收藏数，返回 列表ID -> 数量
+func (r *BookmarkRepository) CountByList(listIDs []uint64, viewer *ArticleViewer) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64)
+	if len(listIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ReadingListID uint64
+		Count         int64
+	}
+	err := r.visible(viewer).
+		Where("bookmarks.reading_list_id IN ?", listIDs).
+		Select("bookmarks.reading_list_id, COUNT(*) AS count").
+		Group("bookmarks.reading_list_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ReadingListID] = row.Count
+	}
+	return counts, nil
+}
+
+// visible 收藏查询，关联未删除且对 viewer 可见的文章
+func (r *BookmarkRepository) visible(viewer *ArticleViewer) *gorm.DB {
+	query := r.db.Model(&model.Bookmark{}).
+		Joins("JOIN articles ON articles.id = bookmarks.article_id AND articles.deleted_at IS NULL")
+	return viewer.visible(query)
+}
+
+// ArticleIDsInList 按顺序返回阅读列表中所有收藏的文章ID
+func (r *BookmarkRepository) ArticleIDsInList(listID uint64) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.Model(&model.Bookmark{}).
+		Where("reading_list_id = ?", listID).
+		Order("position ASC, id ASC").
+		Pluck("article_id", &ids).Error
+	return ids, err
+}
+
+// Reorder 在一个事务中把 articleIDs 依次排在阅读列表的最前面，列表中其余收藏保持原有相对顺序排在之后
+func (r *BookmarkRepository) Reorder(listID uint64, articleIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Bookmark{}).
+			Where("reading_list_id = ? AND article_id NOT IN ?", listID, articleIDs).
+			UpdateColumn("position", gorm.Expr("position + ?", len(articleIDs))).Error
+		if err != nil {
+			return err
+		}
+		for i, articleID := range articleIDs {
+			err := tx.Model(&model.Bookmark{}).
+				Where("reading_list_id = ? AND article_id = ?", listID, articleID).
+				UpdateColumn("position", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BookmarkedArticles 返回 articleIDs 中用户已收藏的文章
+func (r *BookmarkRepository) BookmarkedArticles(userID uint64, articleIDs []uint64) (map[uint64]bool, error) {
+	bookmarked := make(map[uint64]bool)
+	if len(articleIDs) == 0 {
+		return bookmarked, nil
+	}
+
+	var ids []uint64
+	err := r.db.Model(&model.Bookmark{}).
+		Where("user_id = ? AND article_id IN ?", userID, articleIDs).
+		Pluck("article_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		bookmarked[id] = true
+	}
+	return bookmarked, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"dbapp/internal/model"
 	"gorm.io/gorm"
 )
@@ -15,6 +16,14 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
 	}
 }
 
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *CategoryRepository) WithContext(ctx context.Context) *CategoryRepository {
+	if r == nil {
+		return nil
+	}
+	return NewCategoryRepository(r.db.WithContext(ctx))
+}
+
 func (r *CategoryRepository) Create(category *model.Category) error {
 	return r.db.Create(category).Error
 }
@@ -26,9 +35,7 @@ func (r *CategoryRepository) GetByID(id uint64) (*model.Category, error) {
 		return &category, err
 	}
 	// 动态计算文章数
-	var count int64
-	r.db.Table("article_categories").Where("category_id = ?", category.ID).Count(&count)
-	category.ArticleCount = int(count)
+	category.ArticleCount = int(countArticles(r.db, "article_categories", "category_id", category.ID))
 	return &category, nil
 }
 
@@ -67,9 +74,7 @@ func (r *CategoryRepository) List(parentID *uint64, isActive *bool) ([]model.Cat
 
 	// 动态计算每个分类的文章数
 	for i := range categories {
-		var count int64
-		r.db.Table("article_categories").Where("category_id = ?", categories[i].ID).Count(&count)
-		categories[i].ArticleCount = int(count)
+		categories[i].ArticleCount = int(countArticles(r.db, "article_categories", "category_id", categories[i].ID))
 	}
 
 	return categories, nil
@@ -88,14 +93,10 @@ func (r *CategoryRepository) GetTree() ([]model.Category, error) {
 
 	// 动态计算每个分类及其子分类的文章数
 	for i := range categories {
-		var count int64
-		r.db.Table("article_categories").Where("category_id = ?", categories[i].ID).Count(&count)
-		categories[i].ArticleCount = int(count)
+		categories[i].ArticleCount = int(countArticles(r.db, "article_categories", "category_id", categories[i].ID))
 		
 		for j := range categories[i].Children {
-			var childCount int64
-			r.db.Table("article_categories").Where("category_id = ?", categories[i].Children[j].ID).Count(&childCount)
-			categories[i].Children[j].ArticleCount = int(childCount)
+			categories[i].Children[j].ArticleCount = int(countArticles(r.db, "article_categories", "category_id", categories[i].Children[j].ID))
 		}
 	}
 
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"dbapp/internal/model"
 	"gorm.io/gorm"
 )
@@ -15,10 +16,70 @@ func NewCommentRepository(db *gorm.DB) *CommentRepository {
 	}
 }
 
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *CommentRepository) WithContext(ctx context.Context) *CommentRepository {
+	if r == nil {
+		return nil
+	}
+	return NewCommentRepository(r.db.WithContext(ctx))
+}
+
 func (r *CommentRepository) Create(comment *model.Comment) error {
 	return r.db.Create(comment).Error
 }
 
+// CreateWithCounters 创建评论，并在同一事务中增加父评论回复数和文章评论数
+// 待审核的评论不计入计数，审核通过时由 PublishWithCounters 补上
+func (r *CommentRepository) CreateWithCounters(comment *model.Comment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		if comment.Status != "published" {
+			return nil
+		}
+		return incrementCommentCounters(tx, comment)
+	})
+}
+
+// PublishWithCounters 将待审核评论改为已发布，并在同一事务中增加父评论回复数和文章评论数
+// 评论已不是待审核状态时返回 false
+func (r *CommentRepository) PublishWithCounters(comment *model.Comment) (bool, error) {
+	published := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Comment{}).
+			Where("id = ? AND status = ?", comment.ID, "pending").
+			Update("status", "published")
+		if result.Error != nil {
+			return result.Error
+		}
+		// 已被并发审核时不再重复增加计数
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		published = true
+		return incrementCommentCounters(tx, comment)
+	})
+	return published, err
+}
+
+// Reject 将待审核评论标记为已拒绝，拒绝的评论不计入任何计数。评论已不是待审核状态时返回 false
+func (r *CommentRepository) Reject(id uint64) (bool, error) {
+	result := r.db.Model(&model.Comment{}).
+		Where("id = ? AND status = ?", id, "pending").
+		Update("status", "rejected")
+	return result.RowsAffected > 0, result.Error
+}
+
+func incrementCommentCounters(tx *gorm.DB, comment *model.Comment) error {
+	if comment.ParentID != nil && *comment.ParentID > 0 {
+		if err := NewCommentRepository(tx).IncrementReplyCount(*comment.ParentID); err != nil {
+			return err
+		}
+	}
+	return NewArticleRepository(tx).IncrementCommentCount(comment.ArticleID)
+}
+
 func (r *CommentRepository) GetByID(id uint64) (*model.Comment, error) {
 	var comment model.Comment
 	err := r.db.Preload("User").Preload("Parent").Preload("Replies.User").
@@ -26,29 +87,47 @@ func (r *CommentRepository) GetByID(id uint64) (*model.Comment, error) {
 	return &comment, err
 }
 
-func (r *CommentRepository) ListByArticle(articleID uint64, page, pageSize int) ([]model.Comment, int64, error) {
-	var comments []model.Comment
-	var total int64
-
+// ListByArticle 按创建时间倒序查询文章的顶级评论及其回复。
+// 按页码分页时返回总数；按游标分页时不统计总数，还有下一页时返回 next
+func (r *CommentRepository) ListByArticle(articleID uint64, page PageQuery) (comments []model.Comment, total int64, next *Cursor, err error) {
 	// 只查询顶级评论（parent_id为NULL）
 	query := r.db.Model(&model.Comment{}).
 		Where("article_id = ? AND parent_id IS NULL AND status = ?", articleID, "published")
 
+	withAssociations := func(db *gorm.DB) *gorm.DB {
+		return db.Preload("User").
+			Preload("Replies", func(db *gorm.DB) *gorm.DB {
+				return db.Where("status = ?", "published").Preload("User").Order("created_at ASC")
+			})
+	}
+
+	if page.IsCursor() {
+		scope, err := keyset(page.Cursor, "created_at", keysetColumn{Column: "created_at", IsTime: true}, true, page.PageSize)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if err := query.Scopes(withAssociations, scope).Find(&comments).Error; err != nil {
+			return nil, 0, nil, err
+		}
+		if len(comments) > page.PageSize {
+			comments = comments[:page.PageSize]
+			last := &comments[len(comments)-1]
+			next = nextCursor("created_at", true, last.CreatedAt, last.ID)
+		}
+		return comments, 0, next, nil
+	}
+
 	// 统计总数
 	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+		return nil, 0, nil, err
 	}
 
 	// 分页查询
-	err := query.Preload("User").
-		Preload("Replies", func(db *gorm.DB) *gorm.DB {
-			return db.Where("status = ?", "published").Preload("User").Order("created_at ASC")
-		}).
-		Scopes(r.Paginate(page, pageSize)).
+	err = query.Scopes(withAssociations, r.Paginate(page.Page, page.PageSize)).
 		Order("created_at DESC").
 		Find(&comments).Error
 
-	return comments, total, err
+	return comments, total, nil, err
 }
 
 func (r *CommentRepository) ListByUser(userID uint64, page, pageSize int) ([]model.Comment, int64, error) {
@@ -70,6 +149,25 @@ func (r *CommentRepository) ListByUser(userID uint64, page, pageSize int) ([]mod
 	return comments, total, err
 }
 
+// ListByStatus 按状态分页查询评论，按创建时间正序，用于审核队列
+func (r *CommentRepository) ListByStatus(status string, page, pageSize int) ([]model.Comment, int64, error) {
+	var comments []model.Comment
+	var total int64
+
+	query := r.db.Model(&model.Comment{}).Where("status = ?", status)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("User").
+		Scopes(r.Paginate(page, pageSize)).
+		Order("created_at ASC").
+		Find(&comments).Error
+
+	return comments, total, err
+}
+
 func (r *CommentRepository) Update(comment *model.Comment) error {
 	return r.db.Save(comment).Error
 }
@@ -78,6 +176,95 @@ func (r *CommentRepository) Delete(id uint64) error {
 	return r.db.Delete(&model.Comment{}, id).Error
 }
 
+// DeleteWithCounters 软删除评论及其点赞，并在同一事务中减少父评论回复数和文章评论数
+func (r *CommentRepository) DeleteWithCounters(comment *model.Comment) error {
+	now := deletionTime()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Comment{}).Where("id = ?", comment.ID).UpdateColumn("deleted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		// 已被并发删除时不再重复扣减计数，待审核的评论本就没有计数
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Model(&model.Like{}).
+			Where("target_type = ? AND target_id = ?", "comment", comment.ID).
+			UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		if comment.Status != "published" {
+			return nil
+		}
+		if comment.ParentID != nil && *comment.ParentID > 0 {
+			if err := NewCommentRepository(tx).DecrementReplyCount(*comment.ParentID); err != nil {
+				return err
+			}
+		}
+		return NewArticleRepository(tx).DecrementCommentCount(comment.ArticleID)
+	})
+}
+
+// GetDeleted 查询已删除的评论
+func (r *CommentRepository) GetDeleted(id uint64) (*model.Comment, error) {
+	var comment model.Comment
+	err := r.db.Unscoped().Preload("User").
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&comment).Error
+	return &comment, err
+}
+
+// ListDeleted 按删除时间倒序分页查询已删除的评论，userID 为 0 时查询所有用户的评论。
+// 随文章一起删除的评论在文章的回收站中，不在这里列出
+func (r *CommentRepository) ListDeleted(userID uint64, page, pageSize int) ([]model.Comment, int64, error) {
+	var comments []model.Comment
+	var total int64
+
+	query := r.db.Unscoped().Model(&model.Comment{}).
+		Where("deleted_at IS NOT NULL").
+		Where("article_id IN (?)", r.db.Model(&model.Article{}).Select("id"))
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("User").
+		Order("deleted_at DESC").
+		Scopes(r.Paginate(page, pageSize)).
+		Find(&comments).Error
+
+	return comments, total, err
+}
+
+// RestoreWithCounters 恢复已删除的评论及与其一起删除的点赞，已发布的评论在同一事务中恢复父评论回复数和文章评论数。
+// 评论已被恢复时返回 false
+func (r *CommentRepository) RestoreWithCounters(comment *model.Comment) (bool, error) {
+	restored := false
+	deletedAt := comment.DeletedAt.Time
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&model.Comment{}).
+			Where("id = ? AND deleted_at = ?", comment.ID, deletedAt).
+			UpdateColumn("deleted_at", nil)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		restored = true
+
+		if err := tx.Unscoped().Model(&model.Like{}).
+			Where("target_type = ? AND target_id = ? AND deleted_at = ?", "comment", comment.ID, deletedAt).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if comment.Status != "published" {
+			return nil
+		}
+		return incrementCommentCounters(tx, comment)
+	})
+	return restored, err
+}
+
 func (r *CommentRepository) IncrementLikeCount(id uint64) error {
 	return r.db.Model(&model.Comment{}).Where("id = ?", id).
 		UpdateColumn("like_count", gorm.Expr("like_count + 1")).Error
@@ -85,7 +272,7 @@ func (r *CommentRepository) IncrementLikeCount(id uint64) error {
 
 func (r *CommentRepository) DecrementLikeCount(id uint64) error {
 	return r.db.Model(&model.Comment{}).Where("id = ?", id).
-		UpdateColumn("like_count", gorm.Expr("GREATEST(like_count - 1, 0)")).Error
+		UpdateColumn("like_count", gorm.Expr("CASE WHEN like_count > 0 THEN like_count - 1 ELSE 0 END")).Error
 }
 
 func (r *CommentRepository) IncrementReplyCount(id uint64) error {
@@ -95,7 +282,7 @@ func (r *CommentRepository) IncrementReplyCount(id uint64) error {
 
 func (r *CommentRepository) DecrementReplyCount(id uint64) error {
 	return r.db.Model(&model.Comment{}).Where("id = ?", id).
-		UpdateColumn("reply_count", gorm.Expr("GREATEST(reply_count - 1, 0)")).Error
+		UpdateColumn("reply_count", gorm.Expr("CASE WHEN reply_count > 0 THEN reply_count - 1 ELSE 0 END")).Error
 }
 
 func (r *CommentRepository) CountByArticle(articleID uint64) (int64, error) {
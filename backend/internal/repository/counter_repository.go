@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// CounterRepository 根据源数据表重新计算冗余计数字段
+type CounterRepository struct {
+	*BaseRepository
+}
+
+func NewCounterRepository(db *gorm.DB) *CounterRepository {
+	return &CounterRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// counterFix 一个冗余计数字段及其从源表计算的子查询。
+// softDelete 为 true 时跳过已软删除的行：回收站中的文章和评论的关联数据也已软删除，
+// 重新计算会把计数清零，恢复时计数无法还原
+type counterFix struct {
+	name       string
+	table      string
+	column     string
+	subquery   string
+	softDelete bool
+}
+
+var counterFixes = []counterFix{
+	{
+		name:       "articles.like_count",
+		table:      "articles",
+		column:     "like_count",
+		subquery:   "SELECT COUNT(*) FROM likes WHERE likes.target_type = 'article' AND likes.target_id = articles.id AND likes.deleted_at IS NULL",
+		softDelete: true,
+	},
+	{
+		name:       "articles.comment_count",
+		table:      "articles",
+		column:     "comment_count",
+		subquery:   "SELECT COUNT(*) FROM comments WHERE comments.article_id = articles.id AND comments.status = 'published' AND comments.deleted_at IS NULL",
+		softDelete: true,
+	},
+	{
+		name:       "comments.like_count",
+		table:      "comments",
+		column:     "like_count",
+		subquery:   "SELECT COUNT(*) FROM likes WHERE likes.target_type = 'comment' AND likes.target_id = comments.id AND likes.deleted_at IS NULL",
+		softDelete: true,
+	},
+	{
+		name:       "comments.reply_count",
+		table:      "comments",
+		column:     "reply_count",
+		subquery:   "SELECT COUNT(*) FROM comments AS replies WHERE replies.parent_id = comments.id AND replies.status = 'published' AND replies.deleted_at IS NULL",
+		softDelete: true,
+	},
+	{
+		name:     "categories.article_count",
+		table:    "categories",
+		column:   "article_count",
+		subquery: "SELECT COUNT(*) FROM article_categories JOIN articles ON articles.id = article_categories.article_id AND articles.deleted_at IS NULL WHERE article_categories.category_id = categories.id",
+	},
+	{
+		name:     "tags.article_count",
+		table:    "tags",
+		column:   "article_count",
+		subquery: "SELECT COUNT(*) FROM article_tags JOIN articles ON articles.id = article_tags.article_id AND articles.deleted_at IS NULL WHERE article_tags.tag_id = tags.id",
+	},
+}
+
+// Reconcile 在一个事务中重新计算所有冗余计数，返回每个字段被修正的行数
+func (r *CounterRepository) Reconcile() (map[string]int64, error) {
+	fixed := make(map[string]int64, len(counterFixes))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, fix := range counterFixes {
+			sql := "UPDATE " + fix.table + " SET " + fix.column + " = (" + fix.subquery + ")" +
+				" WHERE " + fix.column + " <> (" + fix.subquery + ")"
+			if fix.softDelete {
+				sql += " AND " + fix.table + ".deleted_at IS NULL"
+			}
+			result := tx.Exec(sql)
+			if result.Error != nil {
+				return result.Error
+			}
+			fixed[fix.name] = result.RowsAffected
+		}
+		return nil
+	})
+	return fixed, err
+}
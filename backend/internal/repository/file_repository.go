@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"dbapp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FileRepository struct {
+	*BaseRepository
+}
+
+func NewFileRepository(db *gorm.DB) *FileRepository {
+	return &FileRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *FileRepository) WithContext(ctx context.Context) *FileRepository {
+	if r == nil {
+		return nil
+	}
+	return NewFileRepository(r.db.WithContext(ctx))
+}
+
+// Create 创建文件记录
+func (r *FileRepository) Create(file *model.File) error {
+	return r.db.Create(file).Error
+}
+
+// CreateWithQuota 在一个事务中锁定上传者，统计其已用空间和 since 之后的上传次数，check 通过后创建文件记录。
+// 锁定上传者的用户记录使同一用户的上传串行执行，多个实例同时上传时统计结果也不会过期；check 的错误原样返回
+func (r *FileRepository) CreateWithQuota(file *model.File, since time.Time, check func(used, count int64) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var uploader model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			First(&uploader, file.UploaderID).Error; err != nil {
+			return err
+		}
+
+		txRepo := NewFileRepository(tx)
+		used, err := txRepo.SumSizeByUploader(file.UploaderID)
+		if err != nil {
+			return err
+		}
+		count, err := txRepo.CountByUploaderSince(file.UploaderID, since)
+		if err != nil {
+			return err
+		}
+		if err := check(used, count); err != nil {
+			return err
+		}
+		return tx.Create(file).Error
+	})
+}
+
+// SumSizeByUploader 统计用户已使用的存储空间（字节）
+func (r *FileRepository) SumSizeByUploader(uploaderID uint64) (int64, error) {
+	var total int64
+	err := r.db.Model(&model.File{}).
+		Where("uploader_id = ?", uploaderID).
+		Select("COALESCE(SUM(file_size), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// CountByUploaderSince 统计用户在指定时间之后的上传次数
+func (r *FileRepository) CountByUploaderSince(uploaderID uint64, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.File{}).
+		Where("uploader_id = ? AND created_at >= ?", uploaderID, since).
+		Count(&count).Error
+	return count, err
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"dbapp/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LikeRepository struct {
@@ -15,6 +17,14 @@ func NewLikeRepository(db *gorm.DB) *LikeRepository {
 	}
 }
 
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *LikeRepository) WithContext(ctx context.Context) *LikeRepository {
+	if r == nil {
+		return nil
+	}
+	return NewLikeRepository(r.db.WithContext(ctx))
+}
+
 func (r *LikeRepository) Create(like *model.Like) error {
 	return r.db.Create(like).Error
 }
@@ -30,7 +40,7 @@ func (r *LikeRepository) GetByUserAndTarget(userID uint64, targetType string, ta
 }
 
 func (r *LikeRepository) Delete(userID uint64, targetType string, targetID uint64) error {
-	return r.db.Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).
+	return r.db.Unscoped().Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).
 		Delete(&model.Like{}).Error
 }
 
@@ -50,3 +60,83 @@ func (r *LikeRepository) IsLikedByUser(userID uint64, targetType string, targetI
 	return count > 0, err
 }
 
+// LikedTargets 返回 targetIDs 中用户已点赞的目标，用一次查询代替逐个调用 IsLikedByUser
+func (r *LikeRepository) LikedTargets(userID uint64, targetType string, targetIDs []uint64) (map[uint64]bool, error) {
+	liked := make(map[uint64]bool)
+	if len(targetIDs) == 0 {
+		return liked, nil
+	}
+
+	var ids []uint64
+	err := r.db.Model(&model.Like{}).
+		Where("user_id = ? AND target_type = ? AND target_id IN ?", userID, targetType, targetIDs).
+		Pluck("target_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		liked[id] = true
+	}
+	return liked, nil
+}
+
+
+// Toggle 切换点赞状态，并在同一事务中更新目标的点赞计数。
+// 依赖 (user_id, target_type, target_id) 唯一索引，并发点赞不会产生重复记录或重复计数。
+func (r *LikeRepository) Toggle(userID uint64, targetType string, targetID uint64) (bool, error) {
+	liked := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 取消点赞直接删除记录，软删除的记录会占用唯一索引，导致无法再次点赞
+		result := tx.Unscoped().Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).
+			Delete(&model.Like{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return adjustLikeCount(tx, targetType, targetID, false)
+		}
+
+		like := &model.Like{
+			UserID:     userID,
+			TargetType: targetType,
+			TargetID:   targetID,
+		}
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(like)
+		if result.Error != nil {
+			return result.Error
+		}
+		liked = true
+		if result.RowsAffected > 0 {
+			return adjustLikeCount(tx, targetType, targetID, true)
+		}
+		return nil
+	})
+	return liked, err
+}
+
+// DeleteDuplicates 删除重复的点赞记录，每组只保留最早的一条。
+// 在创建唯一索引前调用，否则历史重复数据会导致建索引失败。
+func (r *LikeRepository) DeleteDuplicates() (int64, error) {
+	result := r.db.Exec(`DELETE FROM likes WHERE id NOT IN (
+		SELECT MIN(id) FROM likes GROUP BY user_id, target_type, target_id
+	)`)
+	return result.RowsAffected, result.Error
+}
+
+func adjustLikeCount(tx *gorm.DB, targetType string, targetID uint64, increment bool) error {
+	switch targetType {
+	case "article":
+		articleRepo := NewArticleRepository(tx)
+		if increment {
+			return articleRepo.IncrementLikeCount(targetID)
+		}
+		return articleRepo.DecrementLikeCount(targetID)
+	case "comment":
+		commentRepo := NewCommentRepository(tx)
+		if increment {
+			return commentRepo.IncrementLikeCount(targetID)
+		}
+		return commentRepo.DecrementLikeCount(targetID)
+	}
+	return nil
+}
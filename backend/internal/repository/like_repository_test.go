@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"dbapp/internal/model"
+	"dbapp/internal/test"
+	"testing"
+)
+
+func TestLikeRepository_Toggle(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	repo := NewLikeRepository(db)
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "测试文章")
+
+	liked, err := repo.Toggle(user.ID, "article", article.ID)
+	if err != nil || !liked {
+		t.Fatalf("点赞失败: liked=%v err=%v", liked, err)
+	}
+
+	var found model.Article
+	db.First(&found, article.ID)
+	if found.LikeCount != 1 {
+		t.Errorf("期望点赞数 1, 得到 %d", found.LikeCount)
+	}
+
+	liked, err = repo.Toggle(user.ID, "article", article.ID)
+	if err != nil || liked {
+		t.Fatalf("取消点赞失败: liked=%v err=%v", liked, err)
+	}
+
+	db.First(&found, article.ID)
+	if found.LikeCount != 0 {
+		t.Errorf("期望点赞数 0, 得到 %d", found.LikeCount)
+	}
+}
+
+func TestLikeRepository_LikedTargets(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	repo := NewLikeRepository(db)
+	repo.Create(&model.Like{UserID: 1, TargetType: "article", TargetID: 1})
+	repo.Create(&model.Like{UserID: 1, TargetType: "article", TargetID: 3})
+	repo.Create(&model.Like{UserID: 1, TargetType: "comment", TargetID: 2})
+	repo.Create(&model.Like{UserID: 2, TargetType: "article", TargetID: 2})
+
+	liked, err := repo.LikedTargets(1, "article", []uint64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("查询点赞状态失败: %v", err)
+	}
+	if len(liked) != 2 || !liked[1] || !liked[3] {
+		t.Errorf("期望已点赞 1 和 3, 得到 %v", liked)
+	}
+
+	liked, err = repo.LikedTargets(1, "article", nil)
+	if err != nil || len(liked) != 0 {
+		t.Errorf("空ID列表应返回空结果: %v %v", liked, err)
+	}
+}
+
+func TestLikeRepository_UniqueIndex(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	repo := NewLikeRepository(db)
+	like := &model.Like{UserID: 1, TargetType: "article", TargetID: 1}
+	if err := repo.Create(like); err != nil {
+		t.Fatalf("创建点赞失败: %v", err)
+	}
+
+	duplicate := &model.Like{UserID: 1, TargetType: "article", TargetID: 1}
+	if err := repo.Create(duplicate); err == nil {
+		t.Error("重复点赞应违反唯一索引")
+	}
+}
+
+func TestCounterRepository_Reconcile(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "测试文章")
+
+	commentRepo := NewCommentRepository(db)
+	parent := &model.Comment{ArticleID: article.ID, UserID: user.ID, Content: "评论", Status: "published"}
+	if err := commentRepo.CreateWithCounters(parent); err != nil {
+		t.Fatalf("创建评论失败: %v", err)
+	}
+	reply := &model.Comment{ArticleID: article.ID, UserID: user.ID, ParentID: &parent.ID, Content: "回复", Status: "published"}
+	if err := commentRepo.CreateWithCounters(reply); err != nil {
+		t.Fatalf("创建回复失败: %v", err)
+	}
+	db.Create(&model.Like{UserID: user.ID, TargetType: "article", TargetID: article.ID})
+
+	// 人为制造计数偏差
+	db.Model(&model.Article{}).Where("id = ?", article.ID).
+		UpdateColumns(map[string]interface{}{"comment_count": 10, "like_count": 0})
+	db.Model(&model.Comment{}).Where("id = ?", parent.ID).UpdateColumn("reply_count", 5)
+
+	fixed, err := NewCounterRepository(db).Reconcile()
+	if err != nil {
+		t.Fatalf("重新计算计数失败: %v", err)
+	}
+	if fixed["articles.comment_count"] != 1 || fixed["comments.reply_count"] != 1 {
+		t.Errorf("期望修正 1 行, 得到 %v", fixed)
+	}
+
+	var found model.Article
+	db.First(&found, article.ID)
+	if found.CommentCount != 2 || found.LikeCount != 1 {
+		t.Errorf("期望评论数 2 点赞数 1, 得到 %d %d", found.CommentCount, found.LikeCount)
+	}
+	var foundParent model.Comment
+	db.First(&foundParent, parent.ID)
+	if foundParent.ReplyCount != 1 {
+		t.Errorf("期望回复数 1, 得到 %d", foundParent.ReplyCount)
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor 游标无法解析或与当前排序方式不匹配
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageQuery 列表的分页方式。
+// Cursor 为 nil 时按页码分页并统计总数；否则使用键集分页，从 Cursor 之后读取 PageSize 条，不统计总数。
+// 键集分页不受翻页期间新增数据的影响，也没有深分页的 OFFSET 开销。
+type PageQuery struct {
+	Page     int
+	PageSize int
+	Cursor   *Cursor
+}
+
+// IsCursor 是否使用键集分页
+func (q PageQuery) IsCursor() bool {
+	return q.Cursor != nil
+}
+
+// Cursor 键集分页的位置：上一页最后一条记录的排序字段值和ID。
+// 零值表示从第一条开始。对外以 Encode 后的不透明字符串传递。
+type Cursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v,omitempty"`
+	ID    uint64 `json:"i,omitempty"`
+}
+
+// Encode 将游标编码为URL安全的字符串
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor 解析 Encode 生成的游标，空字符串表示第一页
+func DecodeCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return &Cursor{}, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// keysetColumn 排序列，可为 NULL 的列不支持键集分页
+type keysetColumn struct {
+	Column   string
+	IsTime   bool // 时间列的游标值为 RFC3339Nano 字符串，否则为整数
+	Nullable bool
+}
+
+// keyset 返回键集分页的查询条件和排序，按 column、id 排序，id 用于区分排序值相同的记录。
+// 游标的排序方式必须与本次查询一致，否则返回 ErrInvalidCursor。
+func keyset(cursor *Cursor, sort string, column keysetColumn, desc bool, pageSize int) (func(*gorm.DB) *gorm.DB, error) {
+	if column.Nullable {
+		return nil, ErrInvalidCursor
+	}
+
+	order := "ASC"
+	cmp := ">"
+	if desc {
+		order = "DESC"
+		cmp = "<"
+	}
+
+	var value interface{}
+	if cursor.ID > 0 {
+		if cursor.Sort != sort || cursor.Order != order {
+			return nil, ErrInvalidCursor
+		}
+		if column.IsTime {
+			t, err := time.Parse(time.RFC3339Nano, cursor.Value)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			value = t
+		} else {
+			n, err := strconv.ParseInt(cursor.Value, 10, 64)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			value = n
+		}
+	}
+
+	// 排序列带表名时ID列也带上表名，避免JOIN后列名不明确
+	idColumn := "id"
+	if i := strings.LastIndex(column.Column, "."); i >= 0 {
+		idColumn = column.Column[:i] + ".id"
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if value != nil {
+			db = db.Where("("+column.Column+" "+cmp+" ? OR ("+column.Column+" = ? AND "+idColumn+" "+cmp+" ?))",
+				value, value, cursor.ID)
+		}
+		// 多取一条，用于判断是否还有下一页
+		return db.Order(column.Column + " " + order).Order(idColumn + " " + order).Limit(pageSize + 1)
+	}, nil
+}
+
+// nextCursor 根据本页最后一条记录生成下一页的游标
+func nextCursor(sort string, desc bool, value interface{}, id uint64) *Cursor {
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+	cursor := &Cursor{Sort: sort, Order: order, ID: id}
+	switch v := value.(type) {
+	case time.Time:
+		cursor.Value = v.Format(time.RFC3339Nano)
+	case int:
+		cursor.Value = strconv.Itoa(v)
+	case int64:
+		cursor.Value = strconv.FormatInt(v, 10)
+	}
+	return cursor
+}
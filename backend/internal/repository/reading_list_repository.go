@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"dbapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ReadingListRepository struct {
+	*BaseRepository
+}
+
+func NewReadingListRepository(db *gorm.DB) *ReadingListRepository {
+	return &ReadingListRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *ReadingListRepository) WithContext(ctx context.Context) *ReadingListRepository {
+	if r == nil {
+		return nil
+	}
+	return NewReadingListRepository(r.db.WithContext(ctx))
+}
+
+func (r *ReadingListRepository) Create(list *model.ReadingList) error {
+	return r.db.Create(list).Error
+}
+
+func (r *ReadingListRepository) GetByID(id uint64) (*model.ReadingList, error) {
+	var list model.ReadingList
+	err := r.db.Preload("User").First(&list, id).Error
+	return &list, err
+}
+
+// GetByName 查询用户指定名称的阅读列表，不存在时返回 nil
+func (r *ReadingListRepository) GetByName(userID uint64, name string) (*model.ReadingList, error) {
+	var list model.ReadingList
+	err := r.db.Where("user_id = ? AND name = ?", userID, name).First(&list).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &list, err
+}
+
+// ListByUser 按创建时间查询用户的阅读列表，publicOnly 为 true 时只返回公开的列表
+func (r *ReadingListRepository) ListByUser(userID uint64, publicOnly bool) ([]model.ReadingList, error) {
+	var lists []model.ReadingList
+	query := r.db.Preload("User").Where("user_id = ?", userID)
+	if publicOnly {
+		query = query.Where("is_public = ?", true)
+	}
+	err := query.Order("created_at ASC, id ASC").Find(&lists).Error
+	return lists, err
+}
+
+func (r *ReadingListRepository) Update(list *model.ReadingList) error {
+	return r.db.Omit("User").Save(list).Error
+}
+
+// Delete 删除阅读列表，列表中的收藏保留，移出列表
+func (r *ReadingListRepository) Delete(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Bookmark{}).Where("reading_list_id = ?", id).
+			Updates(map[string]interface{}{"reading_list_id": nil, "position": 0}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.ReadingList{}, id).Error
+	})
+}
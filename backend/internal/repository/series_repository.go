@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"dbapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type SeriesRepository struct {
+	*BaseRepository
+}
+
+func NewSeriesRepository(db *gorm.DB) *SeriesRepository {
+	return &SeriesRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *SeriesRepository) WithContext(ctx context.Context) *SeriesRepository {
+	if r == nil {
+		return nil
+	}
+	return NewSeriesRepository(r.db.WithContext(ctx))
+}
+
+func (r *SeriesRepository) Create(series *model.Series) error {
+	return r.db.Create(series).Error
+}
+
+func (r *SeriesRepository) GetByID(id uint64) (*model.Series, error) {
+	var series model.Series
+	err := r.db.Preload("Author").First(&series, id).Error
+	return &series, err
+}
+
+func (r *SeriesRepository) Update(series *model.Series) error {
+	return r.db.Omit("Author").Save(series).Error
+}
+
+// Delete 删除系列及其文章顺序，文章本身不受影响
+func (r *SeriesRepository) Delete(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("series_id = ?", id).Delete(&model.SeriesArticle{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Series{}, id).Error
+	})
+}
+
+// List 按创建时间倒序查询系列，authorID 为 0 时查询所有作者的系列
+func (r *SeriesRepository) List(authorID uint64, page, pageSize int) ([]model.Series, int64, error) {
+	var series []model.Series
+	var total int64
+
+	query := r.db.Model(&model.Series{})
+	if authorID > 0 {
+		query = query.Where("author_id = ?", authorID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Author").
+		Order("created_at DESC, id DESC").
+		Scopes(r.Paginate(page, pageSize)).
+		Find(&series).Error
+
+	return series, total, err
+}
+
+// Articles 按顺序查询系列中未删除的文章，只加载列表和导航需要的字段
+func (r *SeriesRepository) Articles(seriesID uint64) ([]model.Article, error) {
+	var articles []model.Article
+	err := r.db.Model(&model.Article{}).
+		Select("articles.id, articles.title, articles.slug, articles.summary, articles.cover_image_url, "+
+			"articles.author_id, articles.status, articles.visibility, articles.published_at").
+		Joins("JOIN series_articles ON series_articles.article_id = articles.id").
+		Where("series_articles.series_id = ?", seriesID).
+		Order("series_articles.position ASC, articles.id ASC").
+		Find(&articles).Error
+	return articles, err
+}
+
+// GetByArticle 查询文章所属系列的成员记录，文章不属于任何系列时返回 nil
+func (r *SeriesRepository) GetByArticle(articleID uint64) (*model.SeriesArticle, error) {
+	var member model.SeriesArticle
+	err := r.db.Where("article_id = ?", articleID).First(&member).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// SeriesOfArticles 查询文章各自所属的系列，返回 文章ID -> 系列ID，不属于任何系列的文章不在结果中
+func (r *SeriesRepository) SeriesOfArticles(articleIDs []uint64) (map[uint64]uint64, error) {
+	result := make(map[uint64]uint64)
+	if len(articleIDs) == 0 {
+		return result, nil
+	}
+
+	var members []model.SeriesArticle
+	if err := r.db.Where("article_id IN ?", articleIDs).Find(&members).Error; err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		result[m.ArticleID] = m.SeriesID
+	}
+	return result, nil
+}
+
+// SetArticles 在一个事务中把系列中未删除的文章替换为 articleIDs，并按其顺序设置位置。
+// 回收站中的文章保留在系列中，按原有顺序排在 articleIDs 之后，恢复后回到系列中且位置不会与其他文章重复。
+func (r *SeriesRepository) SetArticles(seriesID uint64, articleIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("series_id = ? AND article_id NOT IN (?)", seriesID,
+			tx.Unscoped().Model(&model.Article{}).Select("id").Where("deleted_at IS NOT NULL")).
+			Delete(&model.SeriesArticle{}).Error
+		if err != nil {
+			return err
+		}
+
+		var kept []model.SeriesArticle
+		if err := tx.Where("series_id = ?", seriesID).Order("position ASC").Find(&kept).Error; err != nil {
+			return err
+		}
+		for i, member := range kept {
+			if err := tx.Model(&model.SeriesArticle{}).
+				Where("series_id = ? AND article_id = ?", seriesID, member.ArticleID).
+				UpdateColumn("position", len(articleIDs)+i+1).Error; err != nil {
+				return err
+			}
+		}
+		if len(articleIDs) == 0 {
+			return nil
+		}
+
+		members := make([]model.SeriesArticle, len(articleIDs))
+		for i, id := range articleIDs {
+			members[i] = model.SeriesArticle{SeriesID: seriesID, ArticleID: id, Position: i + 1}
+		}
+		return tx.Create(&members).Error
+	})
+}
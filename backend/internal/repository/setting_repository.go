@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"dbapp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SettingRepository struct {
+	*BaseRepository
+}
+
+func NewSettingRepository(db *gorm.DB) *SettingRepository {
+	return &SettingRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *SettingRepository) WithContext(ctx context.Context) *SettingRepository {
+	if r == nil {
+		return nil
+	}
+	return NewSettingRepository(r.db.WithContext(ctx))
+}
+
+// All 返回所有已保存的设置
+func (r *SettingRepository) All() ([]model.Setting, error) {
+	var settings []model.Setting
+	err := r.db.Find(&settings).Error
+	return settings, err
+}
+
+// Save 在同一事务中保存设置并写入修改记录，values 的值为JSON
+func (r *SettingRepository) Save(values map[string]string, userID uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []model.Setting
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		if err := tx.Where("key IN ?", keys).Find(&existing).Error; err != nil {
+			return err
+		}
+		old := make(map[string]string, len(existing))
+		for _, setting := range existing {
+			old[setting.Key] = setting.Value
+		}
+
+		now := time.Now()
+		for key, value := range values {
+			change := model.SettingChange{Key: key, NewValue: value, ChangedBy: userID}
+			if oldValue, ok := old[key]; ok {
+				if oldValue == value {
+					continue
+				}
+				change.OldValue = &oldValue
+			}
+
+			setting := model.Setting{Key: key, Value: value, UpdatedBy: &userID, UpdatedAt: now}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "key"}},
+				DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by", "updated_at"}),
+			}).Create(&setting).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&change).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListChanges 分页查询修改记录，key 为空时查询全部，按时间倒序
+func (r *SettingRepository) ListChanges(key string, page, pageSize int) ([]model.SettingChange, int64, error) {
+	var changes []model.SettingChange
+	var total int64
+
+	query := r.db.Model(&model.SettingChange{})
+	if key != "" {
+		query = query.Where("key = ?", key)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Preload("User").Order("created_at DESC, id DESC").
+		Scopes(r.Paginate(page, pageSize)).Find(&changes).Error
+	return changes, total, err
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"dbapp/internal/model"
 	"gorm.io/gorm"
 )
@@ -15,6 +16,14 @@ func NewTagRepository(db *gorm.DB) *TagRepository {
 	}
 }
 
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *TagRepository) WithContext(ctx context.Context) *TagRepository {
+	if r == nil {
+		return nil
+	}
+	return NewTagRepository(r.db.WithContext(ctx))
+}
+
 func (r *TagRepository) Create(tag *model.Tag) error {
 	return r.db.Create(tag).Error
 }
@@ -26,9 +35,7 @@ func (r *TagRepository) GetByID(id uint64) (*model.Tag, error) {
 		return &tag, err
 	}
 	// 动态计算文章数
-	var count int64
-	r.db.Table("article_tags").Where("tag_id = ?", tag.ID).Count(&count)
-	tag.ArticleCount = int(count)
+	tag.ArticleCount = int(countArticles(r.db, "article_tags", "tag_id", tag.ID))
 	return &tag, nil
 }
 
@@ -78,9 +85,7 @@ func (r *TagRepository) List(keyword string, sort string, order string, limit in
 
 	// 动态计算每个标签的文章数
 	for i := range tags {
-		var count int64
-		r.db.Table("article_tags").Where("tag_id = ?", tags[i].ID).Count(&count)
-		tags[i].ArticleCount = int(count)
+		tags[i].ArticleCount = int(countArticles(r.db, "article_tags", "tag_id", tags[i].ID))
 	}
 
 	return tags, nil
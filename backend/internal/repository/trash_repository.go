@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// deletionTime 软删除使用的时间。级联删除的记录使用同一时间，恢复时按该时间找回一起删除的记录；
+// 截断到微秒并使用UTC，保证写入数据库后读回的值与写入的值相等
+func deletionTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// TrashRepository 彻底删除回收站中超过保留时间的记录
+type TrashRepository struct {
+	*BaseRepository
+}
+
+func NewTrashRepository(db *gorm.DB) *TrashRepository {
+	return &TrashRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// purgeStep 彻底删除的一步，按顺序执行，先删除引用方再删除被引用的记录
+type purgeStep struct {
+	name string
+	sql  string
+}
+
+// 删除时间早于 ? 的文章连同其所有评论、点赞、分类标签关联、图片记录、系列中的位置和收藏一起删除。
+// 单独删除的评论只删除没有回复的，有回复的评论等回复被删除后在之后的清理中删除。
+var purgeSteps = []purgeStep{
+	{"likes", "DELETE FROM likes WHERE target_type = 'comment' AND target_id IN (SELECT id FROM comments WHERE article_id IN (SELECT id FROM articles WHERE deleted_at < ?))"},
+	{"likes", "DELETE FROM likes WHERE target_type = 'article' AND target_id IN (SELECT id FROM articles WHERE deleted_at < ?)"},
+	{"comments", "DELETE FROM comments WHERE article_id IN (SELECT id FROM articles WHERE deleted_at < ?)"},
+	{"", "DELETE FROM article_categories WHERE article_id IN (SELECT id FROM articles WHERE deleted_at < ?)"},
+	{"", "DELETE FROM article_tags WHERE article_id IN (SELECT id FROM articles WHERE deleted_at < ?)"},
+	{"", "DELETE FROM article_images WHERE article_id IN (SELECT id FROM articles WHERE deleted_at < ?)"},
+	{"", "DELETE FROM series_articles WHERE article_id IN (SELECT id FROM articles WHERE deleted_at < ?)"},
+	{"", "DELETE FROM bookmarks WHERE article_id IN (SELECT id FROM articles WHERE deleted_at < ?)"},
+	{"articles", "DELETE FROM articles WHERE deleted_at < ?"},
+	{"likes", "DELETE FROM likes WHERE target_type = 'comment' AND target_id IN (SELECT id FROM comments WHERE deleted_at < ? AND NOT EXISTS (SELECT 1 FROM comments AS replies WHERE replies.parent_id = comments.id))"},
+	{"comments", "DELETE FROM comments WHERE deleted_at < ? AND NOT EXISTS (SELECT 1 FROM comments AS replies WHERE replies.parent_id = comments.id)"},
+	{"likes", "DELETE FROM likes WHERE deleted_at < ?"},
+}
+
+// Purge 在一个事务中彻底删除删除时间早于 before 的文章、评论和点赞，返回各类记录删除的行数
+func (r *TrashRepository) Purge(before time.Time) (map[string]int64, error) {
+	purged := make(map[string]int64)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, step := range purgeSteps {
+			result := tx.Exec(step.sql, before)
+			if result.Error != nil {
+				return result.Error
+			}
+			if step.name != "" {
+				purged[step.name] += result.RowsAffected
+			}
+		}
+		return nil
+	})
+	return purged, err
+}
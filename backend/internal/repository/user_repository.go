@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"dbapp/internal/model"
 	"time"
 
@@ -17,6 +18,14 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	}
 }
 
+// WithContext 返回使用 ctx 执行SQL的副本，SQL的追踪span会挂在 ctx 中的请求span下
+func (r *UserRepository) WithContext(ctx context.Context) *UserRepository {
+	if r == nil {
+		return nil
+	}
+	return NewUserRepository(r.db.WithContext(ctx))
+}
+
 func (r *UserRepository) Create(user *model.User) error {
 	return r.db.Create(user).Error
 }
@@ -54,3 +63,94 @@ func (r *UserRepository) UpdateLastLogin(userID uint64) error {
 		Update("last_login_at", time.Now()).Error
 }
 
+
+// CountByRole 统计指定角色的用户数
+func (r *UserRepository) CountByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
+
+// DeleteWithArticles 软删除用户，并在同一事务中级联软删除用户的文章及文章的评论和点赞。
+// 级联删除的记录与用户使用相同的删除时间，Restore 据此只恢复与用户一起删除的文章。
+// 返回被删除的文章ID；用户不存在时返回 false
+func (r *UserRepository) DeleteWithArticles(id uint64) ([]uint64, bool, error) {
+	var articleIDs []uint64
+	deleted := false
+	now := deletionTime()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).Where("id = ?", id).UpdateColumn("deleted_at", now)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = true
+
+		if err := tx.Model(&model.Article{}).Where("author_id = ?", id).Pluck("id", &articleIDs).Error; err != nil {
+			return err
+		}
+		if len(articleIDs) == 0 {
+			return nil
+		}
+		if err := tx.Model(&model.Article{}).Where("id IN ?", articleIDs).UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return deleteArticleDependents(tx, articleIDs, now)
+	})
+	return articleIDs, deleted, err
+}
+
+// GetDeleted 查询已删除的用户
+func (r *UserRepository) GetDeleted(id uint64) (*model.User, error) {
+	var user model.User
+	err := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error
+	return &user, err
+}
+
+// ListDeleted 按删除时间倒序分页查询已删除的用户
+func (r *UserRepository) ListDeleted(page, pageSize int) ([]model.User, int64, error) {
+	var users []model.User
+	var total int64
+
+	query := r.db.Unscoped().Model(&model.User{}).Where("deleted_at IS NOT NULL")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("deleted_at DESC").
+		Scopes(r.Paginate(page, pageSize)).
+		Find(&users).Error
+
+	return users, total, err
+}
+
+// Restore 恢复已删除的用户，以及与用户一起删除的文章和文章的评论、点赞。
+// 返回恢复的文章ID；用户已被恢复时返回 false
+func (r *UserRepository) Restore(user *model.User) ([]uint64, bool, error) {
+	var articleIDs []uint64
+	restored := false
+	deletedAt := user.DeletedAt.Time
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&model.User{}).
+			Where("id = ? AND deleted_at = ?", user.ID, deletedAt).
+			UpdateColumn("deleted_at", nil)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		restored = true
+
+		if err := tx.Unscoped().Model(&model.Article{}).
+			Where("author_id = ? AND deleted_at = ?", user.ID, deletedAt).
+			Pluck("id", &articleIDs).Error; err != nil {
+			return err
+		}
+		if len(articleIDs) == 0 {
+			return nil
+		}
+		if err := tx.Unscoped().Model(&model.Article{}).Where("id IN ?", articleIDs).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return restoreArticleDependents(tx, articleIDs, deletedAt)
+	})
+	return articleIDs, restored, err
+}
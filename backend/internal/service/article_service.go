@@ -1,11 +1,16 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"dbapp/internal/dto/request"
 	"dbapp/internal/dto/response"
 	"dbapp/internal/errors"
 	"dbapp/internal/model"
 	"dbapp/internal/repository"
+	"dbapp/pkg/cache"
 	"github.com/gosimple/slug"
 	"regexp"
 	"strings"
@@ -16,23 +21,59 @@ type ArticleService struct {
 	articleRepo     *repository.ArticleRepository
 	userRepo        *repository.UserRepository
 	likeRepo        *repository.LikeRepository
+	bookmarkRepo    *repository.BookmarkRepository
 	articleImageRepo *repository.ArticleImageRepository
+	seriesRepo      *repository.SeriesRepository
+	cache           cache.Cache
+	viewCounter     *ViewCounter
+	settings        *SettingService
+	audit           *AuditService
 }
 
-func NewArticleService(
-	articleRepo *repository.ArticleRepository,
-	userRepo *repository.UserRepository,
-	likeRepo *repository.LikeRepository,
-	articleImageRepo *repository.ArticleImageRepository,
-) *ArticleService {
+// ArticleServiceDeps ArticleService 的依赖，ArticleRepo 和 Cache 必须提供，其余为 nil 时对应功能关闭
+type ArticleServiceDeps struct {
+	ArticleRepo      *repository.ArticleRepository
+	UserRepo         *repository.UserRepository
+	LikeRepo         *repository.LikeRepository         // nil 时不标记当前用户是否点赞
+	BookmarkRepo     *repository.BookmarkRepository     // nil 时不标记当前用户是否收藏
+	ArticleImageRepo *repository.ArticleImageRepository // nil 时不记录文章引用的图片
+	SeriesRepo       *repository.SeriesRepository       // nil 时文章详情不返回系列导航
+	Cache            cache.Cache
+	ViewCounter      *ViewCounter    // nil 时不统计浏览量
+	Settings         *SettingService // nil 时未指定状态的新文章为草稿
+	Audit            *AuditService   // nil 时不记录审计日志
+}
+
+func NewArticleService(deps ArticleServiceDeps) *ArticleService {
 	return &ArticleService{
-		articleRepo:      articleRepo,
-		userRepo:         userRepo,
-		likeRepo:         likeRepo,
-		articleImageRepo: articleImageRepo,
+		articleRepo:      deps.ArticleRepo,
+		userRepo:         deps.UserRepo,
+		likeRepo:         deps.LikeRepo,
+		bookmarkRepo:     deps.BookmarkRepo,
+		articleImageRepo: deps.ArticleImageRepo,
+		seriesRepo:       deps.SeriesRepo,
+		cache:            deps.Cache,
+		viewCounter:      deps.ViewCounter,
+		settings:         deps.Settings,
+		audit:            deps.Audit,
 	}
 }
 
+// WithContext 返回在 ctx 下访问数据库和缓存的副本，用于把SQL和Redis的追踪span关联到当前请求
+func (s *ArticleService) WithContext(ctx context.Context) *ArticleService {
+	clone := *s
+	clone.articleRepo = s.articleRepo.WithContext(ctx)
+	clone.userRepo = s.userRepo.WithContext(ctx)
+	clone.likeRepo = s.likeRepo.WithContext(ctx)
+	clone.bookmarkRepo = s.bookmarkRepo.WithContext(ctx)
+	clone.articleImageRepo = s.articleImageRepo.WithContext(ctx)
+	clone.seriesRepo = s.seriesRepo.WithContext(ctx)
+	clone.cache = cache.WithContext(s.cache, ctx)
+	clone.settings = s.settings.WithContext(ctx)
+	clone.audit = s.audit.WithContext(ctx)
+	return &clone
+}
+
 func (s *ArticleService) Create(req *request.CreateArticleRequest, userID uint64) (*response.ArticleResponse, error) {
 	// 生成slug
 	articleSlug := slug.Make(req.Title)
@@ -44,6 +85,15 @@ func (s *ArticleService) Create(req *request.CreateArticleRequest, userID uint64
 		articleSlug = articleSlug + "-" + time.Now().Format("20060102150405")
 	}
 
+	// 未指定状态时使用站点设置中的默认状态
+	status := req.Status
+	if status == "" {
+		status = "draft"
+		if s.settings != nil {
+			status = s.settings.String(SettingDefaultArticleStatus)
+		}
+	}
+
 	now := time.Now()
 	article := &model.Article{
 		Title:   req.Title,
@@ -52,128 +102,137 @@ func (s *ArticleService) Create(req *request.CreateArticleRequest, userID uint64
 		Summary: req.Summary,
 		CoverImageURL: req.CoverImageURL,
 		AuthorID: userID,
-		Status:  req.Status,
+		Status:  status,
+		Visibility: model.VisibilityPublic,
+	}
+	if req.Visibility != "" {
+		if err := setVisibility(article, req.Visibility, false); err != nil {
+			return nil, err
+		}
 	}
 
-	if req.Status == "published" {
+	if status == "published" {
 		article.PublishedAt = &now
 	}
 
 	if err := s.articleRepo.Create(article); err != nil {
-		return nil, errors.NewInternalError("创建文章失败")
+		return nil, errors.NewInternalError("创建文章失败").WithCause(err)
 	}
+	articlesCreatedTotal.WithLabelValues(article.Status).Inc()
+	s.audit.Record(AuditActionCreate, AuditTargetArticle, article.ID, nil, article)
 
-	// 更新分类关联
-	if len(req.CategoryIDs) > 0 {
-		if err := s.articleRepo.UpdateCategories(article.ID, req.CategoryIDs); err != nil {
-			// 记录错误但不影响文章创建
-		}
-	}
-
-	// 更新标签关联
-	if len(req.TagIDs) > 0 {
-		if err := s.articleRepo.UpdateTags(article.ID, req.TagIDs); err != nil {
-			// 记录错误但不影响文章创建
-		}
-	}
+	// 更新分类和标签关联
+	assocErr := s.saveAssociations(article.ID, req.CategoryIDs, req.TagIDs)
 
 	// 提取文章内容中的图片并保存到数据库
 	s.extractAndSaveImages(article.ID, article.Content)
 
+	// 分类和标签的文章数发生变化
+	invalidateCategoryTree(s.cache)
+	invalidateTagLists(s.cache)
+	if assocErr != nil {
+		return nil, assocErr
+	}
+
 	// 加载关联数据
-	article, _ = s.articleRepo.GetByID(article.ID)
-	return s.toResponse(article, userID), nil
+	article, err := s.articleRepo.GetByID(article.ID)
+	if err != nil {
+		return nil, errors.NewInternalError("查询文章失败").WithCause(err)
+	}
+	return toArticleResponse(article), nil
 }
 
-func (s *ArticleService) GetByID(id uint64, userID uint64) (*response.ArticleResponse, error) {
-	article, err := s.articleRepo.GetByID(id)
+// GetByID 获取文章详情。文章对当前用户不可见时返回不存在，不暴露文章是否存在；
+// shareToken 为不公开文章分享链接中的令牌。
+func (s *ArticleService) GetByID(id uint64, userID uint64, role string, shareToken string) (*response.ArticleResponse, error) {
+	// 缓存中的文章详情与用户无关，点赞、收藏状态在读取后单独查询
+	var cached response.ArticleResponse
+	err := cache.Remember(s.cache, articleCacheKey(id), 0, &cached, func() (interface{}, error) {
+		article, err := s.articleRepo.GetByID(id)
+		if err != nil {
+			return nil, errors.NewNotFoundError("文章不存在")
+		}
+		return toArticleResponse(article), nil
+	})
 	if err != nil {
+		return nil, err
+	}
+
+	resp := &cached
+	if !canViewArticle(resp, userID, role, shareToken) {
 		return nil, errors.NewNotFoundError("文章不存在")
 	}
+	hideShareToken(resp, userID, role)
 
-	// 增加浏览次数
-	go s.articleRepo.IncrementViewCount(id)
+	markUserState(s.likeRepo, s.bookmarkRepo, userID, []*response.ArticleResponse{resp})
 
-	resp := s.toResponse(article, userID)
-	
-	// 检查当前用户是否点赞
-	if userID > 0 && s.likeRepo != nil {
-		isLiked, _ := s.likeRepo.IsLikedByUser(userID, "article", id)
-		resp.IsLiked = isLiked
+	// 系列导航按当前用户的可见范围计算，不放入缓存
+	if nav, err := seriesNavigation(s.seriesRepo, id, userID, role); err == nil {
+		resp.Series = nav
 	}
 
 	return resp, nil
 }
 
-func (s *ArticleService) List(req *request.ListArticleRequest, userID uint64) (*response.ArticleListResponse, error) {
-	if req.Page <= 0 {
-		req.Page = 1
-	}
-	if req.PageSize <= 0 {
-		req.PageSize = 20
+// RecordView 记录一次文章浏览，由浏览计数器批量写入数据库
+func (s *ArticleService) RecordView(id uint64, userID uint64, ip string, userAgent string) {
+	if s.viewCounter != nil {
+		s.viewCounter.Record(id, userID, ip, userAgent)
 	}
+}
 
-	conditions := make(map[string]interface{})
-	if req.Status != "" {
-		conditions["status"] = req.Status
-	} else {
-		// 如果用户已登录，显示所有状态的文章；否则只显示已发布的
-		if userID > 0 {
-			// 已登录用户可以看到所有文章（包括自己的草稿）
-			// 不设置status条件，显示所有状态
-		} else {
-			conditions["status"] = "published" // 未登录用户只显示已发布的
-		}
-	}
+// List 查询文章列表，只返回当前用户可见的文章
+func (s *ArticleService) List(req *request.ListArticleRequest, userID uint64, role string) (*response.ArticleListResponse, error) {
+	page, err := pageQuery(req.Page, req.PageSize, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.ArticleFilter{
+		Status:                 req.Status,
+		AuthorID:               req.AuthorID,
+		Keyword:                req.Keyword,
+		CategoryIDs:            req.CategoryIDs,
+		CategoryMatch:          req.CategoryMatch,
+		IncludeChildCategories: req.IncludeChildren,
+		TagIDs:                 req.TagIDs,
+		TagMatch:               req.TagMatch,
+		IsFeatured:             req.Featured,
+		IsLocked:               req.Locked,
+		Viewer:                 &repository.ArticleViewer{UserID: userID, Privileged: model.HasRole(role, model.RoleEditor)},
+		Sort:                   req.Sort,
+		Desc:                   !strings.EqualFold(req.Order, "asc"),
+	}
+	// 兼容单个分类、标签的旧参数
 	if req.CategoryID > 0 {
-		conditions["category_id"] = req.CategoryID
+		filter.CategoryIDs = append(filter.CategoryIDs, req.CategoryID)
 	}
 	if req.TagID > 0 {
-		conditions["tag_id"] = req.TagID
-	}
-	if req.AuthorID > 0 {
-		conditions["author_id"] = req.AuthorID
+		filter.TagIDs = append(filter.TagIDs, req.TagID)
 	}
-	if req.Keyword != "" {
-		conditions["keyword"] = req.Keyword
+	if filter.CreatedFrom, filter.CreatedTo, err = parseTimeRange(req.CreatedFrom, req.CreatedTo); err != nil {
+		return nil, err
 	}
-	if req.Sort != "" {
-		conditions["sort"] = req.Sort
-	} else {
-		conditions["sort"] = "created_at"
-	}
-	if req.Order != "" {
-		conditions["order"] = req.Order
-	} else {
-		conditions["order"] = "DESC"
+	if filter.PublishedFrom, filter.PublishedTo, err = parseTimeRange(req.PublishedFrom, req.PublishedTo); err != nil {
+		return nil, err
 	}
 
-	articles, total, err := s.articleRepo.List(req.Page, req.PageSize, conditions)
+	articles, total, next, err := s.articleRepo.List(filter, page)
 	if err != nil {
-		return nil, errors.NewInternalError("查询文章列表失败")
+		return nil, listError(err, "查询文章列表失败")
 	}
 
 	items := make([]*response.ArticleResponse, len(articles))
-	for i, article := range articles {
-		resp := s.toResponse(&article, userID)
-		// 检查当前用户是否点赞
-		if userID > 0 && s.likeRepo != nil {
-			isLiked, _ := s.likeRepo.IsLikedByUser(userID, "article", article.ID)
-			resp.IsLiked = isLiked
-		}
+	for i := range articles {
+		resp := toArticleResponse(&articles[i])
+		hideShareToken(resp, userID, role)
 		items[i] = resp
 	}
-
-	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+	markUserState(s.likeRepo, s.bookmarkRepo, userID, items)
 
 	return &response.ArticleListResponse{
-		Items: items,
-		Pagination: response.Pagination{
-			Page:       req.Page,
-			PageSize:   req.PageSize,
-			Total:      total,
-			TotalPages: totalPages,
-		},
+		Items:      items,
+		Pagination: toPagination(page, total, next),
 	}, nil
 }
 
@@ -187,6 +246,7 @@ func (s *ArticleService) Update(id uint64, req *request.UpdateArticleRequest, us
 	if article.AuthorID != userID {
 		return nil, errors.NewForbiddenError("无权限修改此文章")
 	}
+	before := *article
 
 	// 更新字段
 	if req.Title != "" {
@@ -212,30 +272,53 @@ func (s *ArticleService) Update(id uint64, req *request.UpdateArticleRequest, us
 		}
 	}
 
+	if req.Visibility != "" || req.ResetShareToken {
+		visibility := req.Visibility
+		if visibility == "" {
+			visibility = article.Visibility
+		}
+		if err := setVisibility(article, visibility, req.ResetShareToken); err != nil {
+			return nil, err
+		}
+	}
+
 	article.EditCount++
 	editorID := userID
 	article.EditorID = &editorID
 
 	if err := s.articleRepo.Update(article); err != nil {
-		return nil, errors.NewInternalError("更新文章失败")
+		return nil, errors.NewInternalError("更新文章失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionUpdate, AuditTargetArticle, id, &before, article)
 
-	// 更新分类关联（即使是空数组也要更新，表示清除所有分类）
-	if req.CategoryIDs != nil {
-		if err := s.articleRepo.UpdateCategories(article.ID, req.CategoryIDs); err != nil {
-			// 记录错误但不影响文章更新
-		}
+	// 更新分类和标签关联（即使是空数组也要更新，表示清除所有分类、标签）
+	assocErr := s.saveAssociations(article.ID, req.CategoryIDs, req.TagIDs)
+
+	s.invalidateCache(id)
+	if assocErr != nil {
+		return nil, assocErr
 	}
 
-	// 更新标签关联（即使是空数组也要更新，表示清除所有标签）
-	if req.TagIDs != nil {
-		if err := s.articleRepo.UpdateTags(article.ID, req.TagIDs); err != nil {
-			// 记录错误但不影响文章更新
-		}
+	article, err = s.articleRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.NewInternalError("查询文章失败").WithCause(err)
 	}
+	return toArticleResponse(article), nil
+}
 
-	article, _ = s.articleRepo.GetByID(id)
-	return s.toResponse(article, userID), nil
+// saveAssociations 保存文章的分类和标签关联，参数为 nil 时不修改，空切片表示清除
+func (s *ArticleService) saveAssociations(id uint64, categoryIDs, tagIDs []uint64) error {
+	if categoryIDs != nil {
+		if err := s.articleRepo.UpdateCategories(id, categoryIDs); err != nil {
+			return errors.NewInternalError("更新文章分类失败").WithCause(err)
+		}
+	}
+	if tagIDs != nil {
+		if err := s.articleRepo.UpdateTags(id, tagIDs); err != nil {
+			return errors.NewInternalError("更新文章标签失败").WithCause(err)
+		}
+	}
+	return nil
 }
 
 func (s *ArticleService) Delete(id uint64, userID uint64) error {
@@ -249,14 +332,25 @@ func (s *ArticleService) Delete(id uint64, userID uint64) error {
 		return errors.NewForbiddenError("无权限删除此文章")
 	}
 
-	if err := s.articleRepo.Delete(id); err != nil {
-		return errors.NewInternalError("删除文章失败")
+	// 评论和点赞随文章一起移入回收站
+	if err := s.articleRepo.DeleteWithDependents(id); err != nil {
+		return errors.NewInternalError("删除文章失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionDelete, AuditTargetArticle, id, article, nil)
+
+	s.invalidateCache(id)
 
 	return nil
 }
 
-func (s *ArticleService) toResponse(article *model.Article, userID uint64) *response.ArticleResponse {
+// invalidateCache 文章变化后清除文章详情及依赖文章数的分类树、标签列表缓存
+func (s *ArticleService) invalidateCache(id uint64) {
+	invalidateArticle(s.cache, id)
+	invalidateCategoryTree(s.cache)
+	invalidateTagLists(s.cache)
+}
+
+func toArticleResponse(article *model.Article) *response.ArticleResponse {
 	author := &response.UserResponse{
 		ID:        article.Author.ID,
 		Username:  article.Author.Username,
@@ -309,12 +403,102 @@ func (s *ArticleService) toResponse(article *model.Article, userID uint64) *resp
 		CommentCount:  article.CommentCount,
 		IsFeatured:    article.IsFeatured,
 		Status:        article.Status,
+		Visibility:    article.Visibility,
+		ShareToken:    article.ShareToken,
 		PublishedAt:   article.PublishedAt,
 		CreatedAt:     article.CreatedAt,
 		UpdatedAt:     article.UpdatedAt,
 	}
 }
 
+// markUserState 设置当前用户对这些文章的点赞、收藏状态，各用一次查询查出
+func markUserState(likeRepo *repository.LikeRepository, bookmarkRepo *repository.BookmarkRepository, userID uint64, articles []*response.ArticleResponse) {
+	if userID == 0 || len(articles) == 0 {
+		return
+	}
+	ids := make([]uint64, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+
+	var liked, bookmarked map[uint64]bool
+	if likeRepo != nil {
+		liked, _ = likeRepo.LikedTargets(userID, "article", ids)
+	}
+	if bookmarkRepo != nil {
+		bookmarked, _ = bookmarkRepo.BookmarkedArticles(userID, ids)
+	}
+	for _, article := range articles {
+		article.IsLiked = liked[article.ID]
+		article.IsBookmarked = bookmarked[article.ID]
+	}
+}
+
+// canViewArticle 判断用户能否查看文章详情
+func canViewArticle(article *response.ArticleResponse, userID uint64, role string, shareToken string) bool {
+	var authorID uint64
+	if article.Author != nil {
+		authorID = article.Author.ID
+	}
+	return articleViewable(authorID, article.Status, article.Visibility, article.ShareToken, userID, role, shareToken)
+}
+
+// articleViewable 判断用户能否查看文章及其评论、点赞等附属内容：
+// 能在列表中看到的文章都可查看，不公开的已发布文章还可凭分享令牌查看
+func articleViewable(authorID uint64, status, visibility, token string, userID uint64, role string, shareToken string) bool {
+	if articleListed(authorID, status, visibility, userID, role) {
+		return true
+	}
+	return status == "published" && visibility == model.VisibilityUnlisted &&
+		shareToken != "" && token != "" &&
+		subtle.ConstantTimeCompare([]byte(shareToken), []byte(token)) == 1
+}
+
+// articleListed 判断文章能否出现在用户看到的列表中，与 ArticleRepository 列表的可见性条件一致
+func articleListed(authorID uint64, status, visibility string, userID uint64, role string) bool {
+	if (userID > 0 && authorID == userID) || model.HasRole(role, model.RoleEditor) {
+		return true
+	}
+	if status != "published" {
+		return false
+	}
+
+	switch visibility {
+	case model.VisibilityPublic:
+		return true
+	case model.VisibilityMembers:
+		return userID > 0
+	default:
+		return false
+	}
+}
+
+// hideShareToken 分享令牌只返回给作者和编辑
+func hideShareToken(article *response.ArticleResponse, userID uint64, role string) {
+	if model.HasRole(role, model.RoleEditor) || (userID > 0 && article.Author != nil && article.Author.ID == userID) {
+		return
+	}
+	article.ShareToken = ""
+}
+
+// setVisibility 设置文章可见范围。改为不公开时生成分享令牌，resetToken 为 true 时重新生成使旧链接失效；
+// 改为其他可见范围时清除令牌。
+func setVisibility(article *model.Article, visibility string, resetToken bool) error {
+	article.Visibility = visibility
+	if visibility != model.VisibilityUnlisted {
+		article.ShareToken = ""
+		return nil
+	}
+	if article.ShareToken == "" || resetToken {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return errors.NewInternalError("生成分享链接失败").WithCause(err)
+		}
+		article.ShareToken = hex.EncodeToString(buf)
+	}
+	return nil
+}
+
 // extractAndSaveImages 从Markdown内容中提取图片URL并保存到数据库
 func (s *ArticleService) extractAndSaveImages(articleID uint64, content string) {
 	if s.articleImageRepo == nil || content == "" {
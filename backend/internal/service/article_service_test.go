@@ -4,7 +4,9 @@ import (
 	"dbapp/internal/dto/request"
 	"dbapp/internal/repository"
 	"dbapp/internal/test"
+	"dbapp/pkg/cache"
 	"testing"
+	"time"
 )
 
 func TestArticleService_Create(t *testing.T) {
@@ -15,7 +17,13 @@ func TestArticleService_Create(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 
 	// 创建测试用户
 	user := test.CreateTestUser(db, "testuser", "test@example.com")
@@ -41,6 +49,28 @@ func TestArticleService_Create(t *testing.T) {
 	}
 }
 
+func TestArticleService_AssociationErrors(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      repository.NewArticleRepository(db),
+		UserRepo:         repository.NewUserRepository(db),
+		LikeRepo:         repository.NewLikeRepository(db),
+		ArticleImageRepo: repository.NewArticleImageRepository(db),
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+
+	// 标签关联保存失败时返回错误，不再静默忽略
+	if err := db.Migrator().DropTable("article_tags"); err != nil {
+		t.Fatalf("删除关联表失败: %v", err)
+	}
+	if _, err := articleService.Create(&request.CreateArticleRequest{Title: "新文章", Content: "内容", TagIDs: []uint64{1}}, user.ID); !isAppError(err, 500) {
+		t.Errorf("创建文章时保存标签失败应返回500, 得到 %v", err)
+	}
+}
+
 func TestArticleService_GetByID(t *testing.T) {
 	db := test.SetupTestDB(t)
 	defer test.TeardownTestDB(db)
@@ -49,14 +79,20 @@ func TestArticleService_GetByID(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 
 	// 创建测试数据
 	user := test.CreateTestUser(db, "testuser", "test@example.com")
 	article := test.CreateTestArticle(db, user.ID, "测试文章")
 
 	// 查询文章
-	found, err := articleService.GetByID(article.ID, user.ID)
+	found, err := articleService.GetByID(article.ID, user.ID, "", "")
 	if err != nil {
 		t.Fatalf("查询文章失败: %v", err)
 	}
@@ -74,10 +110,16 @@ func TestArticleService_GetByID_NotFound(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 
 	// 查询不存在的文章
-	_, err := articleService.GetByID(99999, 1)
+	_, err := articleService.GetByID(99999, 1, "", "")
 	if err == nil {
 		t.Error("应该返回文章不存在的错误")
 	}
@@ -91,7 +133,13 @@ func TestArticleService_List(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 
 	// 创建测试数据
 	user := test.CreateTestUser(db, "testuser", "test@example.com")
@@ -104,7 +152,7 @@ func TestArticleService_List(t *testing.T) {
 		PageSize: 10,
 	}
 
-	result, err := articleService.List(req, user.ID)
+	result, err := articleService.List(req, user.ID, "")
 	if err != nil {
 		t.Fatalf("查询文章列表失败: %v", err)
 	}
@@ -126,7 +174,13 @@ func TestArticleService_ListWithPagination(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 
 	// 创建测试数据
 	user := test.CreateTestUser(db, "testuser", "test@example.com")
@@ -139,7 +193,7 @@ func TestArticleService_ListWithPagination(t *testing.T) {
 		PageSize: 10,
 	}
 
-	result, err := articleService.List(req, user.ID)
+	result, err := articleService.List(req, user.ID, "")
 	if err != nil {
 		t.Fatalf("查询文章列表失败: %v", err)
 	}
@@ -165,7 +219,13 @@ func TestArticleService_Update(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 
 	// 创建测试数据
 	user := test.CreateTestUser(db, "testuser", "test@example.com")
@@ -194,7 +254,13 @@ func TestArticleService_Update_Unauthorized(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 
 	// 创建测试数据
 	author := test.CreateTestUser(db, "author", "author@example.com")
@@ -219,7 +285,13 @@ func TestArticleService_Delete(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 
 	// 创建测试数据
 	user := test.CreateTestUser(db, "testuser", "test@example.com")
@@ -231,7 +303,7 @@ func TestArticleService_Delete(t *testing.T) {
 	}
 
 	// 验证删除
-	_, err = articleService.GetByID(article.ID, user.ID)
+	_, err = articleService.GetByID(article.ID, user.ID, "", "")
 	if err == nil {
 		t.Error("文章应该已被删除")
 	}
@@ -245,7 +317,13 @@ func TestArticleService_Delete_Unauthorized(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	likeRepo := repository.NewLikeRepository(db)
 	articleImageRepo := repository.NewArticleImageRepository(db)
-	articleService := NewArticleService(articleRepo, userRepo, likeRepo, articleImageRepo)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
 
 	// 创建测试数据
 	author := test.CreateTestUser(db, "author", "author@example.com")
@@ -258,3 +336,174 @@ func TestArticleService_Delete_Unauthorized(t *testing.T) {
 	}
 }
 
+func TestArticleService_GetByID_CacheInvalidatedOnUpdate(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleRepo := repository.NewArticleRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	likeRepo := repository.NewLikeRepository(db)
+	articleImageRepo := repository.NewArticleImageRepository(db)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         userRepo,
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: articleImageRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
+
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "原始标题")
+
+	// 第一次读取写入缓存
+	if _, err := articleService.GetByID(article.ID, user.ID, "", ""); err != nil {
+		t.Fatalf("查询文章失败: %v", err)
+	}
+
+	if _, err := articleService.Update(article.ID, &request.UpdateArticleRequest{Title: "更新后的标题"}, user.ID); err != nil {
+		t.Fatalf("更新文章失败: %v", err)
+	}
+
+	found, err := articleService.GetByID(article.ID, user.ID, "", "")
+	if err != nil {
+		t.Fatalf("查询文章失败: %v", err)
+	}
+	if found.Title != "更新后的标题" {
+		t.Errorf("更新后缓存应失效, 得到标题 %s", found.Title)
+	}
+}
+
+func BenchmarkArticleService_List(b *testing.B) {
+	db := test.SetupTestDB(b)
+	defer test.TeardownTestDB(db)
+
+	likeRepo := repository.NewLikeRepository(db)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      repository.NewArticleRepository(db),
+		UserRepo:         repository.NewUserRepository(db),
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: repository.NewArticleImageRepository(db),
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
+
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	for i := 0; i < 20; i++ {
+		article := test.CreateTestArticle(db, user.ID, "测试文章")
+		if i%2 == 0 {
+			likeRepo.Toggle(user.ID, "article", article.ID)
+		}
+	}
+
+	req := &request.ListArticleRequest{Page: 1, PageSize: 20}
+	countQueries := test.CountQueries(db)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := articleService.List(req, user.ID, "")
+		if err != nil {
+			b.Fatalf("查询文章列表失败: %v", err)
+		}
+		if len(result.Items) != 20 {
+			b.Fatalf("期望 20 篇文章, 得到 %d", len(result.Items))
+		}
+	}
+	b.ReportMetric(float64(countQueries())/float64(b.N), "queries/op")
+}
+
+func TestArticleService_Visibility(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      repository.NewArticleRepository(db),
+		UserRepo:         repository.NewUserRepository(db),
+		LikeRepo:         repository.NewLikeRepository(db),
+		ArticleImageRepo: repository.NewArticleImageRepository(db),
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
+
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	reader := test.CreateTestUser(db, "reader", "reader@example.com")
+
+	create := func(title, status, visibility string) uint64 {
+		t.Helper()
+		article, err := articleService.Create(&request.CreateArticleRequest{
+			Title:      title,
+			Content:    "内容",
+			Status:     status,
+			Visibility: visibility,
+		}, author.ID)
+		if err != nil {
+			t.Fatalf("创建文章失败: %v", err)
+		}
+		return article.ID
+	}
+	public := create("公开", "published", "")
+	draft := create("草稿", "draft", "")
+	members := create("登录可见", "published", "members")
+	private := create("私有", "published", "private")
+	unlisted := create("不公开", "published", "unlisted")
+
+	// 列表中可见的文章
+	visible := func(userID uint64, role string) map[uint64]bool {
+		t.Helper()
+		result, err := articleService.List(&request.ListArticleRequest{PageSize: 20}, userID, role)
+		if err != nil {
+			t.Fatalf("查询文章列表失败: %v", err)
+		}
+		ids := make(map[uint64]bool)
+		for _, item := range result.Items {
+			ids[item.ID] = true
+			if item.ShareToken != "" && userID != author.ID && role == "" {
+				t.Errorf("文章 %d 的分享令牌不应返回给其他用户", item.ID)
+			}
+		}
+		return ids
+	}
+	if got := visible(0, ""); len(got) != 1 || !got[public] {
+		t.Errorf("未登录用户只能看到公开文章, 得到 %v", got)
+	}
+	if got := visible(reader.ID, "user"); len(got) != 2 || !got[public] || !got[members] {
+		t.Errorf("登录用户能看到公开和登录可见的文章, 得到 %v", got)
+	}
+	if got := visible(author.ID, "user"); len(got) != 5 {
+		t.Errorf("作者能看到自己的全部文章, 得到 %v", got)
+	}
+	if got := visible(reader.ID, "editor"); len(got) != 5 {
+		t.Errorf("编辑能看到全部文章, 得到 %v", got)
+	}
+
+	// 详情
+	canView := func(id, userID uint64, role, token string) bool {
+		_, err := articleService.GetByID(id, userID, role, token)
+		return err == nil
+	}
+	if canView(draft, reader.ID, "user", "") || !canView(draft, author.ID, "user", "") || !canView(draft, reader.ID, "admin", "") {
+		t.Error("草稿只有作者和编辑、管理员可见")
+	}
+	if canView(members, 0, "", "") || !canView(members, reader.ID, "user", "") {
+		t.Error("登录可见的文章只对登录用户可见")
+	}
+	if canView(private, reader.ID, "user", "") || !canView(private, author.ID, "user", "") {
+		t.Error("私有文章只有作者可见")
+	}
+
+	owned, _ := articleService.GetByID(unlisted, author.ID, "user", "")
+	if owned.ShareToken == "" {
+		t.Fatal("不公开的文章应生成分享令牌")
+	}
+	if canView(unlisted, reader.ID, "user", "") || canView(unlisted, 0, "", "wrong") || !canView(unlisted, 0, "", owned.ShareToken) {
+		t.Error("不公开的文章只能通过分享链接查看")
+	}
+	shared, _ := articleService.GetByID(unlisted, 0, "", owned.ShareToken)
+	if shared.ShareToken != "" {
+		t.Error("分享令牌不应返回给其他用户")
+	}
+
+	// 重新生成令牌后旧链接失效
+	if _, err := articleService.Update(unlisted, &request.UpdateArticleRequest{Status: "published", ResetShareToken: true}, author.ID); err != nil {
+		t.Fatalf("更新文章失败: %v", err)
+	}
+	if canView(unlisted, 0, "", owned.ShareToken) {
+		t.Error("重新生成令牌后旧链接应失效")
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"dbapp/internal/dto/request"
+	"dbapp/internal/dto/response"
+	"dbapp/internal/errors"
+	"dbapp/internal/model"
+	"dbapp/internal/repository"
+	"dbapp/pkg/audit"
+	"dbapp/pkg/logger"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 审计日志的操作类型
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionApprove = "approve"
+	AuditActionReject  = "reject"
+	AuditActionRestore = "restore"
+)
+
+// 审计日志的目标类型
+const (
+	AuditTargetArticle  = "article"
+	AuditTargetCategory = "category"
+	AuditTargetTag      = "tag"
+	AuditTargetComment  = "comment"
+	AuditTargetUser     = "user"
+	AuditTargetFile     = "file"
+	AuditTargetSetting  = "setting"
+	AuditTargetSeries   = "series"
+)
+
+const (
+	// auditExportLimit 单次导出的最大行数，更多数据需缩小时间范围分批导出
+	auditExportLimit     = 100000
+	auditExportBatchSize = 500
+)
+
+type AuditService struct {
+	auditRepo *repository.AuditLogRepository
+	ctx       context.Context
+}
+
+func NewAuditService(auditRepo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		ctx:       context.Background(),
+	}
+}
+
+// WithContext 返回在 ctx 下写入日志的副本，操作者信息从 ctx 中读取
+func (s *AuditService) WithContext(ctx context.Context) *AuditService {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	clone.auditRepo = s.auditRepo.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
+}
+
+// Record 记录一次写操作，before/after 为操作前后的对象，新增时 before 为 nil，删除时 after 为 nil。
+// s 为 nil 时不记录；写入失败只记录日志，不影响已完成的业务操作。
+func (s *AuditService) Record(action, targetType string, targetID uint64, before, after interface{}) {
+	if s == nil {
+		return
+	}
+
+	actor := audit.FromContext(s.ctx)
+	log := &model.AuditLog{
+		IP:         actor.IP,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		RequestID:  actor.RequestID,
+	}
+	if actor.UserID > 0 {
+		log.UserID = &actor.UserID
+	}
+	if changes := audit.Diff(before, after); changes != nil {
+		data, _ := json.Marshal(changes)
+		log.Changes = string(data)
+	}
+
+	if err := s.auditRepo.Create(log); err != nil {
+		logger.FromContext(s.ctx).Warn("写入审计日志失败",
+			zap.String("action", action),
+			zap.String("target_type", targetType),
+			zap.Uint64("target_id", targetID),
+			zap.String("error", err.Error()))
+	}
+}
+
+// List 分页查询审计日志
+func (s *AuditService) List(req *request.ListAuditLogRequest) (*response.AuditLogListResponse, error) {
+	filter, err := auditFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	logs, total, err := s.auditRepo.List(filter, req.Page, req.PageSize)
+	if err != nil {
+		return nil, errors.NewInternalError("查询审计日志失败").WithCause(err)
+	}
+
+	items := make([]response.AuditLogResponse, len(logs))
+	for i, log := range logs {
+		items[i] = toAuditLogResponse(&log)
+	}
+
+	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+
+	return &response.AuditLogListResponse{
+		Items: items,
+		Pagination: response.Pagination{
+			Page:       req.Page,
+			PageSize:   req.PageSize,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+// ValidateFilter 校验查询条件，导出前调用，以便在写出响应头之前返回参数错误
+func (s *AuditService) ValidateFilter(req *request.ListAuditLogRequest) error {
+	_, err := auditFilter(req)
+	return err
+}
+
+// Export 按查询条件将审计日志以CSV格式写入 w，按时间倒序，最多 auditExportLimit 行
+func (s *AuditService) Export(req *request.ListAuditLogRequest, w io.Writer) error {
+	filter, err := auditFilter(req)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "created_at", "user_id", "username", "ip", "action", "target_type", "target_id", "changes", "request_id"})
+
+	err = s.auditRepo.Each(filter, auditExportBatchSize, auditExportLimit, func(logs []model.AuditLog) error {
+		for _, log := range logs {
+			var userID, username string
+			if log.UserID != nil {
+				userID = strconv.FormatUint(*log.UserID, 10)
+			}
+			if log.User != nil {
+				username = log.User.Username
+			}
+			writer.Write([]string{
+				strconv.FormatUint(log.ID, 10),
+				log.CreatedAt.Format(time.RFC3339),
+				userID,
+				csvSafe(username),
+				log.IP,
+				log.Action,
+				log.TargetType,
+				strconv.FormatUint(log.TargetID, 10),
+				csvSafe(log.Changes),
+				log.RequestID,
+			})
+		}
+		// 每批刷新一次，边查询边输出，不在内存中缓存整个文件
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return errors.NewInternalError("导出审计日志失败").WithCause(err)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvSafe 防止单元格被表格软件当作公式执行（CSV注入）
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+	return value
+}
+
+func auditFilter(req *request.ListAuditLogRequest) (repository.AuditLogFilter, error) {
+	filter := repository.AuditLogFilter{
+		UserID:     req.UserID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+	}
+	var err error
+	if filter.From, filter.To, err = parseTimeRange(req.From, req.To); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func toAuditLogResponse(log *model.AuditLog) response.AuditLogResponse {
+	resp := response.AuditLogResponse{
+		ID:         log.ID,
+		IP:         log.IP,
+		Action:     log.Action,
+		TargetType: log.TargetType,
+		TargetID:   log.TargetID,
+		RequestID:  log.RequestID,
+		CreatedAt:  log.CreatedAt,
+	}
+	if log.User != nil {
+		resp.User = toUserResponse(log.User)
+	}
+	if log.Changes != "" {
+		resp.Changes = json.RawMessage(log.Changes)
+	}
+	return resp
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"dbapp/internal/dto/request"
+	"dbapp/internal/repository"
+	"dbapp/internal/test"
+	"dbapp/pkg/audit"
+	"dbapp/pkg/cache"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditService_RecordCategoryChanges(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	admin := test.CreateTestUser(db, "admin", "admin@example.com")
+	auditService := NewAuditService(repository.NewAuditLogRepository(db))
+	categoryService := NewCategoryService(repository.NewCategoryRepository(db), cache.NewMemoryCache(time.Minute), auditService)
+
+	ctx := audit.NewContext(context.Background(), audit.Actor{UserID: admin.ID, IP: "10.0.0.1", RequestID: "req-1"})
+	categoryService = categoryService.WithContext(ctx)
+
+	category, err := categoryService.Create(&request.CreateCategoryRequest{Name: "旧名称"})
+	if err != nil {
+		t.Fatalf("创建分类失败: %v", err)
+	}
+	if _, err := categoryService.Update(category.ID, &request.UpdateCategoryRequest{Name: "新名称"}); err != nil {
+		t.Fatalf("更新分类失败: %v", err)
+	}
+	if err := categoryService.Delete(category.ID); err != nil {
+		t.Fatalf("删除分类失败: %v", err)
+	}
+
+	result, err := auditService.List(&request.ListAuditLogRequest{TargetType: AuditTargetCategory, TargetID: category.ID})
+	if err != nil {
+		t.Fatalf("查询审计日志失败: %v", err)
+	}
+	if result.Pagination.Total != 3 {
+		t.Fatalf("期望 3 条审计日志, 得到 %d", result.Pagination.Total)
+	}
+
+	// 按时间倒序：删除、更新、创建
+	actions := []string{AuditActionDelete, AuditActionUpdate, AuditActionCreate}
+	for i, item := range result.Items {
+		if item.Action != actions[i] {
+			t.Errorf("第 %d 条期望操作 %s, 得到 %s", i+1, actions[i], item.Action)
+		}
+		if item.User == nil || item.User.ID != admin.ID {
+			t.Errorf("第 %d 条操作者不正确", i+1)
+		}
+		if item.IP != "10.0.0.1" || item.RequestID != "req-1" {
+			t.Errorf("第 %d 条IP或请求ID不正确: %s %s", i+1, item.IP, item.RequestID)
+		}
+	}
+
+	var changes map[string]audit.Change
+	if err := json.Unmarshal(result.Items[1].Changes, &changes); err != nil {
+		t.Fatalf("解析字段差异失败: %v", err)
+	}
+	if len(changes) != 1 || changes["name"].Old != "旧名称" || changes["name"].New != "新名称" {
+		t.Errorf("更新的字段差异不正确: %v", changes)
+	}
+
+	// 按操作类型过滤
+	result, _ = auditService.List(&request.ListAuditLogRequest{Action: AuditActionUpdate})
+	if result.Pagination.Total != 1 {
+		t.Errorf("按操作类型过滤期望 1 条, 得到 %d", result.Pagination.Total)
+	}
+}
+
+func TestAuditService_List_TimeRange(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	auditService := NewAuditService(repository.NewAuditLogRepository(db))
+	auditService.Record(AuditActionCreate, AuditTargetTag, 1, nil, nil)
+
+	today := time.Now().Format("2006-01-02")
+	result, err := auditService.List(&request.ListAuditLogRequest{From: today, To: today})
+	if err != nil {
+		t.Fatalf("查询审计日志失败: %v", err)
+	}
+	if result.Pagination.Total != 1 {
+		t.Errorf("结束日期应包含当天, 得到 %d 条", result.Pagination.Total)
+	}
+
+	result, _ = auditService.List(&request.ListAuditLogRequest{From: time.Now().Add(time.Hour).Format(time.RFC3339)})
+	if result.Pagination.Total != 0 {
+		t.Errorf("开始时间之后没有日志, 得到 %d 条", result.Pagination.Total)
+	}
+
+	if _, err := auditService.List(&request.ListAuditLogRequest{From: "yesterday"}); err == nil {
+		t.Error("无效的时间应该返回错误")
+	}
+}
+
+func TestAuditService_Export(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	auditService := NewAuditService(repository.NewAuditLogRepository(db))
+	type named struct {
+		Name string `json:"name"`
+	}
+	for i := 0; i < auditExportBatchSize+5; i++ {
+		auditService.Record(AuditActionCreate, AuditTargetTag, uint64(i+1), nil, nil)
+	}
+	auditService.Record(AuditActionUpdate, AuditTargetTag, 1, named{Name: "a"}, named{Name: "=1+1"})
+
+	var buf bytes.Buffer
+	if err := auditService.Export(&request.ListAuditLogRequest{}, &buf); err != nil {
+		t.Fatalf("导出审计日志失败: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("解析CSV失败: %v", err)
+	}
+	if len(rows) != auditExportBatchSize+7 {
+		t.Fatalf("期望 %d 行（含表头）, 得到 %d", auditExportBatchSize+7, len(rows))
+	}
+	if rows[0][0] != "id" {
+		t.Errorf("第一行应为表头, 得到 %v", rows[0])
+	}
+	// 最新的一条在最前面
+	if rows[1][5] != AuditActionUpdate {
+		t.Errorf("期望第一条为 update, 得到 %s", rows[1][5])
+	}
+	if !strings.Contains(rows[1][8], "=1+1") {
+		t.Errorf("字段差异应包含修改后的值: %s", rows[1][8])
+	}
+
+	// 以公式字符开头的单元格需要转义
+	if got := csvSafe("=HYPERLINK(\"x\")"); got != "'=HYPERLINK(\"x\")" {
+		t.Errorf("公式单元格未转义: %s", got)
+	}
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"dbapp/internal/dto/request"
+	"dbapp/internal/dto/response"
+	"dbapp/internal/errors"
+	"dbapp/internal/model"
+	"dbapp/internal/repository"
+)
+
+// BookmarkService 收藏和阅读列表：用户收藏文章留待以后阅读，并整理到命名的阅读列表中。
+// 收藏和列表只有本人可以修改；公开的阅读列表其他用户也可以查看，列表中只显示查看者可见的文章。
+type BookmarkService struct {
+	bookmarkRepo    *repository.BookmarkRepository
+	readingListRepo *repository.ReadingListRepository
+	articleRepo     *repository.ArticleRepository
+	likeRepo        *repository.LikeRepository
+}
+
+func NewBookmarkService(
+	bookmarkRepo *repository.BookmarkRepository,
+	readingListRepo *repository.ReadingListRepository,
+	articleRepo *repository.ArticleRepository,
+	likeRepo *repository.LikeRepository,
+) *BookmarkService {
+	return &BookmarkService{
+		bookmarkRepo:    bookmarkRepo,
+		readingListRepo: readingListRepo,
+		articleRepo:     articleRepo,
+		likeRepo:        likeRepo,
+	}
+}
+
+// WithContext 返回在 ctx 下访问数据库的副本
+func (s *BookmarkService) WithContext(ctx context.Context) *BookmarkService {
+	clone := *s
+	clone.bookmarkRepo = s.bookmarkRepo.WithContext(ctx)
+	clone.readingListRepo = s.readingListRepo.WithContext(ctx)
+	clone.articleRepo = s.articleRepo.WithContext(ctx)
+	clone.likeRepo = s.likeRepo.WithContext(ctx)
+	return &clone
+}
+
+// Add 收藏文章，指定阅读列表时排在列表末尾。只能收藏在列表中对自己可见的文章
+func (s *BookmarkService) Add(req *request.CreateBookmarkRequest, userID uint64, role string) (*response.BookmarkResponse, error) {
+	article, err := s.articleRepo.GetByID(req.ArticleID)
+	if err != nil || !articleListed(article.AuthorID, article.Status, article.Visibility, userID, role) {
+		return nil, errors.NewNotFoundError("文章不存在")
+	}
+
+	existing, err := s.bookmarkRepo.Get(userID, req.ArticleID)
+	if err != nil {
+		return nil, errors.NewInternalError("查询收藏失败").WithCause(err)
+	}
+	if existing != nil {
+		return nil, errors.NewBadRequestError("文章已收藏")
+	}
+
+	listID := normalizeListID(req.ReadingListID)
+	if listID != nil {
+		if _, err := s.ownList(*listID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	bookmark := &model.Bookmark{
+		UserID:        userID,
+		ArticleID:     req.ArticleID,
+		ReadingListID: listID,
+	}
+	if err := s.bookmarkRepo.Create(bookmark); err != nil {
+		return nil, errors.NewInternalError("收藏失败").WithCause(err)
+	}
+	bookmark.Article = *article
+
+	return s.toResponses([]model.Bookmark{*bookmark}, userID, role)[0], nil
+}
+
+// Move 把收藏移到另一个阅读列表的末尾，ReadingListID 为空时移出列表
+func (s *BookmarkService) Move(articleID uint64, req *request.MoveBookmarkRequest, userID uint64) error {
+	bookmark, err := s.bookmarkRepo.Get(userID, articleID)
+	if err != nil {
+		return errors.NewInternalError("查询收藏失败").WithCause(err)
+	}
+	if bookmark == nil {
+		return errors.NewNotFoundError("未收藏该文章")
+	}
+
+	listID := normalizeListID(req.ReadingListID)
+	if listID != nil {
+		if _, err := s.ownList(*listID, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.bookmarkRepo.Move(bookmark, listID); err != nil {
+		return errors.NewInternalError("移动收藏失败").WithCause(err)
+	}
+	return nil
+}
+
+// Remove 取消收藏
+func (s *BookmarkService) Remove(articleID uint64, userID uint64) error {
+	removed, err := s.bookmarkRepo.Delete(userID, articleID)
+	if err != nil {
+		return errors.NewInternalError("取消收藏失败").WithCause(err)
+	}
+	if !removed {
+		return errors.NewNotFoundError("未收藏该文章")
+	}
+	return nil
+}
+
+// List 按收藏时间倒序分页查询当前用户的收藏
+func (s *BookmarkService) List(req *request.ListBookmarkRequest, userID uint64, role string) (*response.BookmarkListResponse, error) {
+	return s.list(repository.BookmarkFilter{UserID: userID}, req, userID, role)
+}
+
+func (s *BookmarkService) CreateList(req *request.CreateReadingListRequest, userID uint64, role string) (*response.ReadingListResponse, error) {
+	existing, err := s.readingListRepo.GetByName(userID, req.Name)
+	if err != nil {
+		return nil, errors.NewInternalError("查询阅读列表失败").WithCause(err)
+	}
+	if existing != nil {
+		return nil, errors.NewBadRequestError("阅读列表名称已存在")
+	}
+
+	list := &model.ReadingList{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		IsPublic:    req.IsPublic,
+	}
+	if err := s.readingListRepo.Create(list); err != nil {
+		return nil, errors.NewInternalError("创建阅读列表失败").WithCause(err)
+	}
+	return s.GetList(list.ID, userID, role)
+}
+
+// GetList 获取阅读列表，其他用户的非公开列表返回不存在
+func (s *BookmarkService) GetList(id uint64, userID uint64, role string) (*response.ReadingListResponse, error) {
+	list, err := s.readableList(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.toListResponses([]model.ReadingList{*list}, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+// ListLists 查询用户的阅读列表，查询其他用户时只返回公开的列表。不指定用户时查询当前用户的
+func (s *BookmarkService) ListLists(req *request.ListReadingListRequest, userID uint64, role string) ([]*response.ReadingListResponse, error) {
+	ownerID := req.UserID
+	if ownerID == 0 {
+		if userID == 0 {
+			return nil, errors.NewUnauthorizedError("未提供认证信息")
+		}
+		ownerID = userID
+	}
+
+	lists, err := s.readingListRepo.ListByUser(ownerID, ownerID != userID)
+	if err != nil {
+		return nil, errors.NewInternalError("查询阅读列表失败").WithCause(err)
+	}
+	return s.toListResponses(lists, userID, role)
+}
+
+func (s *BookmarkService) UpdateList(id uint64, req *request.UpdateReadingListRequest, userID uint64, role string) (*response.ReadingListResponse, error) {
+	list, err := s.ownList(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" && req.Name != list.Name {
+		existing, err := s.readingListRepo.GetByName(userID, req.Name)
+		if err != nil {
+			return nil, errors.NewInternalError("查询阅读列表失败").WithCause(err)
+		}
+		if existing != nil {
+			return nil, errors.NewBadRequestError("阅读列表名称已存在")
+		}
+		list.Name = req.Name
+	}
+	if req.Description != nil {
+		list.Description = *req.Description
+	}
+	if req.IsPublic != nil {
+		list.IsPublic = *req.IsPublic
+	}
+
+	if err := s.readingListRepo.Update(list); err != nil {
+		return nil, errors.NewInternalError("更新阅读列表失败").WithCause(err)
+	}
+	return s.GetList(id, userID, role)
+}
+
+// DeleteList 删除阅读列表，列表中的收藏保留，移出列表
+func (s *BookmarkService) DeleteList(id uint64, userID uint64) error {
+	if _, err := s.ownList(id, userID); err != nil {
+		return err
+	}
+	if err := s.readingListRepo.Delete(id); err != nil {
+		return errors.NewInternalError("删除阅读列表失败").WithCause(err)
+	}
+	return nil
+}
+
+// ListBookmarks 按列表中的顺序分页查询阅读列表中的收藏
+func (s *BookmarkService) ListBookmarks(id uint64, req *request.ListBookmarkRequest, userID uint64, role string) (*response.BookmarkListResponse, error) {
+	if _, err := s.readableList(id, userID); err != nil {
+		return nil, err
+	}
+	return s.list(repository.BookmarkFilter{ReadingListID: id}, req, userID, role)
+}
+
+// Reorder 按给定顺序把这些收藏排在阅读列表最前面，列表中其余收藏保持原有顺序排在之后
+func (s *BookmarkService) Reorder(id uint64, req *request.ReorderReadingListRequest, userID uint64) error {
+	if _, err := s.ownList(id, userID); err != nil {
+		return err
+	}
+
+	current, err := s.bookmarkRepo.ArticleIDsInList(id)
+	if err != nil {
+		return errors.NewInternalError("查询阅读列表失败").WithCause(err)
+	}
+	inList := make(map[uint64]bool, len(current))
+	for _, articleID := range current {
+		inList[articleID] = true
+	}
+	seen := make(map[uint64]bool, len(req.ArticleIDs))
+	for _, articleID := range req.ArticleIDs {
+		if seen[articleID] {
+			return errors.NewBadRequestError("文章ID重复")
+		}
+		if !inList[articleID] {
+			return errors.NewBadRequestError("文章不在阅读列表中")
+		}
+		seen[articleID] = true
+	}
+
+	if err := s.bookmarkRepo.Reorder(id, req.ArticleIDs); err != nil {
+		return errors.NewInternalError("调整顺序失败").WithCause(err)
+	}
+	return nil
+}
+
+func (s *BookmarkService) list(filter repository.BookmarkFilter, req *request.ListBookmarkRequest, userID uint64, role string) (*response.BookmarkListResponse, error) {
+	page, _ := pageQuery(req.Page, req.PageSize, nil)
+	filter.Viewer = &repository.ArticleViewer{UserID: userID, Privileged: model.HasRole(role, model.RoleEditor)}
+
+	bookmarks, total, err := s.bookmarkRepo.List(filter, page.Page, page.PageSize)
+	if err != nil {
+		return nil, errors.NewInternalError("查询收藏失败").WithCause(err)
+	}
+	return &response.BookmarkListResponse{
+		Items:      s.toResponses(bookmarks, userID, role),
+		Pagination: toPagination(page, total, nil),
+	}, nil
+}
+
+// ownList 查询阅读列表并检查是否属于当前用户
+func (s *BookmarkService) ownList(id uint64, userID uint64) (*model.ReadingList, error) {
+	list, err := s.readingListRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("阅读列表不存在")
+	}
+	if list.UserID != userID {
+		return nil, errors.NewForbiddenError("无权限修改此阅读列表")
+	}
+	return list, nil
+}
+
+// readableList 查询当前用户可以查看的阅读列表，其他用户的非公开列表按不存在处理
+func (s *BookmarkService) readableList(id uint64, userID uint64) (*model.ReadingList, error) {
+	list, err := s.readingListRepo.GetByID(id)
+	if err != nil || (!list.IsPublic && list.UserID != userID) {
+		return nil, errors.NewNotFoundError("阅读列表不存在")
+	}
+	return list, nil
+}
+
+// toResponses 转换收藏列表，文章的点赞、收藏状态按当前用户批量查询
+func (s *BookmarkService) toResponses(bookmarks []model.Bookmark, userID uint64, role string) []*response.BookmarkResponse {
+	items := make([]*response.BookmarkResponse, len(bookmarks))
+	articles := make([]*response.ArticleResponse, len(bookmarks))
+	for i := range bookmarks {
+		article := toArticleResponse(&bookmarks[i].Article)
+		// 列表中不返回正文
+		article.Content = ""
+		article.ContentHTML = ""
+		hideShareToken(article, userID, role)
+		articles[i] = article
+
+		items[i] = &response.BookmarkResponse{
+			ID:            bookmarks[i].ID,
+			ReadingListID: bookmarks[i].ReadingListID,
+			Position:      bookmarks[i].Position,
+			Article:       article,
+			CreatedAt:     bookmarks[i].CreatedAt,
+		}
+	}
+	markUserState(s.likeRepo, s.bookmarkRepo, userID, articles)
+	return items
+}
+
+func (s *BookmarkService) toListResponses(lists []model.ReadingList, userID uint64, role string) ([]*response.ReadingListResponse, error) {
+	ids := make([]uint64, len(lists))
+	for i := range lists {
+		ids[i] = lists[i].ID
+	}
+	counts, err := s.bookmarkRepo.CountByList(ids, &repository.ArticleViewer{UserID: userID, Privileged: model.HasRole(role, model.RoleEditor)})
+	if err != nil {
+		return nil, errors.NewInternalError("查询阅读列表失败").WithCause(err)
+	}
+
+	items := make([]*response.ReadingListResponse, len(lists))
+	for i, list := range lists {
+		items[i] = &response.ReadingListResponse{
+			ID:          list.ID,
+			Name:        list.Name,
+			Description: list.Description,
+			IsPublic:    list.IsPublic,
+			User: &response.UserResponse{
+				ID:        list.User.ID,
+				Username:  list.User.Username,
+				Nickname:  list.User.Nickname,
+				AvatarURL: list.User.AvatarURL,
+			},
+			BookmarkCount: counts[list.ID],
+			CreatedAt:     list.CreatedAt,
+			UpdatedAt:     list.UpdatedAt,
+		}
+	}
+	return items, nil
+}
+
+// normalizeListID 阅读列表ID为 0 时视为不放入列表
+func normalizeListID(id *uint64) *uint64 {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	return id
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"dbapp/internal/dto/request"
+	"dbapp/internal/model"
+	"dbapp/internal/repository"
+	"dbapp/internal/test"
+	"dbapp/pkg/cache"
+	"testing"
+	"time"
+)
+
+func TestBookmarkService_ReadingList(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	bookmarkService := NewBookmarkService(repository.NewBookmarkRepository(db), repository.NewReadingListRepository(db),
+		repository.NewArticleRepository(db), repository.NewLikeRepository(db))
+
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	reader := test.CreateTestUser(db, "reader", "reader@example.com")
+	a1 := test.CreateTestArticle(db, author.ID, "第一篇")
+	a2 := test.CreateTestArticle(db, author.ID, "第二篇")
+	a3 := test.CreateTestArticle(db, author.ID, "第三篇")
+
+	list, err := bookmarkService.CreateList(&request.CreateReadingListRequest{Name: "稍后阅读"}, reader.ID, "user")
+	if err != nil {
+		t.Fatalf("创建阅读列表失败: %v", err)
+	}
+	if _, err := bookmarkService.CreateList(&request.CreateReadingListRequest{Name: "稍后阅读"}, reader.ID, "user"); !isAppError(err, 400) {
+		t.Errorf("重名的阅读列表应返回400, 得到 %v", err)
+	}
+
+	for _, article := range []*model.Article{a1, a2, a3} {
+		if _, err := bookmarkService.Add(&request.CreateBookmarkRequest{ArticleID: article.ID, ReadingListID: &list.ID}, reader.ID, "user"); err != nil {
+			t.Fatalf("收藏失败: %v", err)
+		}
+	}
+	if _, err := bookmarkService.Add(&request.CreateBookmarkRequest{ArticleID: a1.ID}, reader.ID, "user"); !isAppError(err, 400) {
+		t.Errorf("重复收藏应返回400, 得到 %v", err)
+	}
+	// 不能把收藏放入其他用户的阅读列表
+	other, _ := bookmarkService.CreateList(&request.CreateReadingListRequest{Name: "作者的列表"}, author.ID, "user")
+	if _, err := bookmarkService.Add(&request.CreateBookmarkRequest{ArticleID: a1.ID, ReadingListID: &other.ID}, author.ID, "user"); err != nil {
+		t.Fatalf("收藏失败: %v", err)
+	}
+	if err := bookmarkService.Move(a1.ID, &request.MoveBookmarkRequest{ReadingListID: &other.ID}, reader.ID); !isAppError(err, 403) {
+		t.Errorf("移入其他用户的阅读列表应返回403, 得到 %v", err)
+	}
+
+	// 只给出部分文章时，其余文章保持原有顺序排在之后
+	if err := bookmarkService.Reorder(list.ID, &request.ReorderReadingListRequest{ArticleIDs: []uint64{a3.ID}}, reader.ID); err != nil {
+		t.Fatalf("调整顺序失败: %v", err)
+	}
+	assertBookmarkOrder(t, bookmarkService, list.ID, reader.ID, a3.ID, a1.ID, a2.ID)
+	if err := bookmarkService.Reorder(list.ID, &request.ReorderReadingListRequest{ArticleIDs: []uint64{a1.ID, a1.ID}}, reader.ID); !isAppError(err, 400) {
+		t.Errorf("重复的文章应返回400, 得到 %v", err)
+	}
+
+	// 非公开列表其他用户看不到，公开后可以查看
+	if _, err := bookmarkService.GetList(list.ID, author.ID, "user"); !isAppError(err, 404) {
+		t.Errorf("其他用户查看非公开列表应返回404, 得到 %v", err)
+	}
+	public := true
+	if _, err := bookmarkService.UpdateList(list.ID, &request.UpdateReadingListRequest{IsPublic: &public}, reader.ID, "user"); err != nil {
+		t.Fatalf("更新阅读列表失败: %v", err)
+	}
+	lists, err := bookmarkService.ListLists(&request.ListReadingListRequest{UserID: reader.ID}, 0, "")
+	if err != nil || len(lists) != 1 || lists[0].BookmarkCount != 3 {
+		t.Fatalf("未登录用户应能看到公开列表及其3篇文章, 得到 %+v, %v", lists, err)
+	}
+
+	// 文章改为草稿后不再出现在其他用户看到的列表中
+	db.Model(&model.Article{}).Where("id = ?", a1.ID).Update("status", "draft")
+	result, err := bookmarkService.ListBookmarks(list.ID, &request.ListBookmarkRequest{}, 0, "")
+	if err != nil {
+		t.Fatalf("查询列表失败: %v", err)
+	}
+	if result.Pagination.Total != 2 || len(result.Items) != 2 {
+		t.Errorf("草稿不应出现在公开列表中, 得到 %d 篇", len(result.Items))
+	}
+	// 更新后返回的收藏数按调用者的角色计算，编辑可以看到草稿
+	desc := "编辑的视角"
+	if updated, err := bookmarkService.UpdateList(list.ID, &request.UpdateReadingListRequest{Description: &desc}, reader.ID, "editor"); err != nil || updated.BookmarkCount != 3 {
+		t.Errorf("编辑应看到列表中的3篇文章, 得到 %+v, %v", updated, err)
+	}
+	if updated, err := bookmarkService.UpdateList(list.ID, &request.UpdateReadingListRequest{Description: &desc}, reader.ID, "user"); err != nil || updated.BookmarkCount != 2 {
+		t.Errorf("普通用户不应看到草稿, 得到 %+v, %v", updated, err)
+	}
+
+	// 删除列表后收藏保留，移出列表
+	if err := bookmarkService.DeleteList(list.ID, reader.ID); err != nil {
+		t.Fatalf("删除阅读列表失败: %v", err)
+	}
+	all, _ := bookmarkService.List(&request.ListBookmarkRequest{}, reader.ID, "user")
+	if all.Pagination.Total != 2 {
+		t.Errorf("期望当前用户可见的收藏 2 个, 得到 %d", all.Pagination.Total)
+	}
+	for _, item := range all.Items {
+		if item.ReadingListID != nil || !item.Article.IsBookmarked {
+			t.Errorf("删除列表后收藏应移出列表并标记为已收藏: %+v", item)
+		}
+	}
+}
+
+func TestArticleService_IsBookmarked(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	articleRepo := repository.NewArticleRepository(db)
+	likeRepo := repository.NewLikeRepository(db)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         repository.NewUserRepository(db),
+		LikeRepo:         likeRepo,
+		BookmarkRepo:     bookmarkRepo,
+		ArticleImageRepo: repository.NewArticleImageRepository(db),
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
+	bookmarkService := NewBookmarkService(bookmarkRepo, repository.NewReadingListRepository(db), articleRepo, likeRepo)
+
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	reader := test.CreateTestUser(db, "reader", "reader@example.com")
+	saved := test.CreateTestArticle(db, author.ID, "已收藏")
+	test.CreateTestArticle(db, author.ID, "未收藏")
+
+	if _, err := bookmarkService.Add(&request.CreateBookmarkRequest{ArticleID: saved.ID}, reader.ID, "user"); err != nil {
+		t.Fatalf("收藏失败: %v", err)
+	}
+
+	result, err := articleService.List(&request.ListArticleRequest{}, reader.ID, "user")
+	if err != nil {
+		t.Fatalf("查询文章列表失败: %v", err)
+	}
+	for _, item := range result.Items {
+		if item.IsBookmarked != (item.ID == saved.ID) {
+			t.Errorf("文章 %d 的收藏状态错误: %v", item.ID, item.IsBookmarked)
+		}
+	}
+
+	article, _ := articleService.GetByID(saved.ID, reader.ID, "user", "")
+	if !article.IsBookmarked {
+		t.Error("文章详情应标记为已收藏")
+	}
+	article, _ = articleService.GetByID(saved.ID, author.ID, "user", "")
+	if article.IsBookmarked {
+		t.Error("其他用户看到的文章详情不应标记为已收藏")
+	}
+}
+
+func assertBookmarkOrder(t *testing.T, s *BookmarkService, listID, userID uint64, ids ...uint64) {
+	t.Helper()
+	result, err := s.ListBookmarks(listID, &request.ListBookmarkRequest{}, userID, "user")
+	if err != nil {
+		t.Fatalf("查询阅读列表失败: %v", err)
+	}
+	if len(result.Items) != len(ids) {
+		t.Fatalf("期望 %d 个收藏, 得到 %d", len(ids), len(result.Items))
+	}
+	for i, id := range ids {
+		if result.Items[i].Article.ID != id {
+			t.Errorf("第 %d 个期望文章 %d, 得到 %d", i+1, id, result.Items[i].Article.ID)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"dbapp/internal/dto/request"
+	"dbapp/pkg/cache"
+	"fmt"
+	"strings"
+)
+
+const (
+	articleCacheKeyPrefix = "article:"
+	categoryTreeCacheKey  = "categories:tree"
+	tagListCacheKeyPrefix = "tags:list:"
+	settingsCacheKey      = "settings"
+)
+
+func articleCacheKey(id uint64) string {
+	return fmt.Sprintf("%s%d", articleCacheKeyPrefix, id)
+}
+
+// tagListCacheKey 只缓存不带关键词、排序参数合法的列表，避免用户输入制造无限多的缓存键。
+// limit 超出 0..maxPageSize 时不缓存，TagService.List 在生成键之前已将其限制在该范围内
+func tagListCacheKey(req *request.ListTagRequest) (string, bool) {
+	if req.Keyword != "" {
+		return "", false
+	}
+	sort := strings.ToLower(req.Sort)
+	switch sort {
+	case "", "name", "created_at", "article_count":
+	default:
+		return "", false
+	}
+	if req.Limit < 0 || req.Limit > maxPageSize {
+		return "", false
+	}
+	order := strings.ToLower(req.Order)
+	switch order {
+	case "", "asc", "desc":
+	default:
+		return "", false
+	}
+	return fmt.Sprintf("%s%s:%s:%d", tagListCacheKeyPrefix, sort, order, req.Limit), true
+}
+
+// invalidateArticle 使文章详情缓存失效，文章计数变化时也需要调用
+func invalidateArticle(c cache.Cache, id uint64) {
+	if c != nil {
+		c.Delete(articleCacheKey(id))
+	}
+}
+
+// invalidateCategoryTree 使分类树缓存失效，分类或文章的分类关联变化时调用
+func invalidateCategoryTree(c cache.Cache) {
+	if c != nil {
+		c.Delete(categoryTreeCacheKey)
+	}
+}
+
+// invalidateTagLists 使所有标签列表缓存失效，标签或文章的标签关联变化时调用
+func invalidateTagLists(c cache.Cache) {
+	if c != nil {
+		c.DeletePrefix(tagListCacheKeyPrefix)
+	}
+}
+
+// invalidateSettings 使站点设置缓存失效，保存设置后调用
+func invalidateSettings(c cache.Cache) {
+	if c != nil {
+		c.Delete(settingsCacheKey)
+	}
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"dbapp/internal/dto/request"
+	"dbapp/internal/repository"
+	"dbapp/internal/test"
+	"dbapp/pkg/cache"
+	"testing"
+	"time"
+)
+
+func TestTagService_List_BoundedCacheKeys(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	appCache := cache.NewMemoryCache(time.Minute)
+	tagService := NewTagService(repository.NewTagRepository(db), appCache, nil)
+
+	// 超过上限的 limit 都使用同一个缓存键
+	for _, limit := range []int{101, 5000, 1 << 30} {
+		if _, err := tagService.List(&request.ListTagRequest{Limit: limit}); err != nil {
+			t.Fatalf("查询标签列表失败: %v", err)
+		}
+	}
+	if _, err := appCache.Get(tagListCacheKeyPrefix + "::100"); err != nil {
+		t.Errorf("limit 应被限制为 %d 后缓存: %v", maxPageSize, err)
+	}
+	if _, ok := tagListCacheKey(&request.ListTagRequest{Limit: maxPageSize + 1}); ok {
+		t.Error("超出上限的 limit 不应生成缓存键")
+	}
+}
@@ -1,24 +1,39 @@
 package service
 
 import (
+	"context"
 	"dbapp/internal/dto/request"
 	"dbapp/internal/dto/response"
 	"dbapp/internal/errors"
 	"dbapp/internal/model"
 	"dbapp/internal/repository"
+	"dbapp/pkg/cache"
 	"github.com/gosimple/slug"
 )
 
 type CategoryService struct {
 	categoryRepo *repository.CategoryRepository
+	cache        cache.Cache
+	audit        *AuditService
 }
 
-func NewCategoryService(categoryRepo *repository.CategoryRepository) *CategoryService {
+func NewCategoryService(categoryRepo *repository.CategoryRepository, cache cache.Cache, audit *AuditService) *CategoryService {
 	return &CategoryService{
 		categoryRepo: categoryRepo,
+		cache:        cache,
+		audit:        audit,
 	}
 }
 
+// WithContext 返回在 ctx 下访问数据库和缓存的副本，用于把SQL和Redis的追踪span关联到当前请求
+func (s *CategoryService) WithContext(ctx context.Context) *CategoryService {
+	clone := *s
+	clone.categoryRepo = s.categoryRepo.WithContext(ctx)
+	clone.cache = cache.WithContext(s.cache, ctx)
+	clone.audit = s.audit.WithContext(ctx)
+	return &clone
+}
+
 func (s *CategoryService) Create(req *request.CreateCategoryRequest) (*response.CategoryResponse, error) {
 	// 如果未提供slug，自动生成
 	categorySlug := req.Slug
@@ -51,11 +66,16 @@ func (s *CategoryService) Create(req *request.CreateCategoryRequest) (*response.
 	}
 
 	if err := s.categoryRepo.Create(category); err != nil {
-		return nil, errors.NewInternalError("创建分类失败")
+		return nil, errors.NewInternalError("创建分类失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionCreate, AuditTargetCategory, category.ID, nil, category)
+	invalidateCategoryTree(s.cache)
 
-	category, _ = s.categoryRepo.GetByID(category.ID)
-	return s.toResponse(category), nil
+	reloaded, err := s.categoryRepo.GetByID(category.ID)
+	if err != nil {
+		return nil, errors.NewInternalError("查询分类失败").WithCause(err)
+	}
+	return s.toResponse(reloaded), nil
 }
 
 func (s *CategoryService) GetByID(id uint64) (*response.CategoryResponse, error) {
@@ -75,25 +95,24 @@ func (s *CategoryService) GetBySlug(slug string) (*response.CategoryResponse, er
 }
 
 func (s *CategoryService) List(req *request.ListCategoryRequest) ([]response.CategoryResponse, error) {
-	var categories []model.Category
-	var err error
-
 	if req.Tree {
-		categories, err = s.categoryRepo.GetTree()
-	} else {
-		categories, err = s.categoryRepo.List(req.ParentID, req.IsActive)
+		var items []response.CategoryResponse
+		err := cache.Remember(s.cache, categoryTreeCacheKey, 0, &items, func() (interface{}, error) {
+			categories, err := s.categoryRepo.GetTree()
+			if err != nil {
+				return nil, errors.NewInternalError("查询分类列表失败").WithCause(err)
+			}
+			return s.toResponses(categories), nil
+		})
+		return items, err
 	}
 
+	categories, err := s.categoryRepo.List(req.ParentID, req.IsActive)
 	if err != nil {
-		return nil, errors.NewInternalError("查询分类列表失败")
+		return nil, errors.NewInternalError("查询分类列表失败").WithCause(err)
 	}
 
-	items := make([]response.CategoryResponse, len(categories))
-	for i, category := range categories {
-		items[i] = *s.toResponse(&category)
-	}
-
-	return items, nil
+	return s.toResponses(categories), nil
 }
 
 func (s *CategoryService) Update(id uint64, req *request.UpdateCategoryRequest) (*response.CategoryResponse, error) {
@@ -101,6 +120,7 @@ func (s *CategoryService) Update(id uint64, req *request.UpdateCategoryRequest)
 	if err != nil {
 		return nil, errors.NewNotFoundError("分类不存在")
 	}
+	before := *category
 
 	// 更新字段
 	if req.Name != "" {
@@ -134,11 +154,16 @@ func (s *CategoryService) Update(id uint64, req *request.UpdateCategoryRequest)
 	}
 
 	if err := s.categoryRepo.Update(category); err != nil {
-		return nil, errors.NewInternalError("更新分类失败")
+		return nil, errors.NewInternalError("更新分类失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionUpdate, AuditTargetCategory, id, &before, category)
+	invalidateCategoryTree(s.cache)
 
-	category, _ = s.categoryRepo.GetByID(id)
-	return s.toResponse(category), nil
+	reloaded, err := s.categoryRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.NewInternalError("查询分类失败").WithCause(err)
+	}
+	return s.toResponse(reloaded), nil
 }
 
 func (s *CategoryService) Delete(id uint64) error {
@@ -150,7 +175,7 @@ func (s *CategoryService) Delete(id uint64) error {
 	// 检查是否有子分类
 	childCount, err := s.categoryRepo.CountChildren(id)
 	if err != nil {
-		return errors.NewInternalError("查询子分类失败")
+		return errors.NewInternalError("查询子分类失败").WithCause(err)
 	}
 	if childCount > 0 {
 		return errors.NewBadRequestError("存在子分类，无法删除")
@@ -162,12 +187,22 @@ func (s *CategoryService) Delete(id uint64) error {
 	}
 
 	if err := s.categoryRepo.Delete(id); err != nil {
-		return errors.NewInternalError("删除分类失败")
+		return errors.NewInternalError("删除分类失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionDelete, AuditTargetCategory, id, category, nil)
+	invalidateCategoryTree(s.cache)
 
 	return nil
 }
 
+func (s *CategoryService) toResponses(categories []model.Category) []response.CategoryResponse {
+	items := make([]response.CategoryResponse, len(categories))
+	for i, category := range categories {
+		items[i] = *s.toResponse(&category)
+	}
+	return items
+}
+
 func (s *CategoryService) toResponse(category *model.Category) *response.CategoryResponse {
 	resp := &response.CategoryResponse{
 		ID:           category.ID,
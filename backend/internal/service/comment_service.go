@@ -1,44 +1,79 @@
 package service
 
 import (
+	"context"
+	"dbapp/internal/config"
 	"dbapp/internal/dto/request"
 	"dbapp/internal/dto/response"
 	"dbapp/internal/errors"
 	"dbapp/internal/model"
 	"dbapp/internal/repository"
+	"dbapp/pkg/cache"
 	"html"
 	"strings"
+	"sync/atomic"
 )
 
 type CommentService struct {
 	commentRepo *repository.CommentRepository
 	articleRepo *repository.ArticleRepository
 	likeRepo    *repository.LikeRepository
+	cache       cache.Cache
+	settings    *SettingService
+	audit       *AuditService
+	// moderationLinks 需审核的链接数阈值，由 WithContext 返回的副本共享，可在运行时修改
+	moderationLinks *atomic.Int64
 }
 
+// NewCommentService settings 为 nil 时评论不需要审核
 func NewCommentService(
 	commentRepo *repository.CommentRepository,
 	articleRepo *repository.ArticleRepository,
 	likeRepo *repository.LikeRepository,
+	cache cache.Cache,
+	settings *SettingService,
+	audit *AuditService,
 ) *CommentService {
 	return &CommentService{
 		commentRepo: commentRepo,
 		articleRepo: articleRepo,
 		likeRepo:    likeRepo,
+		cache:       cache,
+		settings:    settings,
+		audit:       audit,
+
+		moderationLinks: new(atomic.Int64),
 	}
 }
 
-func (s *CommentService) Create(req *request.CreateCommentRequest, userID uint64) (*response.CommentResponse, error) {
-	// 验证文章是否存在
-	article, err := s.articleRepo.GetByID(req.ArticleID)
-	if err != nil || article == nil {
-		return nil, errors.NewNotFoundError("文章不存在")
+// UpdateConfig 设置评论审核配置，启动时和配置重新加载时调用
+func (s *CommentService) UpdateConfig(cfg config.CommentConfig) {
+	s.moderationLinks.Store(int64(cfg.ModerationLinks))
+}
+
+// WithContext 返回在 ctx 下访问数据库和缓存的副本，用于把SQL和Redis的追踪span关联到当前请求
+func (s *CommentService) WithContext(ctx context.Context) *CommentService {
+	clone := *s
+	clone.commentRepo = s.commentRepo.WithContext(ctx)
+	clone.articleRepo = s.articleRepo.WithContext(ctx)
+	clone.likeRepo = s.likeRepo.WithContext(ctx)
+	clone.cache = cache.WithContext(s.cache, ctx)
+	clone.settings = s.settings.WithContext(ctx)
+	clone.audit = s.audit.WithContext(ctx)
+	return &clone
+}
+
+// Create 创建评论，开启先审后发或链接数达到审核阈值时，非管理员的评论需审核后才展示。
+// 只能评论当前用户可查看的文章，不公开的文章需提供分享令牌
+func (s *CommentService) Create(req *request.CreateCommentRequest, userID uint64, role string, shareToken string) (*response.CommentResponse, error) {
+	if err := s.checkArticle(req.ArticleID, userID, role, shareToken); err != nil {
+		return nil, err
 	}
 
 	// 如果是指定父评论的回复，验证父评论是否存在
 	if req.ParentID != nil && *req.ParentID > 0 {
 		parent, err := s.commentRepo.GetByID(*req.ParentID)
-		if err != nil || parent == nil {
+		if err != nil || parent == nil || parent.Status != "published" {
 			return nil, errors.NewNotFoundError("父评论不存在")
 		}
 		// 确保父评论属于同一篇文章
@@ -60,22 +95,38 @@ func (s *CommentService) Create(req *request.CreateCommentRequest, userID uint64
 		ContentHTML: contentHTML,
 		Status:      "published",
 	}
-
-	if err := s.commentRepo.Create(comment); err != nil {
-		return nil, errors.NewInternalError("创建评论失败")
+	if !model.HasRole(role, model.RoleAdmin) && s.needsModeration(req.Content) {
+		comment.Status = "pending"
 	}
 
-	// 如果是回复，增加父评论的回复计数
-	if req.ParentID != nil && *req.ParentID > 0 {
-		go s.commentRepo.IncrementReplyCount(*req.ParentID)
+	// 评论与父评论回复数、文章评论数在同一事务中更新
+	if err := s.commentRepo.CreateWithCounters(comment); err != nil {
+		return nil, errors.NewInternalError("创建评论失败").WithCause(err)
 	}
-
-	// 增加文章的评论计数
-	go s.articleRepo.IncrementCommentCount(req.ArticleID)
+	commentsCreatedTotal.Inc()
+	s.audit.Record(AuditActionCreate, AuditTargetComment, comment.ID, nil, comment)
+	invalidateArticle(s.cache, req.ArticleID)
 
 	// 重新加载评论以获取关联数据
-	comment, _ = s.commentRepo.GetByID(comment.ID)
-	return s.toResponse(comment, userID), nil
+	reloaded, err := s.commentRepo.GetByID(comment.ID)
+	if err != nil {
+		return nil, errors.NewInternalError("查询评论失败").WithCause(err)
+	}
+	return s.toResponse(reloaded, userID), nil
+}
+
+// needsModeration 开启先审后发，或评论包含的链接数达到审核阈值时需要审核
+func (s *CommentService) needsModeration(content string) bool {
+	if s.settings != nil && s.settings.Bool(SettingCommentModeration) {
+		return true
+	}
+	threshold := s.moderationLinks.Load()
+	if threshold <= 0 {
+		return false
+	}
+	lower := strings.ToLower(content)
+	links := strings.Count(lower, "http://") + strings.Count(lower, "https://")
+	return int64(links) >= threshold
 }
 
 func (s *CommentService) GetByID(id uint64, userID uint64) (*response.CommentResponse, error) {
@@ -86,34 +137,25 @@ func (s *CommentService) GetByID(id uint64, userID uint64) (*response.CommentRes
 	return s.toResponse(comment, userID), nil
 }
 
-func (s *CommentService) ListByArticle(articleID uint64, req *request.ListCommentRequest, userID uint64) (*response.CommentListResponse, error) {
-	if req.Page <= 0 {
-		req.Page = 1
-	}
-	if req.PageSize <= 0 {
-		req.PageSize = 20
+// ListByArticle 分页查询文章评论，文章对当前用户不可见时返回404
+func (s *CommentService) ListByArticle(articleID uint64, req *request.ListCommentRequest, userID uint64, role string, shareToken string) (*response.CommentListResponse, error) {
+	if err := s.checkArticle(articleID, userID, role, shareToken); err != nil {
+		return nil, err
 	}
 
-	comments, total, err := s.commentRepo.ListByArticle(articleID, req.Page, req.PageSize)
+	page, err := pageQuery(req.Page, req.PageSize, req.Cursor)
 	if err != nil {
-		return nil, errors.NewInternalError("查询评论列表失败")
+		return nil, err
 	}
 
-	items := make([]response.CommentResponse, len(comments))
-	for i, comment := range comments {
-		items[i] = *s.toResponse(&comment, userID)
+	comments, total, next, err := s.commentRepo.ListByArticle(articleID, page)
+	if err != nil {
+		return nil, listError(err, "查询评论列表失败")
 	}
 
-	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
-
 	return &response.CommentListResponse{
-		Items: items,
-		Pagination: response.Pagination{
-			Page:       req.Page,
-			PageSize:   req.PageSize,
-			Total:      total,
-			TotalPages: totalPages,
-		},
+		Items:      s.toResponses(comments, userID),
+		Pagination: toPagination(page, total, next),
 	}, nil
 }
 
@@ -127,6 +169,7 @@ func (s *CommentService) Update(id uint64, req *request.UpdateCommentRequest, us
 	if comment.UserID != userID {
 		return nil, errors.NewForbiddenError("无权限修改此评论")
 	}
+	before := *comment
 
 	// 转义HTML
 	contentHTML := html.EscapeString(req.Content)
@@ -136,11 +179,15 @@ func (s *CommentService) Update(id uint64, req *request.UpdateCommentRequest, us
 	comment.ContentHTML = contentHTML
 
 	if err := s.commentRepo.Update(comment); err != nil {
-		return nil, errors.NewInternalError("更新评论失败")
+		return nil, errors.NewInternalError("更新评论失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionUpdate, AuditTargetComment, id, &before, comment)
 
-	comment, _ = s.commentRepo.GetByID(id)
-	return s.toResponse(comment, userID), nil
+	reloaded, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.NewInternalError("查询评论失败").WithCause(err)
+	}
+	return s.toResponse(reloaded, userID), nil
 }
 
 func (s *CommentService) Delete(id uint64, userID uint64) error {
@@ -154,22 +201,143 @@ func (s *CommentService) Delete(id uint64, userID uint64) error {
 		return errors.NewForbiddenError("无权限删除此评论")
 	}
 
-	// 如果是回复，减少父评论的回复计数
-	if comment.ParentID != nil && *comment.ParentID > 0 {
-		go s.commentRepo.DecrementReplyCount(*comment.ParentID)
+	// 评论与父评论回复数、文章评论数在同一事务中更新
+	if err := s.commentRepo.DeleteWithCounters(comment); err != nil {
+		return errors.NewInternalError("删除评论失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionDelete, AuditTargetComment, id, comment, nil)
+	invalidateArticle(s.cache, comment.ArticleID)
 
-	// 减少文章的评论计数
-	go s.articleRepo.DecrementCommentCount(comment.ArticleID)
+	return nil
+}
 
-	if err := s.commentRepo.Delete(id); err != nil {
-		return errors.NewInternalError("删除评论失败")
+// ListPending 分页查询待审核评论
+func (s *CommentService) ListPending(req *request.ListCommentRequest) (*response.CommentListResponse, error) {
+	if req.Page <= 0 {
+		req.Page = 1
 	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	comments, total, err := s.commentRepo.ListByStatus("pending", req.Page, req.PageSize)
+	if err != nil {
+		return nil, errors.NewInternalError("查询待审核评论失败").WithCause(err)
+	}
+
+	items := s.toResponses(comments, 0)
 
+	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+
+	return &response.CommentListResponse{
+		Items: items,
+		Pagination: response.Pagination{
+			Page:       req.Page,
+			PageSize:   req.PageSize,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+// Approve 审核通过评论
+func (s *CommentService) Approve(id uint64) (*response.CommentResponse, error) {
+	comment, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("评论不存在")
+	}
+
+	published, err := s.commentRepo.PublishWithCounters(comment)
+	if err != nil {
+		return nil, errors.NewInternalError("审核评论失败").WithCause(err)
+	}
+	if !published {
+		return nil, errors.NewBadRequestError("评论不是待审核状态")
+	}
+	before := *comment
+	comment.Status = "published"
+	s.audit.Record(AuditActionApprove, AuditTargetComment, id, &before, comment)
+	invalidateArticle(s.cache, comment.ArticleID)
+
+	reloaded, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.NewInternalError("查询评论失败").WithCause(err)
+	}
+	return s.toResponse(reloaded, 0), nil
+}
+
+// Reject 拒绝待审核评论，评论保留为 rejected 状态，不再出现在待审核列表和文章评论中
+func (s *CommentService) Reject(id uint64) (*response.CommentResponse, error) {
+	comment, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("评论不存在")
+	}
+
+	rejected, err := s.commentRepo.Reject(id)
+	if err != nil {
+		return nil, errors.NewInternalError("审核评论失败").WithCause(err)
+	}
+	if !rejected {
+		return nil, errors.NewBadRequestError("评论不是待审核状态")
+	}
+	before := *comment
+	comment.Status = "rejected"
+	s.audit.Record(AuditActionReject, AuditTargetComment, id, &before, comment)
+
+	return s.toResponse(comment, 0), nil
+}
+
+// checkArticle 检查文章存在且对当前用户可见，草稿、私有文章与不存在的文章一样返回404
+func (s *CommentService) checkArticle(articleID uint64, userID uint64, role string, shareToken string) error {
+	article, err := s.articleRepo.GetVisibility(articleID)
+	if err != nil ||
+		!articleViewable(article.AuthorID, article.Status, article.Visibility, article.ShareToken, userID, role, shareToken) {
+		return errors.NewNotFoundError("文章不存在")
+	}
 	return nil
 }
 
 func (s *CommentService) toResponse(comment *model.Comment, userID uint64) *response.CommentResponse {
+	return buildCommentResponse(comment, s.likedComments(userID, collectCommentIDs(nil, comment)))
+}
+
+// toResponses 转换评论列表，所有评论及其父评论、回复的点赞状态一次查出
+func (s *CommentService) toResponses(comments []model.Comment, userID uint64) []response.CommentResponse {
+	var ids []uint64
+	for i := range comments {
+		ids = collectCommentIDs(ids, &comments[i])
+	}
+	liked := s.likedComments(userID, ids)
+
+	items := make([]response.CommentResponse, len(comments))
+	for i := range comments {
+		items[i] = *buildCommentResponse(&comments[i], liked)
+	}
+	return items
+}
+
+// likedComments 返回当前用户已点赞的评论，未登录或查询失败时返回空
+func (s *CommentService) likedComments(userID uint64, ids []uint64) map[uint64]bool {
+	if userID == 0 || s.likeRepo == nil {
+		return nil
+	}
+	liked, _ := s.likeRepo.LikedTargets(userID, "comment", ids)
+	return liked
+}
+
+// collectCommentIDs 将评论及其已加载的父评论、回复的ID追加到 ids
+func collectCommentIDs(ids []uint64, comment *model.Comment) []uint64 {
+	ids = append(ids, comment.ID)
+	if comment.Parent != nil {
+		ids = collectCommentIDs(ids, comment.Parent)
+	}
+	for i := range comment.Replies {
+		ids = collectCommentIDs(ids, &comment.Replies[i])
+	}
+	return ids
+}
+
+func buildCommentResponse(comment *model.Comment, liked map[uint64]bool) *response.CommentResponse {
 	resp := &response.CommentResponse{
 		ID:          comment.ID,
 		ArticleID:   comment.ArticleID,
@@ -182,25 +350,19 @@ func (s *CommentService) toResponse(comment *model.Comment, userID uint64) *resp
 		Status:      comment.Status,
 		CreatedAt:   comment.CreatedAt,
 		UpdatedAt:   comment.UpdatedAt,
-	}
-
-	// 检查当前用户是否点赞
-	if userID > 0 {
-		isLiked, _ := s.likeRepo.IsLikedByUser(userID, "comment", comment.ID)
-		resp.IsLiked = isLiked
+		IsLiked:     liked[comment.ID],
 	}
 
 	// 处理父评论
 	if comment.Parent != nil {
-		parentResp := s.toResponse(comment.Parent, userID)
-		resp.Parent = parentResp
+		resp.Parent = buildCommentResponse(comment.Parent, liked)
 	}
 
 	// 处理回复列表
 	if len(comment.Replies) > 0 {
 		replies := make([]response.CommentResponse, len(comment.Replies))
-		for i, reply := range comment.Replies {
-			replies[i] = *s.toResponse(&reply, userID)
+		for i := range comment.Replies {
+			replies[i] = *buildCommentResponse(&comment.Replies[i], liked)
 		}
 		resp.Replies = replies
 	}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"dbapp/internal/config"
+	"dbapp/internal/dto/request"
+	"dbapp/internal/dto/response"
+	"dbapp/internal/repository"
+	"dbapp/internal/test"
+	"dbapp/pkg/cache"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCommentService_ListByArticle_Cursor(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "测试文章")
+
+	commentService := NewCommentService(repository.NewCommentRepository(db), repository.NewArticleRepository(db),
+		repository.NewLikeRepository(db), cache.NewMemoryCache(time.Minute), nil, nil)
+	for i := 0; i < 5; i++ {
+		req := &request.CreateCommentRequest{ArticleID: article.ID, Content: fmt.Sprintf("评论%d", i)}
+		if _, err := commentService.Create(req, user.ID, "user", ""); err != nil {
+			t.Fatalf("创建评论失败: %v", err)
+		}
+	}
+
+	cursor := ""
+	var ids []uint64
+	for {
+		result, err := commentService.ListByArticle(article.ID, &request.ListCommentRequest{PageSize: 2, Cursor: &cursor}, 0, "", "")
+		if err != nil {
+			t.Fatalf("查询评论列表失败: %v", err)
+		}
+		for _, item := range result.Items {
+			ids = append(ids, item.ID)
+		}
+		if result.Pagination.NextCursor == "" {
+			break
+		}
+		cursor = result.Pagination.NextCursor
+	}
+
+	if len(ids) != 5 {
+		t.Fatalf("期望读取 5 条评论, 得到 %d", len(ids))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] >= ids[i-1] {
+			t.Errorf("评论应按时间倒序, 得到 %v", ids)
+			break
+		}
+	}
+
+	invalid := "bad"
+	if _, err := commentService.ListByArticle(article.ID, &request.ListCommentRequest{Cursor: &invalid}, 0, "", ""); err == nil {
+		t.Error("无效的游标应该返回错误")
+	}
+}
+
+// createCommentPage 创建 n 条顶级评论，每条带 replies 条回复，用户点赞其中一半的评论
+func createCommentPage(tb testing.TB, commentService *CommentService, likeRepo *repository.LikeRepository, articleID, userID uint64, n, replies int) {
+	for i := 0; i < n; i++ {
+		comment, err := commentService.Create(&request.CreateCommentRequest{ArticleID: articleID, Content: fmt.Sprintf("评论%d", i)}, userID, "user", "")
+		if err != nil {
+			tb.Fatalf("创建评论失败: %v", err)
+		}
+		ids := []uint64{comment.ID}
+		for j := 0; j < replies; j++ {
+			reply, err := commentService.Create(&request.CreateCommentRequest{
+				ArticleID: articleID,
+				ParentID:  &comment.ID,
+				Content:   fmt.Sprintf("回复%d-%d", i, j),
+			}, userID, "user", "")
+			if err != nil {
+				tb.Fatalf("创建回复失败: %v", err)
+			}
+			ids = append(ids, reply.ID)
+		}
+		for k, id := range ids {
+			if k%2 == 0 {
+				likeRepo.Toggle(userID, "comment", id)
+			}
+		}
+	}
+}
+
+func TestCommentService_ListByArticle_IsLiked(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "测试文章")
+	likeRepo := repository.NewLikeRepository(db)
+	commentService := NewCommentService(repository.NewCommentRepository(db), repository.NewArticleRepository(db),
+		likeRepo, cache.NewMemoryCache(time.Minute), nil, nil)
+	createCommentPage(t, commentService, likeRepo, article.ID, user.ID, 3, 3)
+
+	countQueries := test.CountQueries(db)
+	result, err := commentService.ListByArticle(article.ID, &request.ListCommentRequest{PageSize: 20}, user.ID, "", "")
+	if err != nil {
+		t.Fatalf("查询评论列表失败: %v", err)
+	}
+	// 文章可见性、统计总数、评论、用户、回复、回复的用户、点赞状态
+	if queries := countQueries(); queries > 7 {
+		t.Errorf("查询次数应与评论数量无关, 得到 %d 次", queries)
+	}
+
+	for _, item := range result.Items {
+		if !item.IsLiked {
+			t.Errorf("评论 %d 应为已点赞", item.ID)
+		}
+		for j, reply := range item.Replies {
+			// 每组中评论本身为第0个，回复从第1个开始，偶数位已点赞
+			if want := (j+1)%2 == 0; reply.IsLiked != want {
+				t.Errorf("回复 %d 点赞状态期望 %v, 得到 %v", reply.ID, want, reply.IsLiked)
+			}
+		}
+	}
+}
+
+func BenchmarkCommentService_ListByArticle(b *testing.B) {
+	db := test.SetupTestDB(b)
+	defer test.TeardownTestDB(db)
+
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "测试文章")
+	likeRepo := repository.NewLikeRepository(db)
+	commentService := NewCommentService(repository.NewCommentRepository(db), repository.NewArticleRepository(db),
+		likeRepo, cache.NewMemoryCache(time.Minute), nil, nil)
+	createCommentPage(b, commentService, likeRepo, article.ID, user.ID, 20, 3)
+
+	req := &request.ListCommentRequest{Page: 1, PageSize: 20}
+	countQueries := test.CountQueries(db)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := commentService.ListByArticle(article.ID, req, user.ID, "", ""); err != nil {
+			b.Fatalf("查询评论列表失败: %v", err)
+		}
+	}
+	b.ReportMetric(float64(countQueries())/float64(b.N), "queries/op")
+}
+
+func TestCommentService_ArticleVisibility(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleRepo := repository.NewArticleRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	likeRepo := repository.NewLikeRepository(db)
+	appCache := cache.NewMemoryCache(time.Minute)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         repository.NewUserRepository(db),
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: repository.NewArticleImageRepository(db),
+		Cache:            appCache,
+	})
+	commentService := NewCommentService(commentRepo, articleRepo, likeRepo, appCache, nil, nil)
+	likeService := NewLikeService(likeRepo, articleRepo, commentRepo, appCache)
+
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	reader := test.CreateTestUser(db, "reader", "reader@example.com")
+
+	create := func(title, status, visibility string) *response.ArticleResponse {
+		t.Helper()
+		article, err := articleService.Create(&request.CreateArticleRequest{
+			Title: title, Content: "内容", Status: status, Visibility: visibility,
+		}, author.ID)
+		if err != nil {
+			t.Fatalf("创建文章失败: %v", err)
+		}
+		return article
+	}
+	draft := create("草稿", "draft", "")
+	private := create("私有", "published", "private")
+	unlisted := create("不公开", "published", "unlisted")
+
+	for _, id := range []uint64{draft.ID, private.ID} {
+		comment, err := commentService.Create(&request.CreateCommentRequest{ArticleID: id, Content: "作者评论"}, author.ID, "user", "")
+		if err != nil {
+			t.Fatalf("作者应能评论自己的文章: %v", err)
+		}
+		if _, err := commentService.ListByArticle(id, &request.ListCommentRequest{}, 0, "", ""); !isAppError(err, 404) {
+			t.Errorf("文章 %d 对未登录用户不可见, 评论列表应返回404, 得到 %v", id, err)
+		}
+		if _, err := commentService.Create(&request.CreateCommentRequest{ArticleID: id, Content: "评论"}, reader.ID, "user", ""); !isAppError(err, 404) {
+			t.Errorf("不能评论不可见的文章 %d, 得到 %v", id, err)
+		}
+		if _, err := likeService.ToggleLike(reader.ID, "user", "article", id, ""); !isAppError(err, 404) {
+			t.Errorf("不能点赞不可见的文章 %d, 得到 %v", id, err)
+		}
+		if _, err := likeService.ToggleLike(reader.ID, "user", "comment", comment.ID, ""); !isAppError(err, 404) {
+			t.Errorf("不能点赞不可见文章 %d 的评论, 得到 %v", id, err)
+		}
+		if _, err := commentService.ListByArticle(id, &request.ListCommentRequest{}, reader.ID, "editor", ""); err != nil {
+			t.Errorf("编辑应能查看文章 %d 的评论: %v", id, err)
+		}
+	}
+
+	// 不公开的文章凭分享令牌可查看评论、评论和点赞
+	if _, err := commentService.ListByArticle(unlisted.ID, &request.ListCommentRequest{}, 0, "", ""); !isAppError(err, 404) {
+		t.Errorf("没有分享令牌不能查看不公开文章的评论, 得到 %v", err)
+	}
+	if _, err := commentService.ListByArticle(unlisted.ID, &request.ListCommentRequest{}, 0, "", unlisted.ShareToken); err != nil {
+		t.Errorf("凭分享令牌应能查看评论: %v", err)
+	}
+	if _, err := commentService.Create(&request.CreateCommentRequest{ArticleID: unlisted.ID, Content: "评论"}, reader.ID, "user", unlisted.ShareToken); err != nil {
+		t.Errorf("凭分享令牌应能发表评论: %v", err)
+	}
+	if liked, err := likeService.ToggleLike(reader.ID, "user", "article", unlisted.ID, unlisted.ShareToken); err != nil || !liked {
+		t.Errorf("凭分享令牌应能点赞: %v", err)
+	}
+}
+
+func TestCommentService_ModerationLinks(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "测试文章")
+
+	commentService := NewCommentService(repository.NewCommentRepository(db), repository.NewArticleRepository(db),
+		repository.NewLikeRepository(db), cache.NewMemoryCache(time.Minute), nil, nil)
+	links := "看这里 https://a.example.com 和 HTTP://b.example.com"
+
+	cases := []struct {
+		threshold int
+		content   string
+		role      string
+		want      string
+	}{
+		{0, links, "user", "published"},
+		{2, "没有链接", "user", "published"},
+		{2, "只有一个 https://a.example.com", "user", "published"},
+		{2, links, "user", "pending"},
+		{2, links, "admin", "published"},
+		{3, links, "user", "published"},
+	}
+	for _, c := range cases {
+		// 模拟配置重新加载，修改对已创建的副本同样生效
+		svc := commentService.WithContext(context.Background())
+		commentService.UpdateConfig(config.CommentConfig{ModerationLinks: c.threshold})
+		comment, err := svc.Create(&request.CreateCommentRequest{ArticleID: article.ID, Content: c.content}, user.ID, c.role, "")
+		if err != nil {
+			t.Fatalf("创建评论失败: %v", err)
+		}
+		if comment.Status != c.want {
+			t.Errorf("阈值 %d, 角色 %s, 内容 %q: 期望状态 %s, 得到 %s", c.threshold, c.role, c.content, c.want, comment.Status)
+		}
+	}
+}
@@ -1,71 +1,87 @@
 package service
 
 import (
+	"context"
 	"dbapp/internal/errors"
-	"dbapp/internal/model"
 	"dbapp/internal/repository"
+	"dbapp/pkg/cache"
 )
 
 type LikeService struct {
 	likeRepo    *repository.LikeRepository
 	articleRepo *repository.ArticleRepository
 	commentRepo *repository.CommentRepository
+	cache       cache.Cache
 }
 
 func NewLikeService(
 	likeRepo *repository.LikeRepository,
 	articleRepo *repository.ArticleRepository,
 	commentRepo *repository.CommentRepository,
+	cache cache.Cache,
 ) *LikeService {
 	return &LikeService{
 		likeRepo:    likeRepo,
 		articleRepo: articleRepo,
 		commentRepo: commentRepo,
+		cache:       cache,
 	}
 }
 
-func (s *LikeService) ToggleLike(userID uint64, targetType string, targetID uint64) (bool, error) {
-	// 检查是否已点赞
-	existing, err := s.likeRepo.GetByUserAndTarget(userID, targetType, targetID)
-	if err != nil {
-		return false, errors.NewInternalError("查询点赞状态失败")
-	}
+// WithContext 返回在 ctx 下访问数据库和缓存的副本，用于把SQL和Redis的追踪span关联到当前请求
+func (s *LikeService) WithContext(ctx context.Context) *LikeService {
+	clone := *s
+	clone.likeRepo = s.likeRepo.WithContext(ctx)
+	clone.articleRepo = s.articleRepo.WithContext(ctx)
+	clone.commentRepo = s.commentRepo.WithContext(ctx)
+	clone.cache = cache.WithContext(s.cache, ctx)
+	return &clone
+}
 
-	if existing != nil {
-		// 已点赞，取消点赞
-		if err := s.likeRepo.Delete(userID, targetType, targetID); err != nil {
-			return false, errors.NewInternalError("取消点赞失败")
-		}
+// ToggleLike 点赞或取消点赞文章、评论，只能操作当前用户可查看的文章及其评论，
+// 不公开的文章需提供分享令牌
+func (s *LikeService) ToggleLike(userID uint64, role string, targetType string, targetID uint64, shareToken string) (bool, error) {
+	if err := s.checkTarget(userID, role, targetType, targetID, shareToken); err != nil {
+		return false, err
+	}
 
-		// 减少计数
-		if targetType == "article" {
-			go s.articleRepo.DecrementLikeCount(targetID)
-		} else if targetType == "comment" {
-			go s.commentRepo.DecrementLikeCount(targetID)
-		}
+	// 点赞记录与计数在同一事务中更新
+	isLiked, err := s.likeRepo.Toggle(userID, targetType, targetID)
+	if err != nil {
+		return false, errors.NewInternalError("点赞操作失败").WithCause(err)
+	}
+	action := "unlike"
+	if isLiked {
+		action = "like"
+	}
+	likesTotal.WithLabelValues(targetType, action).Inc()
 
-		return false, nil
-	} else {
-		// 未点赞，添加点赞
-		like := &model.Like{
-			UserID:     userID,
-			TargetType: targetType,
-			TargetID:   targetID,
-		}
+	if targetType == "article" {
+		invalidateArticle(s.cache, targetID)
+	}
 
-		if err := s.likeRepo.Create(like); err != nil {
-			return false, errors.NewInternalError("点赞失败")
-		}
+	return isLiked, nil
+}
 
-		// 增加计数
-		if targetType == "article" {
-			go s.articleRepo.IncrementLikeCount(targetID)
-		} else if targetType == "comment" {
-			go s.commentRepo.IncrementLikeCount(targetID)
+// checkTarget 检查点赞对象所属的文章对当前用户可见，不可见时与不存在一样返回404
+func (s *LikeService) checkTarget(userID uint64, role string, targetType string, targetID uint64, shareToken string) error {
+	articleID := targetID
+	notFound := errors.NewNotFoundError("文章不存在")
+	if targetType == "comment" {
+		comment, err := s.commentRepo.GetByID(targetID)
+		if err != nil || comment == nil {
+			return errors.NewNotFoundError("评论不存在")
 		}
+		articleID = comment.ArticleID
+		notFound = errors.NewNotFoundError("评论不存在")
+	}
 
-		return true, nil
+	article, err := s.articleRepo.GetVisibility(articleID)
+	if err != nil ||
+		!articleViewable(article.AuthorID, article.Status, article.Visibility, article.ShareToken, userID, role, shareToken) {
+		return notFound
 	}
+	return nil
 }
 
 func (s *LikeService) IsLiked(userID uint64, targetType string, targetID uint64) (bool, error) {
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"dbapp/internal/config"
+	"dbapp/pkg/cache"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	loginFailPrefix = "login:failures:"
+	loginLockPrefix = "login:lock:"
+)
+
+// LoginGuard 记录登录失败次数，连续失败达到上限后临时锁定账户。
+// 首次锁定 BaseDuration，之后每多失败一次锁定时长翻倍，最长 MaxDuration。
+// 失败记录按用户名保存，用户名不存在时同样计数，避免通过锁定行为判断用户是否存在。
+// 失败次数用缓存的原子自增累计，并发的失败请求不会互相覆盖计数。
+type LoginGuard struct {
+	cache cache.Cache
+	mu    sync.RWMutex
+	cfg   config.LockoutConfig
+	now   func() time.Time
+}
+
+type loginLock struct {
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+func NewLoginGuard(c cache.Cache, cfg config.LockoutConfig) *LoginGuard {
+	return &LoginGuard{cache: c, cfg: cfg, now: time.Now}
+}
+
+// SetConfig 配置重新加载时更新锁定策略，已锁定的账户按原锁定时间解锁
+func (g *LoginGuard) SetConfig(cfg config.LockoutConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cfg = cfg
+}
+
+func (g *LoginGuard) config() config.LockoutConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.cfg
+}
+
+// Locked 返回账户剩余的锁定时间，未锁定时返回0
+func (g *LoginGuard) Locked(username string) time.Duration {
+	var lock loginLock
+	data, err := g.cache.Get(loginLockPrefix + g.key(username))
+	if err != nil || json.Unmarshal(data, &lock) != nil {
+		return 0
+	}
+	if remaining := lock.LockedUntil.Sub(g.now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Fail 记录一次登录失败，返回本次触发的锁定时长，未触发锁定时返回0
+func (g *LoginGuard) Fail(username string) time.Duration {
+	cfg := g.config()
+	key := g.key(username)
+	window := time.Duration(cfg.Window) * time.Second
+	count, err := g.cache.Incr(loginFailPrefix+key, window)
+	if err != nil || cfg.MaxAttempts <= 0 || count < int64(cfg.MaxAttempts) {
+		return 0
+	}
+
+	lock := lockDuration(cfg, int(count)-cfg.MaxAttempts)
+	// 失败次数至少保留到锁定结束，锁定结束后再次失败时锁定时长继续翻倍
+	if lock > window {
+		g.cache.Expire(loginFailPrefix+key, lock)
+	}
+	if data, err := json.Marshal(loginLock{LockedUntil: g.now().Add(lock)}); err == nil {
+		g.cache.Set(loginLockPrefix+key, data, lock)
+	}
+	return lock
+}
+
+// Reset 登录成功后清除失败记录
+func (g *LoginGuard) Reset(username string) {
+	key := g.key(username)
+	g.cache.Delete(loginFailPrefix+key, loginLockPrefix+key)
+}
+
+// lockDuration 第 n 次（从0开始）超出上限时的锁定时长
+func lockDuration(cfg config.LockoutConfig, n int) time.Duration {
+	base := time.Duration(cfg.BaseDuration) * time.Second
+	max := time.Duration(cfg.MaxDuration) * time.Second
+	d := base
+	for i := 0; i < n && d < max; i++ {
+		d *= 2
+	}
+	if max > 0 && d > max {
+		d = max
+	}
+	return d
+}
+
+func (g *LoginGuard) key(username string) string {
+	return strings.ToLower(username)
+}
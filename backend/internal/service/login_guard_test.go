@@ -0,0 +1,125 @@
+package service
+
+import (
+	"dbapp/internal/config"
+	"dbapp/internal/dto/request"
+	"dbapp/internal/errors"
+	"dbapp/internal/repository"
+	"dbapp/internal/test"
+	"dbapp/pkg/cache"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestLoginGuard(now *time.Time) *LoginGuard {
+	g := NewLoginGuard(cache.NewMemoryCache(time.Minute), config.LockoutConfig{
+		MaxAttempts:  3,
+		BaseDuration: 60,
+		MaxDuration:  300,
+		Window:       900,
+	})
+	g.now = func() time.Time { return *now }
+	return g
+}
+
+func TestLoginGuard_ProgressiveLockout(t *testing.T) {
+	now := time.Now()
+	g := newTestLoginGuard(&now)
+
+	for i := 0; i < 2; i++ {
+		if lock := g.Fail("alice"); lock != 0 {
+			t.Fatalf("第 %d 次失败不应锁定", i+1)
+		}
+	}
+	if lock := g.Fail("alice"); lock != time.Minute {
+		t.Fatalf("达到上限后应锁定 1m, 得到 %s", lock)
+	}
+	if g.Locked("Alice") != time.Minute {
+		t.Error("用户名不区分大小写")
+	}
+
+	// 锁定结束后再次失败，锁定时长翻倍，最长不超过上限
+	expected := []time.Duration{2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for _, want := range expected {
+		now = now.Add(g.Locked("alice"))
+		if g.Locked("alice") != 0 {
+			t.Fatal("锁定时间结束后应解锁")
+		}
+		if lock := g.Fail("alice"); lock != want {
+			t.Errorf("期望锁定 %s, 得到 %s", want, lock)
+		}
+	}
+
+	g.Reset("alice")
+	if g.Locked("alice") != 0 {
+		t.Error("重置后应解锁")
+	}
+	if lock := g.Fail("alice"); lock != 0 {
+		t.Error("重置后失败次数应重新计算")
+	}
+}
+
+func TestLoginGuard_ConcurrentFailures(t *testing.T) {
+	now := time.Now()
+	g := newTestLoginGuard(&now)
+
+	// 并发的失败请求都应计入，不能因为读-改-写互相覆盖而少算
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.Fail("alice")
+		}()
+	}
+	wg.Wait()
+
+	if data, err := g.cache.Get(loginFailPrefix + "alice"); err != nil || string(data) != "20" {
+		t.Fatalf("期望失败次数为 20, 得到 %q (%v)", data, err)
+	}
+	if g.Locked("alice") == 0 {
+		t.Error("并发失败达到上限后应锁定")
+	}
+}
+
+func TestUserService_Login_Lockout(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	now := time.Now()
+	userRepo := repository.NewUserRepository(db)
+	userService := NewUserService(userRepo, newTestLoginGuard(&now), nil, nil)
+
+	_, err := userService.Register(&request.RegisterRequest{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("创建测试用户失败: %v", err)
+	}
+
+	wrong := &request.LoginRequest{Username: "testuser", Password: "wrongpassword"}
+	for i := 0; i < 2; i++ {
+		_, err := userService.Login(wrong)
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != 401 {
+			t.Fatalf("第 %d 次失败应返回 401, 得到 %v", i+1, err)
+		}
+	}
+	_, err = userService.Login(wrong)
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != 429 || appErr.RetryAfter != time.Minute {
+		t.Fatalf("达到上限应返回 429 并设置 RetryAfter, 得到 %v", err)
+	}
+
+	// 锁定期间密码正确也不能登录
+	correct := &request.LoginRequest{Username: "testuser", Password: "password123"}
+	if _, err := userService.Login(correct); err == nil {
+		t.Fatal("锁定期间不应登录成功")
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := userService.Login(correct); err != nil {
+		t.Fatalf("锁定结束后应能登录: %v", err)
+	}
+}
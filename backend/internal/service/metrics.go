@@ -0,0 +1,17 @@
+package service
+
+import "dbapp/pkg/metrics"
+
+// 业务指标，只在操作成功后计数
+var (
+	articlesCreatedTotal = metrics.Default.NewCounterVec(
+		"dbapp_articles_created_total", "创建的文章数", "status")
+	commentsCreatedTotal = metrics.Default.NewCounter(
+		"dbapp_comments_created_total", "发表的评论数")
+	likesTotal = metrics.Default.NewCounterVec(
+		"dbapp_likes_total", "点赞和取消点赞次数", "target_type", "action")
+	uploadsTotal = metrics.Default.NewCounter(
+		"dbapp_uploads_total", "上传完成的文件数")
+	uploadBytesTotal = metrics.Default.NewCounter(
+		"dbapp_upload_bytes_total", "上传完成的文件总字节数")
+)
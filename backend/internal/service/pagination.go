@@ -0,0 +1,59 @@
+package service
+
+import (
+	"dbapp/internal/dto/response"
+	"dbapp/internal/errors"
+	"dbapp/internal/repository"
+)
+
+// maxPageSize 列表每页的最大数量
+const maxPageSize = 100
+
+// pageQuery 根据请求参数确定分页方式：请求中带有 cursor 参数时（值为空表示第一页）使用游标分页，否则按页码分页
+func pageQuery(page, pageSize int, cursor *string) (repository.PageQuery, error) {
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	if cursor != nil {
+		c, err := repository.DecodeCursor(*cursor)
+		if err != nil {
+			return repository.PageQuery{}, errors.NewBadRequestError("无效的分页游标")
+		}
+		return repository.PageQuery{PageSize: pageSize, Cursor: c}, nil
+	}
+	if page <= 0 {
+		page = 1
+	}
+	return repository.PageQuery{Page: page, PageSize: pageSize}, nil
+}
+
+// listError 将仓储层的列表查询错误转换为业务错误
+func listError(err error, message string) error {
+	if err == repository.ErrInvalidCursor {
+		return errors.NewBadRequestError("无效的分页游标，游标需与排序参数一致，且排序字段支持游标分页")
+	}
+	if err == repository.ErrInvalidSort {
+		return errors.NewBadRequestError("不支持的排序字段")
+	}
+	return errors.NewInternalError(message).WithCause(err)
+}
+
+// toPagination 生成分页信息，游标分页不统计总数，page、total、total_pages 为 0
+func toPagination(query repository.PageQuery, total int64, next *repository.Cursor) response.Pagination {
+	pagination := response.Pagination{
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Total:    total,
+	}
+	if query.IsCursor() {
+		if next != nil {
+			pagination.NextCursor = next.Encode()
+		}
+		return pagination
+	}
+	pagination.TotalPages = int((total + int64(query.PageSize) - 1) / int64(query.PageSize))
+	return pagination
+}
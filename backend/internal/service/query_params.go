@@ -0,0 +1,38 @@
+package service
+
+import (
+	"dbapp/internal/errors"
+	"time"
+)
+
+// parseTimeRange 解析列表查询的时间范围参数，为空的一端返回 nil。
+// 参数可以是 RFC3339 时间或 2006-01-02 日期，日期按服务器本地时区的零点计算；
+// 结束时间只给日期时包含当天，返回的结束时间不包含在范围内。
+func parseTimeRange(from, to string) (start, end *time.Time, err error) {
+	if from != "" {
+		t, _, err := parseTimeParam(from)
+		if err != nil {
+			return nil, nil, errors.NewBadRequestError("无效的开始时间: " + from)
+		}
+		start = &t
+	}
+	if to != "" {
+		t, isDate, err := parseTimeParam(to)
+		if err != nil {
+			return nil, nil, errors.NewBadRequestError("无效的结束时间: " + to)
+		}
+		if isDate {
+			t = t.AddDate(0, 0, 1)
+		}
+		end = &t
+	}
+	return start, end, nil
+}
+
+func parseTimeParam(value string) (t time.Time, isDate bool, err error) {
+	if t, err = time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err = time.ParseInLocation("2006-01-02", value, time.Local)
+	return t, true, err
+}
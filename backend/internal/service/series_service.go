@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"dbapp/internal/dto/request"
+	"dbapp/internal/dto/response"
+	"dbapp/internal/errors"
+	"dbapp/internal/model"
+	"dbapp/internal/repository"
+)
+
+// SeriesService 文章系列：作者把自己的多篇文章按顺序组织成系列，文章详情中返回系列内的前后篇。
+// 系列由作者和管理员维护，系列中只能包含系列作者的文章，一篇文章最多属于一个系列。
+type SeriesService struct {
+	seriesRepo  *repository.SeriesRepository
+	articleRepo *repository.ArticleRepository
+	audit       *AuditService
+}
+
+func NewSeriesService(seriesRepo *repository.SeriesRepository, articleRepo *repository.ArticleRepository, audit *AuditService) *SeriesService {
+	return &SeriesService{
+		seriesRepo:  seriesRepo,
+		articleRepo: articleRepo,
+		audit:       audit,
+	}
+}
+
+// WithContext 返回在 ctx 下访问数据库的副本
+func (s *SeriesService) WithContext(ctx context.Context) *SeriesService {
+	clone := *s
+	clone.seriesRepo = s.seriesRepo.WithContext(ctx)
+	clone.articleRepo = s.articleRepo.WithContext(ctx)
+	clone.audit = s.audit.WithContext(ctx)
+	return &clone
+}
+
+func (s *SeriesService) Create(req *request.CreateSeriesRequest, userID uint64) (*response.SeriesResponse, error) {
+	series := &model.Series{
+		Title:       req.Title,
+		Description: req.Description,
+		AuthorID:    userID,
+	}
+	if err := s.checkArticles(series, req.ArticleIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.seriesRepo.Create(series); err != nil {
+		return nil, errors.NewInternalError("创建系列失败").WithCause(err)
+	}
+	if len(req.ArticleIDs) > 0 {
+		if err := s.seriesRepo.SetArticles(series.ID, req.ArticleIDs); err != nil {
+			return nil, errors.NewInternalError("设置系列文章失败").WithCause(err)
+		}
+	}
+	s.audit.Record(AuditActionCreate, AuditTargetSeries, series.ID, nil, series)
+
+	return s.GetByID(series.ID, userID, "")
+}
+
+// GetByID 获取系列详情，文章列表中只包含当前用户可见的文章
+func (s *SeriesService) GetByID(id uint64, userID uint64, role string) (*response.SeriesResponse, error) {
+	series, err := s.seriesRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("系列不存在")
+	}
+	articles, err := s.seriesRepo.Articles(id)
+	if err != nil {
+		return nil, errors.NewInternalError("查询系列文章失败").WithCause(err)
+	}
+
+	resp := toSeriesResponse(series)
+	visible := visibleSeriesArticles(articles, userID, role, 0)
+	resp.Articles = make([]response.SeriesArticleResponse, len(visible))
+	for i, article := range visible {
+		resp.Articles[i] = response.SeriesArticleResponse{
+			ID:            article.ID,
+			Title:         article.Title,
+			Slug:          article.Slug,
+			Summary:       article.Summary,
+			CoverImageURL: article.CoverImageURL,
+			Position:      i + 1,
+			Status:        article.Status,
+			Visibility:    article.Visibility,
+			PublishedAt:   article.PublishedAt,
+		}
+	}
+	return resp, nil
+}
+
+func (s *SeriesService) List(req *request.ListSeriesRequest) (*response.SeriesListResponse, error) {
+	page, _ := pageQuery(req.Page, req.PageSize, nil)
+	list, total, err := s.seriesRepo.List(req.AuthorID, page.Page, page.PageSize)
+	if err != nil {
+		return nil, errors.NewInternalError("查询系列列表失败").WithCause(err)
+	}
+
+	items := make([]*response.SeriesResponse, len(list))
+	for i := range list {
+		items[i] = toSeriesResponse(&list[i])
+	}
+	return &response.SeriesListResponse{
+		Items:      items,
+		Pagination: toPagination(page, total, nil),
+	}, nil
+}
+
+func (s *SeriesService) Update(id uint64, req *request.UpdateSeriesRequest, userID uint64, role string) (*response.SeriesResponse, error) {
+	series, err := s.manageable(id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	before := *series
+
+	if req.Title != "" {
+		series.Title = req.Title
+	}
+	if req.Description != nil {
+		series.Description = *req.Description
+	}
+
+	if err := s.seriesRepo.Update(series); err != nil {
+		return nil, errors.NewInternalError("更新系列失败").WithCause(err)
+	}
+	s.audit.Record(AuditActionUpdate, AuditTargetSeries, id, &before, series)
+
+	return s.GetByID(id, userID, role)
+}
+
+// Delete 删除系列，系列中的文章不受影响
+func (s *SeriesService) Delete(id uint64, userID uint64, role string) error {
+	series, err := s.manageable(id, userID, role)
+	if err != nil {
+		return err
+	}
+
+	if err := s.seriesRepo.Delete(id); err != nil {
+		return errors.NewInternalError("删除系列失败").WithCause(err)
+	}
+	s.audit.Record(AuditActionDelete, AuditTargetSeries, id, series, nil)
+	return nil
+}
+
+// SetArticles 按给定顺序设置系列中的全部文章，未列出的文章从系列中移除
+func (s *SeriesService) SetArticles(id uint64, articleIDs []uint64, userID uint64, role string) (*response.SeriesResponse, error) {
+	series, err := s.manageable(id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.articleIDs(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkArticles(series, articleIDs); err != nil {
+		return nil, err
+	}
+	if err := s.setArticles(series, current, articleIDs); err != nil {
+		return nil, err
+	}
+	return s.GetByID(id, userID, role)
+}
+
+// AddArticle 向系列添加文章，position 为插入位置（从 1 开始），为 0 或超出末尾时添加到末尾
+func (s *SeriesService) AddArticle(id uint64, articleID uint64, position int, userID uint64, role string) (*response.SeriesResponse, error) {
+	series, err := s.manageable(id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.articleIDs(id)
+	if err != nil {
+		return nil, err
+	}
+	if indexOf(current, articleID) >= 0 {
+		return nil, errors.NewBadRequestError("文章已在系列中")
+	}
+
+	index := len(current)
+	if position > 0 && position <= len(current) {
+		index = position - 1
+	}
+	articleIDs := make([]uint64, 0, len(current)+1)
+	articleIDs = append(articleIDs, current[:index]...)
+	articleIDs = append(articleIDs, articleID)
+	articleIDs = append(articleIDs, current[index:]...)
+
+	if err := s.checkArticles(series, articleIDs); err != nil {
+		return nil, err
+	}
+	if err := s.setArticles(series, current, articleIDs); err != nil {
+		return nil, err
+	}
+	return s.GetByID(id, userID, role)
+}
+
+// RemoveArticle 从系列中移除文章，文章本身不受影响
+func (s *SeriesService) RemoveArticle(id uint64, articleID uint64, userID uint64, role string) error {
+	series, err := s.manageable(id, userID, role)
+	if err != nil {
+		return err
+	}
+	current, err := s.articleIDs(id)
+	if err != nil {
+		return err
+	}
+	index := indexOf(current, articleID)
+	if index < 0 {
+		return errors.NewNotFoundError("文章不在系列中")
+	}
+
+	articleIDs := make([]uint64, 0, len(current)-1)
+	articleIDs = append(articleIDs, current[:index]...)
+	articleIDs = append(articleIDs, current[index+1:]...)
+	return s.setArticles(series, current, articleIDs)
+}
+
+// manageable 查询系列并检查当前用户能否维护，系列作者和管理员可以维护
+func (s *SeriesService) manageable(id uint64, userID uint64, role string) (*model.Series, error) {
+	series, err := s.seriesRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("系列不存在")
+	}
+	if series.AuthorID != userID && !model.HasRole(role, model.RoleAdmin) {
+		return nil, errors.NewForbiddenError("无权限修改此系列")
+	}
+	return series, nil
+}
+
+// articleIDs 按顺序返回系列中未删除的文章ID
+func (s *SeriesService) articleIDs(id uint64) ([]uint64, error) {
+	articles, err := s.seriesRepo.Articles(id)
+	if err != nil {
+		return nil, errors.NewInternalError("查询系列文章失败").WithCause(err)
+	}
+	ids := make([]uint64, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].ID
+	}
+	return ids, nil
+}
+
+// checkArticles 检查文章能否放入系列：不重复、存在、属于系列作者且不在其他系列中
+func (s *SeriesService) checkArticles(series *model.Series, articleIDs []uint64) error {
+	if len(articleIDs) == 0 {
+		return nil
+	}
+	seen := make(map[uint64]bool, len(articleIDs))
+	for _, id := range articleIDs {
+		if seen[id] {
+			return errors.NewBadRequestError("文章ID重复")
+		}
+		seen[id] = true
+	}
+
+	articles, err := s.articleRepo.GetByIDs(articleIDs)
+	if err != nil {
+		return errors.NewInternalError("查询文章失败").WithCause(err)
+	}
+	if len(articles) != len(articleIDs) {
+		return errors.NewNotFoundError("文章不存在")
+	}
+	for _, article := range articles {
+		if article.AuthorID != series.AuthorID {
+			return errors.NewBadRequestError("系列中只能包含系列作者的文章")
+		}
+	}
+
+	owners, err := s.seriesRepo.SeriesOfArticles(articleIDs)
+	if err != nil {
+		return errors.NewInternalError("查询文章所属系列失败").WithCause(err)
+	}
+	for _, seriesID := range owners {
+		if seriesID != series.ID {
+			return errors.NewBadRequestError("文章已属于其他系列")
+		}
+	}
+	return nil
+}
+
+func (s *SeriesService) setArticles(series *model.Series, before, after []uint64) error {
+	if err := s.seriesRepo.SetArticles(series.ID, after); err != nil {
+		return errors.NewInternalError("设置系列文章失败").WithCause(err)
+	}
+	s.audit.Record(AuditActionUpdate, AuditTargetSeries, series.ID,
+		map[string][]uint64{"article_ids": before}, map[string][]uint64{"article_ids": after})
+	return nil
+}
+
+// seriesNavigation 返回文章所在系列及前后篇，文章不属于任何系列时返回 nil。
+// 前后篇跳过当前用户不可见的文章，当前文章本身总是计入（用户已能查看该文章）。
+func seriesNavigation(seriesRepo *repository.SeriesRepository, articleID uint64, userID uint64, role string) (*response.SeriesNavigation, error) {
+	if seriesRepo == nil {
+		return nil, nil
+	}
+	member, err := seriesRepo.GetByArticle(articleID)
+	if err != nil || member == nil {
+		return nil, err
+	}
+	series, err := seriesRepo.GetByID(member.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	articles, err := seriesRepo.Articles(member.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := visibleSeriesArticles(articles, userID, role, articleID)
+	nav := &response.SeriesNavigation{
+		ID:    series.ID,
+		Title: series.Title,
+		Total: len(visible),
+	}
+	for i, article := range visible {
+		if article.ID != articleID {
+			continue
+		}
+		nav.Position = i + 1
+		if i > 0 {
+			nav.Prev = seriesArticleLink(&visible[i-1])
+		}
+		if i < len(visible)-1 {
+			nav.Next = seriesArticleLink(&visible[i+1])
+		}
+	}
+	return nav, nil
+}
+
+// visibleSeriesArticles 过滤出当前用户在列表中可见的文章，currentID 对应的文章总是保留
+func visibleSeriesArticles(articles []model.Article, userID uint64, role string, currentID uint64) []model.Article {
+	visible := make([]model.Article, 0, len(articles))
+	for _, article := range articles {
+		if article.ID == currentID || articleListed(article.AuthorID, article.Status, article.Visibility, userID, role) {
+			visible = append(visible, article)
+		}
+	}
+	return visible
+}
+
+func seriesArticleLink(article *model.Article) *response.SeriesArticleLink {
+	return &response.SeriesArticleLink{
+		ID:    article.ID,
+		Title: article.Title,
+		Slug:  article.Slug,
+	}
+}
+
+func toSeriesResponse(series *model.Series) *response.SeriesResponse {
+	return &response.SeriesResponse{
+		ID:          series.ID,
+		Title:       series.Title,
+		Description: series.Description,
+		Author: &response.UserResponse{
+			ID:        series.Author.ID,
+			Username:  series.Author.Username,
+			Nickname:  series.Author.Nickname,
+			AvatarURL: series.Author.AvatarURL,
+		},
+		CreatedAt: series.CreatedAt,
+		UpdatedAt: series.UpdatedAt,
+	}
+}
+
+func indexOf(ids []uint64, id uint64) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"dbapp/internal/dto/request"
+	"dbapp/internal/dto/response"
+	"dbapp/internal/errors"
+	"dbapp/internal/model"
+	"dbapp/internal/repository"
+	"dbapp/internal/test"
+	"dbapp/pkg/cache"
+	"testing"
+	"time"
+)
+
+func TestSeriesService_ManageArticles(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	seriesService := NewSeriesService(repository.NewSeriesRepository(db), repository.NewArticleRepository(db), nil)
+
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	other := test.CreateTestUser(db, "other", "other@example.com")
+	a1 := test.CreateTestArticle(db, author.ID, "第一篇")
+	a2 := test.CreateTestArticle(db, author.ID, "第二篇")
+	a3 := test.CreateTestArticle(db, author.ID, "第三篇")
+	foreign := test.CreateTestArticle(db, other.ID, "其他作者的文章")
+
+	series, err := seriesService.Create(&request.CreateSeriesRequest{
+		Title:      "入门系列",
+		ArticleIDs: []uint64{a1.ID, a2.ID},
+	}, author.ID)
+	if err != nil {
+		t.Fatalf("创建系列失败: %v", err)
+	}
+
+	// 插入到第一位
+	series, err = seriesService.AddArticle(series.ID, a3.ID, 1, author.ID, "user")
+	if err != nil {
+		t.Fatalf("添加文章失败: %v", err)
+	}
+	assertSeriesOrder(t, series.Articles, a3.ID, a1.ID, a2.ID)
+
+	// 调整顺序
+	series, err = seriesService.SetArticles(series.ID, []uint64{a1.ID, a2.ID, a3.ID}, author.ID, "user")
+	if err != nil {
+		t.Fatalf("调整顺序失败: %v", err)
+	}
+	assertSeriesOrder(t, series.Articles, a1.ID, a2.ID, a3.ID)
+
+	if err := seriesService.RemoveArticle(series.ID, a2.ID, author.ID, "user"); err != nil {
+		t.Fatalf("移除文章失败: %v", err)
+	}
+	series, _ = seriesService.GetByID(series.ID, 0, "")
+	assertSeriesOrder(t, series.Articles, a1.ID, a3.ID)
+
+	// 其他作者的文章、重复的文章不能加入系列，其他用户不能维护系列
+	if _, err := seriesService.AddArticle(series.ID, foreign.ID, 0, author.ID, "user"); !isAppError(err, 400) {
+		t.Errorf("添加其他作者的文章应返回400, 得到 %v", err)
+	}
+	if _, err := seriesService.SetArticles(series.ID, []uint64{a1.ID, a1.ID}, author.ID, "user"); !isAppError(err, 400) {
+		t.Errorf("重复的文章应返回400, 得到 %v", err)
+	}
+	if _, err := seriesService.SetArticles(series.ID, []uint64{a1.ID}, other.ID, "user"); !isAppError(err, 403) {
+		t.Errorf("非作者维护系列应返回403, 得到 %v", err)
+	}
+
+	// 一篇文章只能属于一个系列
+	second, _ := seriesService.Create(&request.CreateSeriesRequest{Title: "第二个系列"}, author.ID)
+	if _, err := seriesService.AddArticle(second.ID, a1.ID, 0, author.ID, "user"); !isAppError(err, 400) {
+		t.Errorf("文章已属于其他系列应返回400, 得到 %v", err)
+	}
+	if _, err := seriesService.AddArticle(second.ID, a2.ID, 0, author.ID, "user"); err != nil {
+		t.Errorf("移出系列的文章应能加入其他系列: %v", err)
+	}
+}
+
+func TestSeriesService_SetArticlesKeepsTrashedAfterNewOrder(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleService, _, trashService := newTestTrashServices(db)
+	seriesService := NewSeriesService(repository.NewSeriesRepository(db), repository.NewArticleRepository(db), nil)
+
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	a1 := test.CreateTestArticle(db, author.ID, "第一篇")
+	a2 := test.CreateTestArticle(db, author.ID, "第二篇")
+	a3 := test.CreateTestArticle(db, author.ID, "第三篇")
+	series, _ := seriesService.Create(&request.CreateSeriesRequest{
+		Title:      "入门系列",
+		ArticleIDs: []uint64{a1.ID, a2.ID, a3.ID},
+	}, author.ID)
+
+	// 第一篇在回收站期间重新设置顺序，恢复后排在新设置的文章之后，位置不与其他文章重复
+	if err := articleService.Delete(a1.ID, author.ID); err != nil {
+		t.Fatalf("删除文章失败: %v", err)
+	}
+	if _, err := seriesService.SetArticles(series.ID, []uint64{a3.ID, a2.ID}, author.ID, "user"); err != nil {
+		t.Fatalf("调整顺序失败: %v", err)
+	}
+	if err := trashService.RestoreArticle(a1.ID, author.ID, "user"); err != nil {
+		t.Fatalf("恢复文章失败: %v", err)
+	}
+
+	series, _ = seriesService.GetByID(series.ID, 0, "")
+	assertSeriesOrder(t, series.Articles, a3.ID, a2.ID, a1.ID)
+	for i, article := range series.Articles {
+		if article.Position != i+1 {
+			t.Errorf("第 %d 篇文章的位置应为 %d, 得到 %d", i+1, i+1, article.Position)
+		}
+	}
+}
+
+func TestArticleService_SeriesNavigation(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	seriesRepo := repository.NewSeriesRepository(db)
+	articleRepo := repository.NewArticleRepository(db)
+	seriesService := NewSeriesService(seriesRepo, articleRepo, nil)
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         repository.NewUserRepository(db),
+		LikeRepo:         repository.NewLikeRepository(db),
+		ArticleImageRepo: repository.NewArticleImageRepository(db),
+		SeriesRepo:       seriesRepo,
+		Cache:            cache.NewMemoryCache(time.Minute),
+	})
+
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	a1 := test.CreateTestArticle(db, author.ID, "第一篇")
+	draft := test.CreateTestArticle(db, author.ID, "草稿")
+	a3 := test.CreateTestArticle(db, author.ID, "第三篇")
+	db.Model(&model.Article{}).Where("id = ?", draft.ID).Update("status", "draft")
+
+	series, err := seriesService.Create(&request.CreateSeriesRequest{
+		Title:      "入门系列",
+		ArticleIDs: []uint64{a1.ID, draft.ID, a3.ID},
+	}, author.ID)
+	if err != nil {
+		t.Fatalf("创建系列失败: %v", err)
+	}
+
+	// 其他用户看不到草稿，前后篇跳过草稿
+	article, err := articleService.GetByID(a1.ID, 0, "", "")
+	if err != nil {
+		t.Fatalf("获取文章失败: %v", err)
+	}
+	nav := article.Series
+	if nav == nil || nav.ID != series.ID || nav.Position != 1 || nav.Total != 2 {
+		t.Fatalf("系列导航错误: %+v", nav)
+	}
+	if nav.Prev != nil || nav.Next == nil || nav.Next.ID != a3.ID {
+		t.Errorf("第一篇的下一篇应为第三篇, 得到 prev=%+v next=%+v", nav.Prev, nav.Next)
+	}
+
+	// 作者可以看到草稿
+	article, _ = articleService.GetByID(a3.ID, author.ID, "user", "")
+	nav = article.Series
+	if nav == nil || nav.Position != 3 || nav.Total != 3 || nav.Prev == nil || nav.Prev.ID != draft.ID || nav.Next != nil {
+		t.Errorf("作者看到的系列导航错误: %+v", nav)
+	}
+
+	// 删除的文章不出现在系列中
+	if err := articleService.Delete(a3.ID, author.ID); err != nil {
+		t.Fatalf("删除文章失败: %v", err)
+	}
+	article, _ = articleService.GetByID(a1.ID, 0, "", "")
+	if article.Series == nil || article.Series.Total != 1 || article.Series.Next != nil {
+		t.Errorf("删除的文章不应出现在系列导航中: %+v", article.Series)
+	}
+}
+
+func assertSeriesOrder(t *testing.T, articles []response.SeriesArticleResponse, ids ...uint64) {
+	t.Helper()
+	if len(articles) != len(ids) {
+		t.Fatalf("期望 %d 篇文章, 得到 %d", len(ids), len(articles))
+	}
+	for i, id := range ids {
+		if articles[i].ID != id || articles[i].Position != i+1 {
+			t.Errorf("第 %d 篇期望文章 %d, 得到文章 %d (位置 %d)", i+1, id, articles[i].ID, articles[i].Position)
+		}
+	}
+}
+
+func isAppError(err error, code int) bool {
+	appErr, ok := err.(*errors.AppError)
+	return ok && appErr.Code == code
+}
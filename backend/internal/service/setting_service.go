@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"dbapp/internal/dto/response"
+	"dbapp/internal/errors"
+	"dbapp/internal/repository"
+	"dbapp/pkg/cache"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// 站点设置键
+const (
+	SettingSiteName             = "site_name"
+	SettingAnnouncement         = "announcement"
+	SettingRegistrationOpen     = "registration_open"
+	SettingCommentModeration    = "comment_moderation"
+	SettingDefaultArticleStatus = "default_article_status"
+)
+
+// settingDefinition 描述一个设置的类型、默认值以及是否对未登录用户公开
+type settingDefinition struct {
+	Key     string
+	Public  bool
+	Default interface{}
+	// parse 校验JSON值并返回对应的Go值，保存和读取时都会调用
+	parse func(raw json.RawMessage) (interface{}, error)
+}
+
+func stringSetting(maxLen int, allowEmpty bool) func(json.RawMessage) (interface{}, error) {
+	return func(raw json.RawMessage) (interface{}, error) {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("必须是字符串")
+		}
+		if !allowEmpty && value == "" {
+			return nil, fmt.Errorf("不能为空")
+		}
+		if utf8.RuneCountInString(value) > maxLen {
+			return nil, fmt.Errorf("长度不能超过%d", maxLen)
+		}
+		return value, nil
+	}
+}
+
+func boolSetting(raw json.RawMessage) (interface{}, error) {
+	var value bool
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("必须是布尔值")
+	}
+	return value, nil
+}
+
+func enumSetting(values ...string) func(json.RawMessage) (interface{}, error) {
+	return func(raw json.RawMessage) (interface{}, error) {
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil {
+			for _, v := range values {
+				if value == v {
+					return value, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("必须是 %v 之一", values)
+	}
+}
+
+func settingDefinitions(siteName string) []settingDefinition {
+	return []settingDefinition{
+		{Key: SettingSiteName, Public: true, Default: siteName, parse: stringSetting(100, false)},
+		{Key: SettingAnnouncement, Public: true, Default: "", parse: stringSetting(500, true)},
+		{Key: SettingRegistrationOpen, Public: true, Default: true, parse: boolSetting},
+		{Key: SettingCommentModeration, Public: true, Default: false, parse: boolSetting},
+		{Key: SettingDefaultArticleStatus, Public: false, Default: "draft", parse: enumSetting("draft", "published")},
+	}
+}
+
+type SettingService struct {
+	settingRepo *repository.SettingRepository
+	cache       cache.Cache
+	definitions []settingDefinition
+}
+
+// NewSettingService siteName 为站点名称的默认值，通常取 AppConfig.Name
+func NewSettingService(settingRepo *repository.SettingRepository, cache cache.Cache, siteName string) *SettingService {
+	return &SettingService{
+		settingRepo: settingRepo,
+		cache:       cache,
+		definitions: settingDefinitions(siteName),
+	}
+}
+
+// WithContext 返回在 ctx 下访问数据库和缓存的副本，用于把SQL和Redis的追踪span关联到当前请求
+func (s *SettingService) WithContext(ctx context.Context) *SettingService {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	clone.settingRepo = s.settingRepo.WithContext(ctx)
+	clone.cache = cache.WithContext(s.cache, ctx)
+	return &clone
+}
+
+func (s *SettingService) definition(key string) (settingDefinition, bool) {
+	for _, def := range s.definitions {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return settingDefinition{}, false
+}
+
+// stored 返回数据库中保存的设置原始JSON值，结果会被缓存
+func (s *SettingService) stored() (map[string]string, error) {
+	var values map[string]string
+	err := cache.Remember(s.cache, settingsCacheKey, 0, &values, func() (interface{}, error) {
+		settings, err := s.settingRepo.All()
+		if err != nil {
+			return nil, errors.NewInternalError("查询站点设置失败").WithCause(err)
+		}
+		values := make(map[string]string, len(settings))
+		for _, setting := range settings {
+			values[setting.Key] = setting.Value
+		}
+		return values, nil
+	})
+	return values, err
+}
+
+// values 合并已保存的值和默认值，已保存的值不再合法（如定义变更）时使用默认值
+func (s *SettingService) values(publicOnly bool) (map[string]interface{}, error) {
+	stored, err := s.stored()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{}, len(s.definitions))
+	for _, def := range s.definitions {
+		if publicOnly && !def.Public {
+			continue
+		}
+		result[def.Key] = def.Default
+		if raw, ok := stored[def.Key]; ok {
+			if value, err := def.parse(json.RawMessage(raw)); err == nil {
+				result[def.Key] = value
+			}
+		}
+	}
+	return result, nil
+}
+
+// All 返回全部设置，供管理员查看
+func (s *SettingService) All() (map[string]interface{}, error) {
+	return s.values(false)
+}
+
+// Public 返回可公开给前端的设置
+func (s *SettingService) Public() (map[string]interface{}, error) {
+	return s.values(true)
+}
+
+// Update 校验并保存设置，只修改提交的键，值未变化的不会产生修改记录
+func (s *SettingService) Update(values map[string]json.RawMessage, userID uint64) (map[string]interface{}, error) {
+	if len(values) == 0 {
+		return nil, errors.NewBadRequestError("未提供要修改的设置")
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	encoded := make(map[string]string, len(values))
+	for _, key := range keys {
+		def, ok := s.definition(key)
+		if !ok {
+			return nil, errors.NewBadRequestError("未知的设置: " + key)
+		}
+		value, err := def.parse(values[key])
+		if err != nil {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("设置 %s 无效: %v", key, err))
+		}
+		// 重新编码，保证保存的JSON是规范形式，便于比较是否变化
+		data, _ := json.Marshal(value)
+		encoded[key] = string(data)
+	}
+
+	if err := s.settingRepo.Save(encoded, userID); err != nil {
+		return nil, errors.NewInternalError("保存站点设置失败").WithCause(err)
+	}
+	invalidateSettings(s.cache)
+
+	return s.All()
+}
+
+// History 分页查询设置修改记录，key 为空时查询全部
+func (s *SettingService) History(key string, page, pageSize int) (*response.SettingChangeListResponse, error) {
+	if key != "" {
+		if _, ok := s.definition(key); !ok {
+			return nil, errors.NewBadRequestError("未知的设置: " + key)
+		}
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	changes, total, err := s.settingRepo.ListChanges(key, page, pageSize)
+	if err != nil {
+		return nil, errors.NewInternalError("查询设置修改记录失败").WithCause(err)
+	}
+
+	items := make([]response.SettingChangeResponse, len(changes))
+	for i, change := range changes {
+		items[i] = response.SettingChangeResponse{
+			ID:        change.ID,
+			Key:       change.Key,
+			NewValue:  json.RawMessage(change.NewValue),
+			ChangedBy: *toUserResponse(&change.User),
+			CreatedAt: change.CreatedAt,
+		}
+		if change.OldValue != nil {
+			items[i].OldValue = json.RawMessage(*change.OldValue)
+		}
+	}
+
+	return &response.SettingChangeListResponse{
+		Items: items,
+		Pagination: response.Pagination{
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+		},
+	}, nil
+}
+
+// Bool 读取布尔类型的设置，读取失败时返回默认值，避免设置存储故障影响主流程
+func (s *SettingService) Bool(key string) bool {
+	value, _ := s.get(key).(bool)
+	return value
+}
+
+// String 读取字符串类型的设置，读取失败时返回默认值
+func (s *SettingService) String(key string) string {
+	value, _ := s.get(key).(string)
+	return value
+}
+
+func (s *SettingService) get(key string) interface{} {
+	def, ok := s.definition(key)
+	if !ok {
+		return nil
+	}
+	values, err := s.values(false)
+	if err != nil {
+		return def.Default
+	}
+	return values[key]
+}
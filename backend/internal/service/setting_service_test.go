@@ -0,0 +1,257 @@
+package service
+
+import (
+	"dbapp/internal/dto/request"
+	"dbapp/internal/model"
+	"dbapp/internal/repository"
+	"dbapp/internal/test"
+	"dbapp/pkg/cache"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSettingService_Defaults(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	settingService := NewSettingService(repository.NewSettingRepository(db), cache.NewMemoryCache(time.Minute), "测试站点")
+
+	all, err := settingService.All()
+	if err != nil {
+		t.Fatalf("查询设置失败: %v", err)
+	}
+	if all[SettingSiteName] != "测试站点" {
+		t.Errorf("站点名称默认值应为配置中的名称，得到 %v", all[SettingSiteName])
+	}
+	if all[SettingRegistrationOpen] != true {
+		t.Errorf("默认应开放注册，得到 %v", all[SettingRegistrationOpen])
+	}
+
+	public, err := settingService.Public()
+	if err != nil {
+		t.Fatalf("查询公开设置失败: %v", err)
+	}
+	if _, ok := public[SettingDefaultArticleStatus]; ok {
+		t.Error("公开设置不应包含文章默认状态")
+	}
+}
+
+func TestSettingService_Update(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	admin := test.CreateTestUser(db, "admin", "admin@example.com")
+	settingService := NewSettingService(repository.NewSettingRepository(db), cache.NewMemoryCache(time.Minute), "测试站点")
+
+	// 先读取一次，确认修改后缓存会失效
+	if settingService.Bool(SettingCommentModeration) {
+		t.Fatal("默认不应开启评论审核")
+	}
+
+	_, err := settingService.Update(map[string]json.RawMessage{
+		SettingCommentModeration: json.RawMessage(`true`),
+		SettingAnnouncement:      json.RawMessage(`"系统维护通知"`),
+	}, admin.ID)
+	if err != nil {
+		t.Fatalf("修改设置失败: %v", err)
+	}
+
+	if !settingService.Bool(SettingCommentModeration) {
+		t.Error("修改后应开启评论审核")
+	}
+	if got := settingService.String(SettingAnnouncement); got != "系统维护通知" {
+		t.Errorf("期望公告 系统维护通知, 得到 %s", got)
+	}
+
+	// 值未变化时不产生修改记录
+	if _, err := settingService.Update(map[string]json.RawMessage{
+		SettingCommentModeration: json.RawMessage(`true`),
+	}, admin.ID); err != nil {
+		t.Fatalf("修改设置失败: %v", err)
+	}
+
+	history, err := settingService.History("", 1, 20)
+	if err != nil {
+		t.Fatalf("查询修改记录失败: %v", err)
+	}
+	if history.Pagination.Total != 2 {
+		t.Fatalf("期望 2 条修改记录, 得到 %d", history.Pagination.Total)
+	}
+	for _, item := range history.Items {
+		if item.OldValue != nil {
+			t.Errorf("首次修改的旧值应为空，得到 %s", item.OldValue)
+		}
+		if item.ChangedBy.Username != "admin" {
+			t.Errorf("期望修改人 admin, 得到 %s", item.ChangedBy.Username)
+		}
+	}
+}
+
+func TestSettingService_Update_Invalid(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	admin := test.CreateTestUser(db, "admin", "admin@example.com")
+	settingService := NewSettingService(repository.NewSettingRepository(db), cache.NewMemoryCache(time.Minute), "测试站点")
+
+	cases := map[string]map[string]json.RawMessage{
+		"未知的键":   {"unknown": json.RawMessage(`1`)},
+		"类型错误":   {SettingRegistrationOpen: json.RawMessage(`"yes"`)},
+		"不在可选值":  {SettingDefaultArticleStatus: json.RawMessage(`"archived"`)},
+		"站点名称为空": {SettingSiteName: json.RawMessage(`""`)},
+	}
+	for name, values := range cases {
+		if _, err := settingService.Update(values, admin.ID); err == nil {
+			t.Errorf("%s: 应该返回错误", name)
+		}
+	}
+
+	// 校验失败时不应保存任何设置
+	var count int64
+	db.Model(&model.Setting{}).Count(&count)
+	if count != 0 {
+		t.Errorf("校验失败时不应保存设置，得到 %d 条", count)
+	}
+}
+
+func TestUserService_Register_Closed(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	admin := test.CreateTestUser(db, "admin", "admin@example.com")
+	settingService := NewSettingService(repository.NewSettingRepository(db), cache.NewMemoryCache(time.Minute), "测试站点")
+	if _, err := settingService.Update(map[string]json.RawMessage{
+		SettingRegistrationOpen: json.RawMessage(`false`),
+	}, admin.ID); err != nil {
+		t.Fatalf("修改设置失败: %v", err)
+	}
+
+	userService := NewUserService(repository.NewUserRepository(db), nil, settingService, nil)
+	_, err := userService.Register(&request.RegisterRequest{
+		Username: "newuser",
+		Email:    "newuser@example.com",
+		Password: "password123",
+	})
+	if err == nil {
+		t.Error("关闭注册后应该返回错误")
+	}
+}
+
+func TestCommentService_Moderation(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	admin := test.CreateTestUser(db, "admin", "admin@example.com")
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "测试文章")
+
+	appCache := cache.NewMemoryCache(time.Minute)
+	settingService := NewSettingService(repository.NewSettingRepository(db), appCache, "测试站点")
+	if _, err := settingService.Update(map[string]json.RawMessage{
+		SettingCommentModeration: json.RawMessage(`true`),
+	}, admin.ID); err != nil {
+		t.Fatalf("修改设置失败: %v", err)
+	}
+
+	articleRepo := repository.NewArticleRepository(db)
+	commentService := NewCommentService(repository.NewCommentRepository(db), articleRepo,
+		repository.NewLikeRepository(db), appCache, settingService, nil)
+
+	comment, err := commentService.Create(&request.CreateCommentRequest{ArticleID: article.ID, Content: "待审核"}, user.ID, "user", "")
+	if err != nil {
+		t.Fatalf("创建评论失败: %v", err)
+	}
+	if comment.Status != "pending" {
+		t.Fatalf("开启审核后评论状态应为 pending, 得到 %s", comment.Status)
+	}
+
+	list, _ := commentService.ListByArticle(article.ID, &request.ListCommentRequest{}, 0, "", "")
+	if list.Pagination.Total != 0 {
+		t.Errorf("待审核评论不应出现在文章评论列表中")
+	}
+	updated, _ := articleRepo.GetByID(article.ID)
+	if updated.CommentCount != 0 {
+		t.Errorf("待审核评论不应计入评论数，得到 %d", updated.CommentCount)
+	}
+
+	pending, err := commentService.ListPending(&request.ListCommentRequest{})
+	if err != nil {
+		t.Fatalf("查询待审核评论失败: %v", err)
+	}
+	if pending.Pagination.Total != 1 {
+		t.Fatalf("期望 1 条待审核评论, 得到 %d", pending.Pagination.Total)
+	}
+
+	if _, err := commentService.Approve(comment.ID); err != nil {
+		t.Fatalf("审核评论失败: %v", err)
+	}
+	if _, err := commentService.Approve(comment.ID); err == nil {
+		t.Error("重复审核应该返回错误")
+	}
+	updated, _ = articleRepo.GetByID(article.ID)
+	if updated.CommentCount != 1 {
+		t.Errorf("审核通过后评论数应为 1，得到 %d", updated.CommentCount)
+	}
+
+	// 管理员的评论不需要审核
+	comment, err = commentService.Create(&request.CreateCommentRequest{ArticleID: article.ID, Content: "管理员评论"}, admin.ID, "admin", "")
+	if err != nil {
+		t.Fatalf("创建评论失败: %v", err)
+	}
+	if comment.Status != "published" {
+		t.Errorf("管理员评论状态应为 published, 得到 %s", comment.Status)
+	}
+}
+
+func TestCommentService_Reject(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	admin := test.CreateTestUser(db, "admin", "admin@example.com")
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "测试文章")
+
+	appCache := cache.NewMemoryCache(time.Minute)
+	settingService := NewSettingService(repository.NewSettingRepository(db), appCache, "测试站点")
+	settingService.Update(map[string]json.RawMessage{SettingCommentModeration: json.RawMessage(`true`)}, admin.ID)
+
+	articleRepo := repository.NewArticleRepository(db)
+	auditService := NewAuditService(repository.NewAuditLogRepository(db))
+	commentService := NewCommentService(repository.NewCommentRepository(db), articleRepo,
+		repository.NewLikeRepository(db), appCache, settingService, auditService)
+
+	comment, err := commentService.Create(&request.CreateCommentRequest{ArticleID: article.ID, Content: "垃圾评论"}, user.ID, "user", "")
+	if err != nil {
+		t.Fatalf("创建评论失败: %v", err)
+	}
+
+	rejected, err := commentService.Reject(comment.ID)
+	if err != nil {
+		t.Fatalf("拒绝评论失败: %v", err)
+	}
+	if rejected.Status != "rejected" {
+		t.Errorf("期望状态 rejected, 得到 %s", rejected.Status)
+	}
+	if _, err := commentService.Reject(comment.ID); err == nil {
+		t.Error("重复拒绝应该返回错误")
+	}
+	if _, err := commentService.Approve(comment.ID); err == nil {
+		t.Error("已拒绝的评论不能再审核通过")
+	}
+
+	if pending, _ := commentService.ListPending(&request.ListCommentRequest{}); pending.Pagination.Total != 0 {
+		t.Errorf("拒绝后不应出现在待审核列表中, 得到 %d 条", pending.Pagination.Total)
+	}
+	if list, _ := commentService.ListByArticle(article.ID, &request.ListCommentRequest{}, 0, "", ""); list.Pagination.Total != 0 {
+		t.Errorf("拒绝的评论不应出现在文章评论列表中")
+	}
+	if updated, _ := articleRepo.GetByID(article.ID); updated.CommentCount != 0 {
+		t.Errorf("拒绝的评论不应计入评论数, 得到 %d", updated.CommentCount)
+	}
+
+	logs, _ := auditService.List(&request.ListAuditLogRequest{Action: AuditActionReject})
+	if logs.Pagination.Total != 1 || logs.Items[0].TargetID != comment.ID {
+		t.Errorf("拒绝评论应记录一条审计日志, 得到 %d 条", logs.Pagination.Total)
+	}
+}
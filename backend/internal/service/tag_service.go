@@ -1,24 +1,39 @@
 package service
 
 import (
+	"context"
 	"dbapp/internal/dto/request"
 	"dbapp/internal/dto/response"
 	"dbapp/internal/errors"
 	"dbapp/internal/model"
 	"dbapp/internal/repository"
+	"dbapp/pkg/cache"
 	"github.com/gosimple/slug"
 )
 
 type TagService struct {
 	tagRepo *repository.TagRepository
+	cache   cache.Cache
+	audit   *AuditService
 }
 
-func NewTagService(tagRepo *repository.TagRepository) *TagService {
+func NewTagService(tagRepo *repository.TagRepository, cache cache.Cache, audit *AuditService) *TagService {
 	return &TagService{
 		tagRepo: tagRepo,
+		cache:   cache,
+		audit:   audit,
 	}
 }
 
+// WithContext 返回在 ctx 下访问数据库和缓存的副本，用于把SQL和Redis的追踪span关联到当前请求
+func (s *TagService) WithContext(ctx context.Context) *TagService {
+	clone := *s
+	clone.tagRepo = s.tagRepo.WithContext(ctx)
+	clone.cache = cache.WithContext(s.cache, ctx)
+	clone.audit = s.audit.WithContext(ctx)
+	return &clone
+}
+
 func (s *TagService) Create(req *request.CreateTagRequest) (*response.TagResponse, error) {
 	// 如果未提供slug，自动生成
 	tagSlug := req.Slug
@@ -46,11 +61,16 @@ func (s *TagService) Create(req *request.CreateTagRequest) (*response.TagRespons
 	}
 
 	if err := s.tagRepo.Create(tag); err != nil {
-		return nil, errors.NewInternalError("创建标签失败")
+		return nil, errors.NewInternalError("创建标签失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionCreate, AuditTargetTag, tag.ID, nil, tag)
+	invalidateTagLists(s.cache)
 
-	tag, _ = s.tagRepo.GetByID(tag.ID)
-	return s.toResponse(tag), nil
+	reloaded, err := s.tagRepo.GetByID(tag.ID)
+	if err != nil {
+		return nil, errors.NewInternalError("查询标签失败").WithCause(err)
+	}
+	return s.toResponse(reloaded), nil
 }
 
 func (s *TagService) GetByID(id uint64) (*response.TagResponse, error) {
@@ -69,18 +89,39 @@ func (s *TagService) GetBySlug(slug string) (*response.TagResponse, error) {
 	return s.toResponse(tag), nil
 }
 
+// List 查询标签列表，limit 超过 maxPageSize 时按 maxPageSize 处理，小于等于0时不限制数量
 func (s *TagService) List(req *request.ListTagRequest) ([]response.TagResponse, error) {
-	tags, err := s.tagRepo.List(req.Keyword, req.Sort, req.Order, req.Limit)
-	if err != nil {
-		return nil, errors.NewInternalError("查询标签列表失败")
+	if req.Limit > maxPageSize {
+		req.Limit = maxPageSize
+	} else if req.Limit < 0 {
+		req.Limit = 0
 	}
 
-	items := make([]response.TagResponse, len(tags))
-	for i, tag := range tags {
-		items[i] = *s.toResponse(&tag)
+	load := func() (interface{}, error) {
+		tags, err := s.tagRepo.List(req.Keyword, req.Sort, req.Order, req.Limit)
+		if err != nil {
+			return nil, errors.NewInternalError("查询标签列表失败").WithCause(err)
+		}
+
+		items := make([]response.TagResponse, len(tags))
+		for i, tag := range tags {
+			items[i] = *s.toResponse(&tag)
+		}
+		return items, nil
+	}
+
+	key, ok := tagListCacheKey(req)
+	if !ok {
+		items, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return items.([]response.TagResponse), nil
 	}
 
-	return items, nil
+	var items []response.TagResponse
+	err := cache.Remember(s.cache, key, 0, &items, load)
+	return items, err
 }
 
 func (s *TagService) Update(id uint64, req *request.UpdateTagRequest) (*response.TagResponse, error) {
@@ -88,6 +129,7 @@ func (s *TagService) Update(id uint64, req *request.UpdateTagRequest) (*response
 	if err != nil {
 		return nil, errors.NewNotFoundError("标签不存在")
 	}
+	before := *tag
 
 	// 更新字段
 	if req.Name != "" {
@@ -106,11 +148,16 @@ func (s *TagService) Update(id uint64, req *request.UpdateTagRequest) (*response
 	}
 
 	if err := s.tagRepo.Update(tag); err != nil {
-		return nil, errors.NewInternalError("更新标签失败")
+		return nil, errors.NewInternalError("更新标签失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionUpdate, AuditTargetTag, id, &before, tag)
+	invalidateTagLists(s.cache)
 
-	tag, _ = s.tagRepo.GetByID(id)
-	return s.toResponse(tag), nil
+	reloaded, err := s.tagRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.NewInternalError("查询标签失败").WithCause(err)
+	}
+	return s.toResponse(reloaded), nil
 }
 
 func (s *TagService) Delete(id uint64) error {
@@ -125,8 +172,10 @@ func (s *TagService) Delete(id uint64) error {
 	}
 
 	if err := s.tagRepo.Delete(id); err != nil {
-		return errors.NewInternalError("删除标签失败")
+		return errors.NewInternalError("删除标签失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionDelete, AuditTargetTag, id, tag, nil)
+	invalidateTagLists(s.cache)
 
 	return nil
 }
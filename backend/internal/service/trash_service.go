@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"dbapp/internal/dto/request"
+	"dbapp/internal/dto/response"
+	"dbapp/internal/errors"
+	"dbapp/internal/model"
+	"dbapp/internal/repository"
+	"dbapp/pkg/cache"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TrashService 回收站：查看和恢复已删除的文章、评论，定期彻底删除超过保留时间的记录。
+// 管理员可以查看和恢复所有人的记录，其他用户只能查看和恢复自己的。
+// 管理员删除的用户也进入回收站，可以恢复；用户记录被文章、评论、文件等大量数据引用，不会被彻底删除。
+type TrashService struct {
+	userRepo    *repository.UserRepository
+	articleRepo *repository.ArticleRepository
+	commentRepo *repository.CommentRepository
+	trashRepo   *repository.TrashRepository
+	cache       cache.Cache
+	retention   time.Duration
+	audit       *AuditService
+}
+
+func NewTrashService(
+	userRepo *repository.UserRepository,
+	articleRepo *repository.ArticleRepository,
+	commentRepo *repository.CommentRepository,
+	trashRepo *repository.TrashRepository,
+	cache cache.Cache,
+	retention time.Duration,
+	audit *AuditService,
+) *TrashService {
+	return &TrashService{
+		userRepo:    userRepo,
+		articleRepo: articleRepo,
+		commentRepo: commentRepo,
+		trashRepo:   trashRepo,
+		cache:       cache,
+		retention:   retention,
+		audit:       audit,
+	}
+}
+
+// WithContext 返回在 ctx 下访问数据库和缓存的副本
+func (s *TrashService) WithContext(ctx context.Context) *TrashService {
+	clone := *s
+	clone.userRepo = s.userRepo.WithContext(ctx)
+	clone.articleRepo = s.articleRepo.WithContext(ctx)
+	clone.commentRepo = s.commentRepo.WithContext(ctx)
+	clone.cache = cache.WithContext(s.cache, ctx)
+	clone.audit = s.audit.WithContext(ctx)
+	return &clone
+}
+
+// ListArticles 分页查询回收站中的文章
+func (s *TrashService) ListArticles(req *request.ListTrashRequest, userID uint64, role string) (*response.DeletedArticleListResponse, error) {
+	page := trashPage(req)
+	articles, total, err := s.articleRepo.ListDeleted(trashOwner(userID, role), page.Page, page.PageSize)
+	if err != nil {
+		return nil, errors.NewInternalError("查询回收站失败").WithCause(err)
+	}
+
+	items := make([]response.DeletedArticleResponse, len(articles))
+	for i, article := range articles {
+		items[i] = response.DeletedArticleResponse{
+			ID:        article.ID,
+			Title:     article.Title,
+			Slug:      article.Slug,
+			Summary:   article.Summary,
+			Author:    toUserResponse(&article.Author),
+			Status:    article.Status,
+			DeletedAt: article.DeletedAt.Time,
+			PurgeAt:   article.DeletedAt.Time.Add(s.retention),
+		}
+	}
+
+	return &response.DeletedArticleListResponse{
+		Items:      items,
+		Pagination: toPagination(page, total, nil),
+	}, nil
+}
+
+// RestoreArticle 恢复文章，与文章一起删除的评论和点赞同时恢复
+func (s *TrashService) RestoreArticle(id uint64, userID uint64, role string) error {
+	article, err := s.articleRepo.GetDeleted(id)
+	if err != nil {
+		return errors.NewNotFoundError("回收站中没有该文章")
+	}
+	if owner := trashOwner(userID, role); owner > 0 && article.AuthorID != owner {
+		return errors.NewForbiddenError("无权限恢复此文章")
+	}
+
+	restored, err := s.articleRepo.Restore(article)
+	if err != nil {
+		return errors.NewInternalError("恢复文章失败").WithCause(err)
+	}
+	if !restored {
+		return errors.NewNotFoundError("回收站中没有该文章")
+	}
+	s.audit.Record(AuditActionRestore, AuditTargetArticle, id, nil, nil)
+
+	// 文章重新计入分类、标签的文章数
+	invalidateArticle(s.cache, id)
+	invalidateCategoryTree(s.cache)
+	invalidateTagLists(s.cache)
+	return nil
+}
+
+// ListComments 分页查询回收站中的评论，随文章一起删除的评论不单独列出
+func (s *TrashService) ListComments(req *request.ListTrashRequest, userID uint64, role string) (*response.DeletedCommentListResponse, error) {
+	page := trashPage(req)
+	comments, total, err := s.commentRepo.ListDeleted(trashOwner(userID, role), page.Page, page.PageSize)
+	if err != nil {
+		return nil, errors.NewInternalError("查询回收站失败").WithCause(err)
+	}
+
+	items := make([]response.DeletedCommentResponse, len(comments))
+	for i, comment := range comments {
+		items[i] = response.DeletedCommentResponse{
+			ID:        comment.ID,
+			ArticleID: comment.ArticleID,
+			ParentID:  comment.ParentID,
+			Content:   comment.Content,
+			User:      toUserResponse(&comment.User),
+			Status:    comment.Status,
+			DeletedAt: comment.DeletedAt.Time,
+			PurgeAt:   comment.DeletedAt.Time.Add(s.retention),
+		}
+	}
+
+	return &response.DeletedCommentListResponse{
+		Items:      items,
+		Pagination: toPagination(page, total, nil),
+	}, nil
+}
+
+// RestoreComment 恢复评论及其点赞，文章已删除时需先恢复文章
+func (s *TrashService) RestoreComment(id uint64, userID uint64, role string) error {
+	comment, err := s.commentRepo.GetDeleted(id)
+	if err != nil {
+		return errors.NewNotFoundError("回收站中没有该评论")
+	}
+	if owner := trashOwner(userID, role); owner > 0 && comment.UserID != owner {
+		return errors.NewForbiddenError("无权限恢复此评论")
+	}
+	if _, err := s.articleRepo.GetByID(comment.ArticleID); err != nil {
+		return errors.NewBadRequestError("评论所属的文章已删除，请先恢复文章")
+	}
+
+	restored, err := s.commentRepo.RestoreWithCounters(comment)
+	if err != nil {
+		return errors.NewInternalError("恢复评论失败").WithCause(err)
+	}
+	if !restored {
+		return errors.NewNotFoundError("回收站中没有该评论")
+	}
+	s.audit.Record(AuditActionRestore, AuditTargetComment, id, nil, nil)
+	invalidateArticle(s.cache, comment.ArticleID)
+	return nil
+}
+
+// DeleteUser 管理员删除用户，用户的文章及文章的评论和点赞一起移入回收站。
+// 用户在别人文章下的评论、点赞保留；已签发的Token在过期前仍然有效，但无法再登录
+func (s *TrashService) DeleteUser(id uint64, operatorID uint64) error {
+	if id == operatorID {
+		return errors.NewBadRequestError("不能删除自己")
+	}
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return errors.NewNotFoundError("用户不存在")
+	}
+
+	articleIDs, deleted, err := s.userRepo.DeleteWithArticles(id)
+	if err != nil {
+		return errors.NewInternalError("删除用户失败").WithCause(err)
+	}
+	if !deleted {
+		return errors.NewNotFoundError("用户不存在")
+	}
+	s.audit.Record(AuditActionDelete, AuditTargetUser, id, user, nil)
+	s.invalidateArticles(articleIDs)
+	return nil
+}
+
+// ListUsers 分页查询回收站中的用户，仅管理员可用
+func (s *TrashService) ListUsers(req *request.ListTrashRequest) (*response.DeletedUserListResponse, error) {
+	page := trashPage(req)
+	users, total, err := s.userRepo.ListDeleted(page.Page, page.PageSize)
+	if err != nil {
+		return nil, errors.NewInternalError("查询回收站失败").WithCause(err)
+	}
+
+	items := make([]response.DeletedUserResponse, len(users))
+	for i, user := range users {
+		items[i] = response.DeletedUserResponse{
+			ID:        user.ID,
+			Username:  user.Username,
+			Email:     user.Email,
+			Nickname:  user.Nickname,
+			Role:      user.Role,
+			DeletedAt: user.DeletedAt.Time,
+		}
+	}
+
+	return &response.DeletedUserListResponse{
+		Items:      items,
+		Pagination: toPagination(page, total, nil),
+	}, nil
+}
+
+// RestoreUser 恢复用户，与用户一起删除且尚未被彻底删除的文章同时恢复
+func (s *TrashService) RestoreUser(id uint64) error {
+	user, err := s.userRepo.GetDeleted(id)
+	if err != nil {
+		return errors.NewNotFoundError("回收站中没有该用户")
+	}
+
+	articleIDs, restored, err := s.userRepo.Restore(user)
+	if err != nil {
+		return errors.NewInternalError("恢复用户失败").WithCause(err)
+	}
+	if !restored {
+		return errors.NewNotFoundError("回收站中没有该用户")
+	}
+	s.audit.Record(AuditActionRestore, AuditTargetUser, id, nil, nil)
+	s.invalidateArticles(articleIDs)
+	return nil
+}
+
+// invalidateArticles 批量删除或恢复文章后清除文章详情及依赖文章数的分类树、标签列表缓存
+func (s *TrashService) invalidateArticles(ids []uint64) {
+	if len(ids) == 0 {
+		return
+	}
+	for _, id := range ids {
+		invalidateArticle(s.cache, id)
+	}
+	invalidateCategoryTree(s.cache)
+	invalidateTagLists(s.cache)
+}
+
+// Purge 彻底删除超过保留时间的文章、评论和点赞，返回各类记录删除的数量
+func (s *TrashService) Purge() (map[string]int64, error) {
+	return s.trashRepo.Purge(time.Now().Add(-s.retention))
+}
+
+// StartPurge 定期彻底删除超过保留时间的记录，返回停止函数，停止函数会等待正在进行的清理结束
+func (s *TrashService) StartPurge(interval time.Duration) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				purged, err := s.Purge()
+				if err != nil {
+					zap.L().Error("清理回收站失败", zap.String("error", err.Error()))
+				} else if purged["articles"]+purged["comments"]+purged["likes"] > 0 {
+					zap.L().Info("已清理回收站",
+						zap.Int64("articles", purged["articles"]),
+						zap.Int64("comments", purged["comments"]),
+						zap.Int64("likes", purged["likes"]))
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stop) })
+		<-done
+	}
+}
+
+// trashOwner 管理员查看所有人的回收站，返回 0；其他用户只能查看自己的
+func trashOwner(userID uint64, role string) uint64 {
+	if model.HasRole(role, model.RoleAdmin) {
+		return 0
+	}
+	return userID
+}
+
+func trashPage(req *request.ListTrashRequest) repository.PageQuery {
+	page, _ := pageQuery(req.Page, req.PageSize, nil)
+	return page
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"dbapp/internal/dto/request"
+	"dbapp/internal/model"
+	"dbapp/internal/repository"
+	"dbapp/internal/test"
+	"dbapp/pkg/cache"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func newTestTrashServices(db *gorm.DB) (*ArticleService, *CommentService, *TrashService) {
+	articleRepo := repository.NewArticleRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	likeRepo := repository.NewLikeRepository(db)
+	appCache := cache.NewMemoryCache(time.Minute)
+
+	articleService := NewArticleService(ArticleServiceDeps{
+		ArticleRepo:      articleRepo,
+		UserRepo:         repository.NewUserRepository(db),
+		LikeRepo:         likeRepo,
+		ArticleImageRepo: repository.NewArticleImageRepository(db),
+		Cache:            appCache,
+	})
+	commentService := NewCommentService(commentRepo, articleRepo, likeRepo, appCache, nil, nil)
+	trashService := NewTrashService(repository.NewUserRepository(db), articleRepo, commentRepo, repository.NewTrashRepository(db), appCache, 30*24*time.Hour, nil)
+	return articleService, commentService, trashService
+}
+
+func TestTrashService_RestoreArticle(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleService, commentService, trashService := newTestTrashServices(db)
+	likeRepo := repository.NewLikeRepository(db)
+
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	reader := test.CreateTestUser(db, "reader", "reader@example.com")
+	article := test.CreateTestArticle(db, author.ID, "测试文章")
+	tag := &model.Tag{Name: "标签", Slug: "tag"}
+	db.Create(tag)
+	repository.NewArticleRepository(db).UpdateTags(article.ID, []uint64{tag.ID})
+
+	kept, _ := commentService.Create(&request.CreateCommentRequest{ArticleID: article.ID, Content: "保留"}, reader.ID, "user", "")
+	removed, _ := commentService.Create(&request.CreateCommentRequest{ArticleID: article.ID, Content: "先删除"}, reader.ID, "user", "")
+	likeRepo.Toggle(reader.ID, "article", article.ID)
+	likeRepo.Toggle(reader.ID, "comment", kept.ID)
+
+	// 先单独删除一条评论，再删除文章
+	if err := commentService.Delete(removed.ID, reader.ID); err != nil {
+		t.Fatalf("删除评论失败: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	if err := articleService.Delete(article.ID, author.ID); err != nil {
+		t.Fatalf("删除文章失败: %v", err)
+	}
+
+	var comments, likes int64
+	db.Model(&model.Comment{}).Where("article_id = ?", article.ID).Count(&comments)
+	db.Model(&model.Like{}).Count(&likes)
+	if comments != 0 || likes != 0 {
+		t.Errorf("评论和点赞应随文章一起删除, 剩余评论 %d, 点赞 %d", comments, likes)
+	}
+	tagRepo := repository.NewTagRepository(db)
+	if found, _ := tagRepo.GetByID(tag.ID); found.ArticleCount != 0 {
+		t.Errorf("已删除的文章不应计入标签文章数, 得到 %d", found.ArticleCount)
+	}
+
+	// 回收站：作者和管理员可见，其他用户不可见
+	list, err := trashService.ListArticles(&request.ListTrashRequest{}, author.ID, "user")
+	if err != nil || len(list.Items) != 1 {
+		t.Fatalf("作者回收站应有 1 篇文章: %v %v", list, err)
+	}
+	if list.Items[0].PurgeAt.Sub(list.Items[0].DeletedAt) != 30*24*time.Hour {
+		t.Errorf("彻底删除时间应为删除后30天")
+	}
+	if list, _ := trashService.ListArticles(&request.ListTrashRequest{}, reader.ID, "user"); len(list.Items) != 0 {
+		t.Errorf("其他用户不应看到该文章")
+	}
+	if list, _ := trashService.ListArticles(&request.ListTrashRequest{}, reader.ID, "admin"); len(list.Items) != 1 {
+		t.Errorf("管理员应看到所有文章")
+	}
+	if err := trashService.RestoreArticle(article.ID, reader.ID, "user"); err == nil {
+		t.Error("其他用户不能恢复文章")
+	}
+	if err := trashService.RestoreComment(kept.ID, reader.ID, "user"); err == nil {
+		t.Error("文章未恢复时不能单独恢复评论")
+	}
+
+	if err := trashService.RestoreArticle(article.ID, author.ID, "user"); err != nil {
+		t.Fatalf("恢复文章失败: %v", err)
+	}
+	if err := trashService.RestoreArticle(article.ID, author.ID, "user"); err == nil {
+		t.Error("重复恢复应返回错误")
+	}
+
+	// 只恢复与文章一起删除的评论和点赞
+	found, err := articleService.GetByID(article.ID, reader.ID, "user", "")
+	if err != nil {
+		t.Fatalf("恢复后应能查看文章: %v", err)
+	}
+	if !found.IsLiked || found.CommentCount != 1 {
+		t.Errorf("期望点赞已恢复、评论数 1, 得到 is_liked=%v comment_count=%d", found.IsLiked, found.CommentCount)
+	}
+	result, _ := commentService.ListByArticle(article.ID, &request.ListCommentRequest{}, reader.ID, "", "")
+	if len(result.Items) != 1 || result.Items[0].ID != kept.ID || !result.Items[0].IsLiked {
+		t.Errorf("期望只恢复评论 %d 及其点赞, 得到 %+v", kept.ID, result.Items)
+	}
+	if found, _ := tagRepo.GetByID(tag.ID); found.ArticleCount != 1 {
+		t.Errorf("恢复后文章应重新计入标签文章数, 得到 %d", found.ArticleCount)
+	}
+
+	// 单独删除的评论可以单独恢复，并恢复计数
+	comments2, _ := trashService.ListComments(&request.ListTrashRequest{}, reader.ID, "user")
+	if len(comments2.Items) != 1 || comments2.Items[0].ID != removed.ID {
+		t.Fatalf("回收站中应有单独删除的评论 %d, 得到 %+v", removed.ID, comments2.Items)
+	}
+	if err := trashService.RestoreComment(removed.ID, reader.ID, "user"); err != nil {
+		t.Fatalf("恢复评论失败: %v", err)
+	}
+	found, _ = articleService.GetByID(article.ID, reader.ID, "user", "")
+	if found.CommentCount != 2 {
+		t.Errorf("恢复评论后评论数应为 2, 得到 %d", found.CommentCount)
+	}
+}
+
+func TestTrashService_RestoreAfterReconcile(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleService, commentService, trashService := newTestTrashServices(db)
+	likeRepo := repository.NewLikeRepository(db)
+
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	reader := test.CreateTestUser(db, "reader", "reader@example.com")
+	article := test.CreateTestArticle(db, author.ID, "测试文章")
+	comment, _ := commentService.Create(&request.CreateCommentRequest{ArticleID: article.ID, Content: "评论"}, reader.ID, "user", "")
+	commentService.Create(&request.CreateCommentRequest{ArticleID: article.ID, ParentID: &comment.ID, Content: "回复"}, author.ID, "user", "")
+	likeRepo.Toggle(reader.ID, "article", article.ID)
+	likeRepo.Toggle(author.ID, "comment", comment.ID)
+
+	if err := articleService.Delete(article.ID, author.ID); err != nil {
+		t.Fatalf("删除文章失败: %v", err)
+	}
+	// 文章在回收站期间执行计数校准，不应修改已删除文章和评论的计数
+	if _, err := repository.NewCounterRepository(db).Reconcile(); err != nil {
+		t.Fatalf("校准计数失败: %v", err)
+	}
+	if err := trashService.RestoreArticle(article.ID, author.ID, "user"); err != nil {
+		t.Fatalf("恢复文章失败: %v", err)
+	}
+
+	var restored model.Article
+	db.First(&restored, article.ID)
+	if restored.LikeCount != 1 || restored.CommentCount != 2 {
+		t.Errorf("期望文章点赞数 1、评论数 2, 得到 %d、%d", restored.LikeCount, restored.CommentCount)
+	}
+	var restoredComment model.Comment
+	db.First(&restoredComment, comment.ID)
+	if restoredComment.LikeCount != 1 || restoredComment.ReplyCount != 1 {
+		t.Errorf("期望评论点赞数 1、回复数 1, 得到 %d、%d", restoredComment.LikeCount, restoredComment.ReplyCount)
+	}
+}
+
+func TestTrashService_Purge(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleService, commentService, trashService := newTestTrashServices(db)
+	likeRepo := repository.NewLikeRepository(db)
+
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	expired := test.CreateTestArticle(db, author.ID, "过期文章")
+	recent := test.CreateTestArticle(db, author.ID, "最近删除")
+	comment, _ := commentService.Create(&request.CreateCommentRequest{ArticleID: expired.ID, Content: "评论"}, author.ID, "user", "")
+	likeRepo.Toggle(author.ID, "comment", comment.ID)
+	likeRepo.Toggle(author.ID, "article", expired.ID)
+
+	articleService.Delete(expired.ID, author.ID)
+	articleService.Delete(recent.ID, author.ID)
+	// 将第一篇文章及其评论、点赞的删除时间改到保留期之前
+	old := time.Now().UTC().AddDate(0, 0, -31).Truncate(time.Microsecond)
+	db.Unscoped().Model(&model.Article{}).Where("id = ?", expired.ID).UpdateColumn("deleted_at", old)
+	db.Unscoped().Model(&model.Comment{}).Where("article_id = ?", expired.ID).UpdateColumn("deleted_at", old)
+	db.Unscoped().Model(&model.Like{}).Where("1 = 1").UpdateColumn("deleted_at", old)
+
+	purged, err := trashService.Purge()
+	if err != nil {
+		t.Fatalf("清理回收站失败: %v", err)
+	}
+	if purged["articles"] != 1 || purged["comments"] != 1 || purged["likes"] != 2 {
+		t.Errorf("期望删除 1 篇文章、1 条评论、2 个点赞, 得到 %v", purged)
+	}
+
+	var count int64
+	db.Unscoped().Model(&model.Article{}).Where("id = ?", expired.ID).Count(&count)
+	if count != 0 {
+		t.Error("过期的文章应被彻底删除")
+	}
+	if err := trashService.RestoreArticle(recent.ID, author.ID, "user"); err != nil {
+		t.Errorf("未过期的文章应仍可恢复: %v", err)
+	}
+}
+
+func TestTrashService_DeleteAndRestoreUser(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleService, commentService, trashService := newTestTrashServices(db)
+	likeRepo := repository.NewLikeRepository(db)
+	userRepo := repository.NewUserRepository(db)
+
+	admin := test.CreateTestUser(db, "admin", "admin@example.com")
+	author := test.CreateTestUser(db, "author", "author@example.com")
+	reader := test.CreateTestUser(db, "reader", "reader@example.com")
+	article := test.CreateTestArticle(db, author.ID, "作者的文章")
+	earlier := test.CreateTestArticle(db, author.ID, "之前删除的文章")
+	other := test.CreateTestArticle(db, reader.ID, "其他人的文章")
+	commentService.Create(&request.CreateCommentRequest{ArticleID: article.ID, Content: "读者评论"}, reader.ID, "user", "")
+	commentService.Create(&request.CreateCommentRequest{ArticleID: other.ID, Content: "作者评论"}, author.ID, "user", "")
+	likeRepo.Toggle(reader.ID, "article", article.ID)
+
+	if err := articleService.Delete(earlier.ID, author.ID); err != nil {
+		t.Fatalf("删除文章失败: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	if err := trashService.DeleteUser(admin.ID, admin.ID); err == nil {
+		t.Error("管理员不能删除自己")
+	}
+	if err := trashService.DeleteUser(author.ID, admin.ID); err != nil {
+		t.Fatalf("删除用户失败: %v", err)
+	}
+	if _, err := userRepo.GetByUsername("author"); err == nil {
+		t.Error("已删除的用户不应能按用户名查到")
+	}
+	if _, err := articleService.GetByID(article.ID, reader.ID, "user", ""); err == nil {
+		t.Error("用户的文章应随用户一起删除")
+	}
+	var comments int64
+	db.Model(&model.Comment{}).Where("article_id = ?", other.ID).Count(&comments)
+	if comments != 1 {
+		t.Errorf("用户在其他人文章下的评论应保留, 得到 %d 条", comments)
+	}
+
+	users, err := trashService.ListUsers(&request.ListTrashRequest{})
+	if err != nil || len(users.Items) != 1 || users.Items[0].ID != author.ID {
+		t.Fatalf("回收站中应有已删除的用户 %d: %v %v", author.ID, users, err)
+	}
+	articles, _ := trashService.ListArticles(&request.ListTrashRequest{}, admin.ID, "admin")
+	if len(articles.Items) != 2 || articles.Items[0].Author == nil || articles.Items[0].Author.Username != "author" {
+		t.Errorf("回收站中的文章应带有已删除的作者, 得到 %+v", articles.Items)
+	}
+
+	if err := trashService.RestoreUser(author.ID); err != nil {
+		t.Fatalf("恢复用户失败: %v", err)
+	}
+	if err := trashService.RestoreUser(author.ID); err == nil {
+		t.Error("重复恢复应返回错误")
+	}
+	if _, err := userRepo.GetByUsername("author"); err != nil {
+		t.Errorf("恢复后应能按用户名查到用户: %v", err)
+	}
+
+	// 只恢复与用户一起删除的文章及其评论和点赞
+	found, err := articleService.GetByID(article.ID, reader.ID, "user", "")
+	if err != nil {
+		t.Fatalf("恢复后应能查看文章: %v", err)
+	}
+	if !found.IsLiked || found.CommentCount != 1 {
+		t.Errorf("期望点赞已恢复、评论数 1, 得到 is_liked=%v comment_count=%d", found.IsLiked, found.CommentCount)
+	}
+	if _, err := articleService.GetByID(earlier.ID, author.ID, "user", ""); err == nil {
+		t.Error("之前单独删除的文章不应随用户恢复")
+	}
+}
@@ -0,0 +1,690 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"dbapp/internal/config"
+	"dbapp/internal/dto/response"
+	"dbapp/internal/errors"
+	"dbapp/internal/model"
+	"dbapp/internal/repository"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	uploadMetaFile = "meta.json"
+	uploadDataFile = "data.part"
+)
+
+// uploadSession 分片上传会话，持久化在临时目录的 meta.json 中
+// 已接收的字节数以 data.part 的实际大小为准，进程重启后仍可续传
+type uploadSession struct {
+	ID        string    `json:"id"`
+	UserID    uint64    `json:"user_id"`
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	Checksum  string    `json:"checksum"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UploadService struct {
+	*uploadState
+	fileRepo *repository.FileRepository
+	audit    *AuditService
+}
+
+// uploadState 配置、上传会话锁和配额锁，由 WithContext 返回的副本共享
+type uploadState struct {
+	cfgMu sync.RWMutex
+	cfg   config.FileConfig
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+	// quotaLocks 按用户ID分段的配额锁，同一用户的配额检查与占用配额的操作串行执行
+	quotaLocks [64]sync.Mutex
+}
+
+func NewUploadService(cfg config.FileConfig, fileRepo *repository.FileRepository, audit *AuditService) *UploadService {
+	if cfg.UploadPath == "" {
+		cfg.UploadPath = "./uploads"
+	}
+	if cfg.TempPath == "" {
+		cfg.TempPath = "./tmp/uploads"
+	}
+	return &UploadService{
+		uploadState: &uploadState{
+			cfg:   cfg,
+			locks: make(map[string]*sync.Mutex),
+		},
+		fileRepo: fileRepo,
+		audit:    audit,
+	}
+}
+
+// WithContext 返回在 ctx 下访问数据库的副本，用于把SQL的追踪span关联到当前请求
+func (s *UploadService) WithContext(ctx context.Context) *UploadService {
+	clone := *s
+	clone.fileRepo = s.fileRepo.WithContext(ctx)
+	clone.audit = s.audit.WithContext(ctx)
+	return &clone
+}
+
+// config 返回当前配置的快照，允许的类型、大小上限和配额可在运行时修改
+func (s *UploadService) config() config.FileConfig {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.cfg
+}
+
+// UpdateConfig 配置重新加载时更新允许的类型、大小上限和配额，目录等其他配置需要重启生效
+func (s *UploadService) UpdateConfig(cfg config.FileConfig) {
+	s.cfgMu.Lock()
+	defer s.cfgMu.Unlock()
+	s.cfg.AllowedExt = cfg.AllowedExt
+	s.cfg.MaxSize = cfg.MaxSize
+	s.cfg.MaxChunkedSize = cfg.MaxChunkedSize
+	s.cfg.Quotas = cfg.Quotas
+}
+
+// CheckSize 检查单次上传的文件大小
+func (s *UploadService) CheckSize(size int64) error {
+	if maxSize := s.config().MaxSize; size > maxSize {
+		return errors.NewBadRequestError(fmt.Sprintf("文件大小不能超过 %d MB", maxSize/1024/1024))
+	}
+	return nil
+}
+
+// quotaFor 返回角色对应的配额，未配置的角色使用 user 的配额
+func (s *UploadService) quotaFor(role string) config.QuotaConfig {
+	quotas := s.config().Quotas
+	if quota, ok := quotas[role]; ok {
+		return quota
+	}
+	return quotas["user"]
+}
+
+// CheckQuota 检查用户再上传 size 字节是否超出配额，未完成的分片上传会话预占其文件大小和一次上传次数。
+// 只用于提前拒绝，最终以写入文件记录时在事务中的检查为准
+func (s *UploadService) CheckQuota(userID uint64, role string, size int64) error {
+	return s.checkQuota(userID, role, size, "")
+}
+
+// checkQuota 同 CheckQuota，except 为不计入预占的上传会话
+func (s *UploadService) checkQuota(userID uint64, role string, size int64, except string) error {
+	if s.fileRepo == nil {
+		return nil
+	}
+	quota := s.quotaFor(role)
+	if quota.DailyUploads <= 0 && quota.Storage <= 0 {
+		return nil
+	}
+
+	used, err := s.fileRepo.SumSizeByUploader(userID)
+	if err != nil {
+		return errors.NewInternalError("查询存储用量失败").WithCause(err)
+	}
+	count, err := s.fileRepo.CountByUploaderSince(userID, startOfDay(time.Now()))
+	if err != nil {
+		return errors.NewInternalError("查询上传次数失败").WithCause(err)
+	}
+	reservedSize, reservedCount := s.reserved(userID, except)
+	return checkUsage(quota, used+reservedSize, count+reservedCount, size)
+}
+
+// checkUsage 检查已用空间为 used、今日已上传 count 次的用户再上传 size 字节是否超出配额
+func checkUsage(quota config.QuotaConfig, used, count, size int64) error {
+	if quota.DailyUploads > 0 && count >= int64(quota.DailyUploads) {
+		return errors.NewTooManyRequestsError(fmt.Sprintf("今日上传次数已达上限（%d 次），请明天再试", quota.DailyUploads))
+	}
+	if quota.Storage > 0 && used+size > quota.Storage {
+		return errors.NewPayloadTooLargeError(fmt.Sprintf("存储空间不足，已使用 %s / %s", formatBytes(used), formatBytes(quota.Storage)))
+	}
+	return nil
+}
+
+// reserved 返回用户未过期的分片上传会话预占的存储空间和上传次数，except 为不计入的会话
+func (s *UploadService) reserved(userID uint64, except string) (int64, int64) {
+	entries, err := os.ReadDir(s.cfg.TempPath)
+	if err != nil {
+		return 0, 0
+	}
+
+	ttl := time.Duration(s.cfg.SessionTTL) * time.Second
+	var size, count int64
+	for _, entry := range entries {
+		id := entry.Name()
+		if !entry.IsDir() || id == except {
+			continue
+		}
+		session, err := s.loadSession(id, userID)
+		if err != nil {
+			continue
+		}
+		if _, modTime, err := s.received(id); err != nil || time.Since(modTime) > ttl {
+			continue
+		}
+		size += session.Size
+		count++
+	}
+	return size, count
+}
+
+// lockQuota 获取用户的配额锁，检查配额和占用配额（写入文件记录、创建上传会话）在锁内完成，
+// 避免同一用户的并发上传都通过检查后超出配额
+func (s *UploadService) lockQuota(userID uint64) func() {
+	l := &s.quotaLocks[userID%uint64(len(s.quotaLocks))]
+	l.Lock()
+	return l.Unlock
+}
+
+// GetQuota 查询用户的配额与用量
+func (s *UploadService) GetQuota(userID uint64, role string) (*response.QuotaResponse, error) {
+	quota := s.quotaFor(role)
+	now := time.Now()
+	resp := &response.QuotaResponse{
+		Role:              role,
+		StorageLimit:      quota.Storage,
+		DailyUploadsLimit: quota.DailyUploads,
+		ResetsAt:          startOfDay(now).AddDate(0, 0, 1),
+	}
+	if s.fileRepo == nil {
+		return resp, nil
+	}
+
+	used, err := s.fileRepo.SumSizeByUploader(userID)
+	if err != nil {
+		return nil, errors.NewInternalError("查询存储用量失败").WithCause(err)
+	}
+	count, err := s.fileRepo.CountByUploaderSince(userID, startOfDay(now))
+	if err != nil {
+		return nil, errors.NewInternalError("查询上传次数失败").WithCause(err)
+	}
+	resp.StorageUsed = used
+	resp.DailyUploads = count
+	return resp, nil
+}
+
+// CheckExt 检查文件扩展名是否在允许列表中
+func (s *UploadService) CheckExt(filename string) error {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	allowed := s.config().AllowedExt
+	for _, allowedExt := range allowed {
+		if ext == strings.ToLower(allowedExt) {
+			return nil
+		}
+	}
+	return errors.NewBadRequestError(fmt.Sprintf("不支持的文件类型，允许的类型: %v", allowed))
+}
+
+// Save 将读取到的内容保存到上传目录并记录到文件表，返回文件信息；超出配额时删除文件并返回错误
+func (s *UploadService) Save(userID uint64, role string, src io.Reader, filename string, size int64) (*response.FileResponse, error) {
+	if err := os.MkdirAll(s.cfg.UploadPath, 0755); err != nil {
+		return nil, errors.NewInternalError("创建上传目录失败").WithCause(err)
+	}
+
+	storedName := storedFilename(filename)
+	path := filepath.Join(s.cfg.UploadPath, storedName)
+	dst, err := os.Create(path)
+	if err != nil {
+		return nil, errors.NewInternalError("创建文件失败").WithCause(err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return nil, errors.NewInternalError("保存文件失败").WithCause(err)
+	}
+
+	return s.record(userID, role, "", filename, storedName, path, size)
+}
+
+// record 在文件表中记录上传，用于配额统计。记录前在同一事务中再次检查配额，
+// uploadID 为正在完成的上传会话，不计入预占
+func (s *UploadService) record(userID uint64, role string, uploadID string, filename, storedName, path string, size int64) (*response.FileResponse, error) {
+	resp := &response.FileResponse{
+		URL:  "/uploads/" + storedName,
+		Name: filename,
+		Size: size,
+	}
+	if s.fileRepo == nil {
+		uploadsTotal.Inc()
+		uploadBytesTotal.Add(float64(size))
+		return resp, nil
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(filename))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	file := &model.File{
+		Filename:       filename,
+		StoredFilename: storedName,
+		FilePath:       path,
+		FileURL:        resp.URL,
+		FileSize:       size,
+		MimeType:       mimeType,
+		FileType:       fileType(mimeType),
+		UploaderID:     userID,
+		IsPublic:       true,
+	}
+
+	unlock := s.lockQuota(userID)
+	defer unlock()
+	quota := s.quotaFor(role)
+	reservedSize, reservedCount := s.reserved(userID, uploadID)
+	err := s.fileRepo.CreateWithQuota(file, startOfDay(time.Now()), func(used, count int64) error {
+		return checkUsage(quota, used+reservedSize, count+reservedCount, size)
+	})
+	if err != nil {
+		// 记录失败时删除文件，避免产生不计入配额的文件
+		os.Remove(path)
+		if appErr, ok := err.(*errors.AppError); ok {
+			return nil, appErr
+		}
+		return nil, errors.NewInternalError("保存文件记录失败").WithCause(err)
+	}
+	s.audit.Record(AuditActionCreate, AuditTargetFile, file.ID, nil, file)
+	uploadsTotal.Inc()
+	uploadBytesTotal.Add(float64(size))
+	return resp, nil
+}
+
+// InitSession 创建分片上传会话，会话在完成、取消或过期前预占文件大小的存储空间和一次上传次数
+func (s *UploadService) InitSession(userID uint64, role string, filename string, size int64, checksum string) (*response.UploadSessionResponse, error) {
+	filename = filepath.Base(filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		return nil, errors.NewBadRequestError("无效的文件名")
+	}
+	if err := s.CheckExt(filename); err != nil {
+		return nil, err
+	}
+	if maxSize := s.config().MaxChunkedSize; size > maxSize {
+		return nil, errors.NewPayloadTooLargeError(fmt.Sprintf("文件大小不能超过 %d MB", maxSize/1024/1024))
+	}
+	unlockQuota := s.lockQuota(userID)
+	defer unlockQuota()
+	if err := s.CheckQuota(userID, role, size); err != nil {
+		return nil, err
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, errors.NewInternalError("创建上传会话失败").WithCause(err)
+	}
+	unlock := s.lock(id)
+	defer unlock()
+
+	session := &uploadSession{
+		ID:        id,
+		UserID:    userID,
+		Filename:  filename,
+		Size:      size,
+		Checksum:  strings.ToLower(checksum),
+		CreatedAt: time.Now(),
+	}
+
+	dir := s.sessionDir(id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.NewInternalError("创建上传会话失败").WithCause(err)
+	}
+	if err := writeSessionMeta(dir, session); err != nil {
+		os.RemoveAll(dir)
+		return nil, errors.NewInternalError("创建上传会话失败").WithCause(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, uploadDataFile), nil, 0644); err != nil {
+		os.RemoveAll(dir)
+		return nil, errors.NewInternalError("创建上传会话失败").WithCause(err)
+	}
+
+	return s.toSessionResponse(session, 0, session.CreatedAt), nil
+}
+
+// GetSession 查询上传会话状态，客户端据此获取续传位置
+func (s *UploadService) GetSession(id string, userID uint64) (*response.UploadSessionResponse, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	session, err := s.loadSession(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	offset, modTime, err := s.received(id)
+	if err != nil {
+		return nil, errors.NewInternalError("读取上传进度失败").WithCause(err)
+	}
+	return s.toSessionResponse(session, offset, modTime), nil
+}
+
+// WriteChunk 在指定偏移处追加一个分片，偏移必须等于已接收的字节数
+func (s *UploadService) WriteChunk(id string, userID uint64, offset int64, chunk io.Reader) (*response.UploadSessionResponse, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	session, err := s.loadSession(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	received, _, err := s.received(id)
+	if err != nil {
+		return nil, errors.NewInternalError("读取上传进度失败").WithCause(err)
+	}
+	if offset != received {
+		return nil, errors.NewConflictError(fmt.Sprintf("分片偏移不匹配，当前已接收 %d 字节", received))
+	}
+
+	f, err := os.OpenFile(filepath.Join(s.sessionDir(id), uploadDataFile), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.NewInternalError("写入分片失败").WithCause(err)
+	}
+	defer f.Close()
+
+	// 多读一个字节用于判断分片是否超出限制
+	limit := s.cfg.ChunkSize
+	if remaining := session.Size - received; remaining < limit {
+		limit = remaining
+	}
+	n, err := io.Copy(f, io.LimitReader(chunk, limit+1))
+	if err != nil {
+		// 写入中断时截断到原位置，避免残留半个分片
+		f.Truncate(received)
+		zap.L().Warn("写入分片中断", zap.String("upload_id", id), zap.String("error", err.Error()))
+		return nil, errors.NewBadRequestError("分片传输中断，请从当前偏移重试")
+	}
+	if n > limit {
+		f.Truncate(received)
+		return nil, errors.NewBadRequestError(fmt.Sprintf("分片大小超出限制，最多 %d 字节", limit))
+	}
+
+	return s.toSessionResponse(session, received+n, time.Now()), nil
+}
+
+// Complete 校验完整文件的SHA-256并移动到上传目录
+func (s *UploadService) Complete(id string, userID uint64, role string) (*response.FileResponse, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	session, err := s.loadSession(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	received, _, err := s.received(id)
+	if err != nil {
+		return nil, errors.NewInternalError("读取上传进度失败").WithCause(err)
+	}
+	if received != session.Size {
+		return nil, errors.NewConflictError(fmt.Sprintf("文件尚未上传完成，已接收 %d/%d 字节", received, session.Size))
+	}
+
+	dir := s.sessionDir(id)
+	dataPath := filepath.Join(dir, uploadDataFile)
+	sum, err := fileSHA256(dataPath)
+	if err != nil {
+		return nil, errors.NewInternalError("校验文件失败").WithCause(err)
+	}
+	if sum != session.Checksum {
+		// 内容已损坏，续传无法修复，直接丢弃会话
+		os.RemoveAll(dir)
+		return nil, errors.NewBadRequestError("文件校验失败，请重新上传")
+	}
+	// 配额变小时会话预占的空间可能不再足够，移动文件前先检查，超出时保留会话
+	if err := s.checkQuota(userID, role, session.Size, id); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.cfg.UploadPath, 0755); err != nil {
+		return nil, errors.NewInternalError("创建上传目录失败").WithCause(err)
+	}
+	storedName := storedFilename(session.Filename)
+	path := filepath.Join(s.cfg.UploadPath, storedName)
+	if err := moveFile(dataPath, path); err != nil {
+		return nil, errors.NewInternalError("保存文件失败").WithCause(err)
+	}
+	os.RemoveAll(dir)
+
+	return s.record(userID, role, id, session.Filename, storedName, path, session.Size)
+}
+
+// Abort 取消上传并删除已接收的数据
+func (s *UploadService) Abort(id string, userID uint64) error {
+	unlock := s.lock(id)
+	defer unlock()
+
+	if _, err := s.loadSession(id, userID); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(s.sessionDir(id)); err != nil {
+		return errors.NewInternalError("取消上传失败").WithCause(err)
+	}
+	return nil
+}
+
+// CleanupExpired 删除超过保留时间未更新的上传会话，返回删除的数量
+func (s *UploadService) CleanupExpired() int {
+	entries, err := os.ReadDir(s.cfg.TempPath)
+	if err != nil {
+		return 0
+	}
+
+	ttl := time.Duration(s.cfg.SessionTTL) * time.Second
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		id := entry.Name()
+		unlock := s.lock(id)
+		_, modTime, err := s.received(id)
+		if err != nil {
+			// 缺少分片文件的会话视为损坏
+			modTime = time.Time{}
+		}
+		if time.Since(modTime) > ttl {
+			if err := os.RemoveAll(s.sessionDir(id)); err == nil {
+				removed++
+			}
+		}
+		unlock()
+	}
+	return removed
+}
+
+// StartCleanup 定期清理过期的上传会话，返回停止函数，停止函数会等待正在进行的清理结束
+func (s *UploadService) StartCleanup(interval time.Duration) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if n := s.CleanupExpired(); n > 0 {
+					zap.L().Info("已清理过期的上传会话", zap.Int("count", n))
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stop) })
+		<-done
+	}
+}
+
+func (s *UploadService) sessionDir(id string) string {
+	return filepath.Join(s.cfg.TempPath, id)
+}
+
+// lock 获取单个会话的互斥锁，同一会话的分片必须串行写入
+func (s *UploadService) lock(id string) func() {
+	s.mu.Lock()
+	l, ok := s.locks[id]
+	if !ok {
+		l = &sync.Mutex{}
+		s.locks[id] = l
+	}
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.mu.Lock()
+		if _, err := os.Stat(s.sessionDir(id)); os.IsNotExist(err) {
+			delete(s.locks, id)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *UploadService) loadSession(id string, userID uint64) (*uploadSession, error) {
+	if !isValidUploadID(id) {
+		return nil, errors.NewNotFoundError("上传会话不存在")
+	}
+	data, err := os.ReadFile(filepath.Join(s.sessionDir(id), uploadMetaFile))
+	if err != nil {
+		return nil, errors.NewNotFoundError("上传会话不存在")
+	}
+	var session uploadSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, errors.NewNotFoundError("上传会话不存在")
+	}
+	if session.UserID != userID {
+		return nil, errors.NewForbiddenError("无权限访问此上传会话")
+	}
+	return &session, nil
+}
+
+// received 返回已接收的字节数和最后写入时间
+func (s *UploadService) received(id string) (int64, time.Time, error) {
+	info, err := os.Stat(filepath.Join(s.sessionDir(id), uploadDataFile))
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return info.Size(), info.ModTime(), nil
+}
+
+func (s *UploadService) toSessionResponse(session *uploadSession, offset int64, lastWrite time.Time) *response.UploadSessionResponse {
+	return &response.UploadSessionResponse{
+		UploadID:  session.ID,
+		Filename:  session.Filename,
+		Size:      session.Size,
+		Offset:    offset,
+		ChunkSize: s.cfg.ChunkSize,
+		ExpiresAt: lastWrite.Add(time.Duration(s.cfg.SessionTTL) * time.Second),
+	}
+}
+
+func writeSessionMeta(dir string, session *uploadSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, uploadMetaFile), data, 0644)
+}
+
+func newUploadID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// isValidUploadID 会话ID只能是32位十六进制，防止路径穿越
+func isValidUploadID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// storedFilename 生成唯一的存储文件名: {timestamp}_{原始文件名}
+func storedFilename(filename string) string {
+	return fmt.Sprintf("%d_%s", time.Now().UnixNano(), filename)
+}
+
+// fileType 根据MIME类型归类: image, document, other
+func fileType(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "image"
+	case mimeType == "application/pdf", strings.HasPrefix(mimeType, "text/"):
+		return "document"
+	default:
+		return "other"
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// moveFile 优先使用rename，跨文件系统时退化为复制
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
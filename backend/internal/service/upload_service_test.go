@@ -0,0 +1,321 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"dbapp/internal/config"
+	"dbapp/internal/errors"
+	"dbapp/internal/repository"
+	"dbapp/internal/test"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestUploadService(t *testing.T) *UploadService {
+	dir := t.TempDir()
+	return NewUploadService(config.FileConfig{
+		UploadPath:     filepath.Join(dir, "uploads"),
+		TempPath:       filepath.Join(dir, "tmp"),
+		AllowedExt:     []string{"pdf"},
+		ChunkSize:      4,
+		MaxChunkedSize: 1024,
+		SessionTTL:     3600,
+		Quotas: map[string]config.QuotaConfig{
+			"user": {Storage: 10, DailyUploads: 2},
+		},
+	}, nil, nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadService_ChunkedUpload(t *testing.T) {
+	s := newTestUploadService(t)
+	content := []byte("hello chunked upload")
+
+	session, err := s.InitSession(1, "user", "doc.pdf", int64(len(content)), sha256Hex(content))
+	if err != nil {
+		t.Fatalf("创建上传会话失败: %v", err)
+	}
+
+	for offset := 0; offset < len(content); offset += 4 {
+		end := offset + 4
+		if end > len(content) {
+			end = len(content)
+		}
+		if _, err := s.WriteChunk(session.UploadID, 1, int64(offset), bytes.NewReader(content[offset:end])); err != nil {
+			t.Fatalf("写入分片失败: %v", err)
+		}
+	}
+
+	result, err := s.Complete(session.UploadID, 1, "user")
+	if err != nil {
+		t.Fatalf("完成上传失败: %v", err)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(s.cfg.UploadPath, filepath.Base(result.URL)))
+	if err != nil {
+		t.Fatalf("读取上传文件失败: %v", err)
+	}
+	if !bytes.Equal(saved, content) {
+		t.Errorf("文件内容不一致")
+	}
+	if _, err := os.Stat(s.sessionDir(session.UploadID)); !os.IsNotExist(err) {
+		t.Errorf("完成后应删除上传会话目录")
+	}
+}
+
+func TestUploadService_ResumeAfterOffsetMismatch(t *testing.T) {
+	s := newTestUploadService(t)
+	content := []byte("12345678")
+
+	session, _ := s.InitSession(1, "user", "doc.pdf", int64(len(content)), sha256Hex(content))
+	s.WriteChunk(session.UploadID, 1, 0, bytes.NewReader(content[:4]))
+
+	// 重复发送第一个分片应返回409
+	_, err := s.WriteChunk(session.UploadID, 1, 0, bytes.NewReader(content[:4]))
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != 409 {
+		t.Fatalf("期望409错误, 得到 %v", err)
+	}
+
+	status, err := s.GetSession(session.UploadID, 1)
+	if err != nil {
+		t.Fatalf("查询上传会话失败: %v", err)
+	}
+	if status.Offset != 4 {
+		t.Errorf("期望偏移 4, 得到 %d", status.Offset)
+	}
+}
+
+func TestUploadService_ChecksumMismatch(t *testing.T) {
+	s := newTestUploadService(t)
+	content := []byte("abcd")
+
+	session, _ := s.InitSession(1, "user", "doc.pdf", int64(len(content)), sha256Hex([]byte("other")))
+	s.WriteChunk(session.UploadID, 1, 0, bytes.NewReader(content))
+
+	if _, err := s.Complete(session.UploadID, 1, "user"); err == nil {
+		t.Fatal("校验和不一致时应返回错误")
+	}
+	if _, err := s.GetSession(session.UploadID, 1); err == nil {
+		t.Error("校验失败后会话应被删除")
+	}
+}
+
+func TestUploadService_ChunkTooLarge(t *testing.T) {
+	s := newTestUploadService(t)
+	content := []byte("0123456789")
+
+	session, _ := s.InitSession(1, "user", "doc.pdf", int64(len(content)), sha256Hex(content))
+	if _, err := s.WriteChunk(session.UploadID, 1, 0, bytes.NewReader(content)); err == nil {
+		t.Fatal("分片超过限制时应返回错误")
+	}
+
+	status, _ := s.GetSession(session.UploadID, 1)
+	if status.Offset != 0 {
+		t.Errorf("超限分片不应被保留, 当前偏移 %d", status.Offset)
+	}
+}
+
+func TestUploadService_OtherUserForbidden(t *testing.T) {
+	s := newTestUploadService(t)
+
+	session, _ := s.InitSession(1, "user", "doc.pdf", 4, sha256Hex([]byte("abcd")))
+	if _, err := s.WriteChunk(session.UploadID, 2, 0, bytes.NewReader([]byte("abcd"))); err == nil {
+		t.Error("其他用户不应能写入分片")
+	}
+	if err := s.Abort(session.UploadID, 2); err == nil {
+		t.Error("其他用户不应能取消上传")
+	}
+}
+
+func TestUploadService_CleanupExpired(t *testing.T) {
+	s := newTestUploadService(t)
+
+	session, _ := s.InitSession(1, "user", "doc.pdf", 4, sha256Hex([]byte("abcd")))
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(filepath.Join(s.sessionDir(session.UploadID), uploadDataFile), old, old)
+
+	if n := s.CleanupExpired(); n != 1 {
+		t.Errorf("期望清理 1 个会话, 得到 %d", n)
+	}
+	if _, err := os.Stat(s.sessionDir(session.UploadID)); !os.IsNotExist(err) {
+		t.Error("过期会话目录应被删除")
+	}
+}
+
+func TestUploadService_InvalidID(t *testing.T) {
+	s := newTestUploadService(t)
+
+	if _, err := s.GetSession("../../etc", 1); err == nil {
+		t.Error("非法的会话ID应返回错误")
+	}
+}
+
+func TestUploadService_Quota(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	s := newTestUploadService(t)
+	s.fileRepo = repository.NewFileRepository(db)
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+
+	if _, err := s.Save(user.ID, "user", bytes.NewReader([]byte("123456")), "a.pdf", 6); err != nil {
+		t.Fatalf("保存文件失败: %v", err)
+	}
+
+	// 超出存储空间返回413
+	err := s.CheckQuota(user.ID, "user", 5)
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != 413 {
+		t.Errorf("期望413错误, 得到 %v", err)
+	}
+
+	if _, err := s.Save(user.ID, "user", bytes.NewReader([]byte("1")), "b.pdf", 1); err != nil {
+		t.Fatalf("保存文件失败: %v", err)
+	}
+
+	// 超出每日上传次数返回429
+	err = s.CheckQuota(user.ID, "user", 1)
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != 429 {
+		t.Errorf("期望429错误, 得到 %v", err)
+	}
+
+	quota, err := s.GetQuota(user.ID, "user")
+	if err != nil {
+		t.Fatalf("查询配额失败: %v", err)
+	}
+	if quota.StorageUsed != 7 || quota.DailyUploads != 2 {
+		t.Errorf("期望用量 7 字节/2 次, 得到 %d 字节/%d 次", quota.StorageUsed, quota.DailyUploads)
+	}
+
+	// 未配置的角色使用 user 的配额
+	if quota, _ := s.GetQuota(user.ID, "editor"); quota.StorageLimit != 10 {
+		t.Errorf("期望存储上限 10, 得到 %d", quota.StorageLimit)
+	}
+}
+
+func TestUploadService_ConcurrentSavesRespectQuota(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+	// 内存数据库的每个连接是独立的数据库，并发请求需共用一个连接
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	s := newTestUploadService(t)
+	s.fileRepo = repository.NewFileRepository(db)
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+
+	// 两个 6 字节的上传都通过了提前检查，写入记录时只能有一个成功
+	for i := 0; i < 2; i++ {
+		if err := s.CheckQuota(user.ID, "user", 6); err != nil {
+			t.Fatalf("提前检查应通过: %v", err)
+		}
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.Save(user.ID, "user", bytes.NewReader([]byte("123456")), "a.pdf", 6)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != 413 {
+			t.Errorf("期望413错误, 得到 %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("期望只有 1 个上传成功, 得到 %d", succeeded)
+	}
+	if quota, _ := s.GetQuota(user.ID, "user"); quota.StorageUsed != 6 {
+		t.Errorf("期望用量 6 字节, 得到 %d", quota.StorageUsed)
+	}
+	if files, _ := os.ReadDir(s.cfg.UploadPath); len(files) != 1 {
+		t.Errorf("超出配额的文件应被删除, 上传目录中有 %d 个文件", len(files))
+	}
+}
+
+func TestUploadService_SessionsReserveQuota(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	s := newTestUploadService(t)
+	s.fileRepo = repository.NewFileRepository(db)
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+
+	content := []byte("12345678")
+	session, err := s.InitSession(user.ID, "user", "doc.pdf", int64(len(content)), sha256Hex(content))
+	if err != nil {
+		t.Fatalf("创建上传会话失败: %v", err)
+	}
+
+	// 未完成的会话预占 8 字节，剩余空间不足
+	if _, err := s.InitSession(user.ID, "user", "doc.pdf", 4, sha256Hex([]byte("abcd"))); !isAppError(err, 413) {
+		t.Errorf("期望413错误, 得到 %v", err)
+	}
+	if _, err := s.Save(user.ID, "user", bytes.NewReader([]byte("1234")), "a.pdf", 4); !isAppError(err, 413) {
+		t.Errorf("期望413错误, 得到 %v", err)
+	}
+
+	// 会话本身的预占不影响其完成
+	for offset := 0; offset < len(content); offset += 4 {
+		if _, err := s.WriteChunk(session.UploadID, user.ID, int64(offset), bytes.NewReader(content[offset:offset+4])); err != nil {
+			t.Fatalf("写入分片失败: %v", err)
+		}
+	}
+	if _, err := s.Complete(session.UploadID, user.ID, "user"); err != nil {
+		t.Fatalf("完成上传失败: %v", err)
+	}
+
+	// 取消的会话不再预占
+	other, err := s.InitSession(user.ID, "user", "doc.pdf", 2, sha256Hex([]byte("ab")))
+	if err != nil {
+		t.Fatalf("创建上传会话失败: %v", err)
+	}
+	if err := s.CheckQuota(user.ID, "user", 1); !isAppError(err, 429) {
+		t.Errorf("未完成的会话应计入上传次数, 期望429错误, 得到 %v", err)
+	}
+	if err := s.Abort(other.UploadID, user.ID); err != nil {
+		t.Fatalf("取消上传失败: %v", err)
+	}
+	if err := s.CheckQuota(user.ID, "user", 2); err != nil {
+		t.Errorf("取消会话后应释放预占: %v", err)
+	}
+}
+
+func TestUploadService_UpdateConfig(t *testing.T) {
+	svc := newTestUploadService(t)
+
+	if err := svc.CheckExt("a.png"); err == nil {
+		t.Fatal("png 不在允许列表中")
+	}
+
+	cfg := svc.config()
+	cfg.AllowedExt = []string{"png"}
+	cfg.MaxSize = 10
+	cfg.UploadPath = "/should/not/change"
+	svc.UpdateConfig(cfg)
+
+	if err := svc.CheckExt("a.png"); err != nil {
+		t.Errorf("更新后应允许 png: %v", err)
+	}
+	if err := svc.CheckSize(11); err == nil {
+		t.Error("更新后应使用新的大小上限")
+	}
+	if svc.config().UploadPath == "/should/not/change" {
+		t.Error("上传目录需要重启生效，不应被更新")
+	}
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"dbapp/internal/config"
 	"dbapp/internal/dto/request"
 	"dbapp/internal/dto/response"
 	"dbapp/internal/errors"
@@ -12,16 +14,36 @@ import (
 )
 
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo   *repository.UserRepository
+	loginGuard *LoginGuard
+	settings   *SettingService
+	audit      *AuditService
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+// NewUserService loginGuard 为 nil 时不限制登录失败次数，settings 为 nil 时始终开放注册
+func NewUserService(userRepo *repository.UserRepository, loginGuard *LoginGuard, settings *SettingService, audit *AuditService) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		loginGuard: loginGuard,
+		settings:   settings,
+		audit:      audit,
 	}
 }
 
+// WithContext 返回在 ctx 下访问数据库和缓存的副本，用于把SQL和Redis的追踪span关联到当前请求
+func (s *UserService) WithContext(ctx context.Context) *UserService {
+	clone := *s
+	clone.userRepo = s.userRepo.WithContext(ctx)
+	clone.settings = s.settings.WithContext(ctx)
+	clone.audit = s.audit.WithContext(ctx)
+	return &clone
+}
+
 func (s *UserService) Register(req *request.RegisterRequest) (*response.UserResponse, error) {
+	if s.settings != nil && !s.settings.Bool(SettingRegistrationOpen) {
+		return nil, errors.NewForbiddenError("站点暂未开放注册")
+	}
+
 	// 检查用户名是否已存在
 	existingUser, err := s.userRepo.GetByUsername(req.Username)
 	if err == nil && existingUser != nil {
@@ -37,7 +59,7 @@ func (s *UserService) Register(req *request.RegisterRequest) (*response.UserResp
 	// 加密密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, errors.NewInternalError("密码加密失败")
+		return nil, errors.NewInternalError("密码加密失败").WithCause(err)
 	}
 
 	// 创建用户
@@ -51,22 +73,34 @@ func (s *UserService) Register(req *request.RegisterRequest) (*response.UserResp
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
-		return nil, errors.NewInternalError("创建用户失败")
+		return nil, errors.NewInternalError("创建用户失败").WithCause(err)
 	}
+	s.audit.Record(AuditActionCreate, AuditTargetUser, user.ID, nil, user)
 
 	return s.toResponse(user), nil
 }
 
 func (s *UserService) Login(req *request.LoginRequest) (*response.LoginResponse, error) {
+	// 锁定期间不校验密码，避免继续尝试
+	if s.loginGuard != nil {
+		if remaining := s.loginGuard.Locked(req.Username); remaining > 0 {
+			return nil, errors.NewTooManyRequestsError("登录失败次数过多，账户已被临时锁定").WithRetryAfter(remaining)
+		}
+	}
+
 	// 查找用户
 	user, err := s.userRepo.GetByUsername(req.Username)
 	if err != nil {
-		return nil, errors.NewUnauthorizedError("用户名或密码错误")
+		return nil, s.loginFailed(req.Username)
 	}
 
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, errors.NewUnauthorizedError("用户名或密码错误")
+		return nil, s.loginFailed(req.Username)
+	}
+
+	if s.loginGuard != nil {
+		s.loginGuard.Reset(req.Username)
 	}
 
 	// 检查用户状态
@@ -77,19 +111,64 @@ func (s *UserService) Login(req *request.LoginRequest) (*response.LoginResponse,
 	// 生成JWT Token
 	token, err := utils.GenerateJWT(user.ID, user.Username, user.Role)
 	if err != nil {
-		return nil, errors.NewInternalError("生成Token失败")
+		return nil, errors.NewInternalError("生成Token失败").WithCause(err)
 	}
 
 	// 更新最后登录时间
 	go s.userRepo.UpdateLastLogin(user.ID)
 
+	// 有效期与 GenerateJWT 使用同一配置，Cookie 会话的 Max-Age 也取自该值
 	return &response.LoginResponse{
 		Token:     token,
-		ExpiresIn: 3600,
+		ExpiresIn: config.GetConfig().JWT.ExpiresIn,
 		User:      s.toResponse(user),
 	}, nil
 }
 
+// loginFailed 记录登录失败，达到上限时返回锁定错误
+func (s *UserService) loginFailed(username string) error {
+	if s.loginGuard != nil {
+		if lock := s.loginGuard.Fail(username); lock > 0 {
+			return errors.NewTooManyRequestsError("登录失败次数过多，账户已被临时锁定").WithRetryAfter(lock)
+		}
+	}
+	return errors.NewUnauthorizedError("用户名或密码错误")
+}
+
+// EnsureAdmin 数据库中没有管理员时创建管理员，返回是否创建。
+// 只在首次启动时生效，已有管理员后修改配置中的密码不会影响现有账户。
+func (s *UserService) EnsureAdmin(username, email, password string) (bool, error) {
+	count, err := s.userRepo.CountByRole(model.RoleAdmin)
+	if err != nil {
+		return false, errors.NewInternalError("查询管理员失败").WithCause(err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	if existing, err := s.userRepo.GetByUsername(username); err == nil && existing != nil {
+		return false, errors.NewConflictError("用户名 " + username + " 已被普通用户占用")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return false, errors.NewInternalError("密码加密失败").WithCause(err)
+	}
+	admin := &model.User{
+		Username:     username,
+		Email:        email,
+		PasswordHash: string(hashedPassword),
+		Nickname:     "管理员",
+		Role:         model.RoleAdmin,
+		Status:       "active",
+	}
+	if err := s.userRepo.Create(admin); err != nil {
+		return false, errors.NewInternalError("创建管理员失败").WithCause(err)
+	}
+	s.audit.Record(AuditActionCreate, AuditTargetUser, admin.ID, nil, admin)
+	return true, nil
+}
+
 func (s *UserService) GetByID(id uint64) (*response.UserResponse, error) {
 	user, err := s.userRepo.GetByID(id)
 	if err != nil {
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"dbapp/internal/config"
 	"dbapp/internal/dto/request"
 	"dbapp/internal/repository"
 	"dbapp/internal/test"
@@ -12,7 +13,7 @@ func TestUserService_Register(t *testing.T) {
 	defer test.TeardownTestDB(db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil, nil, nil)
 
 	req := &request.RegisterRequest{
 		Username: "newuser",
@@ -39,7 +40,7 @@ func TestUserService_Register_DuplicateUsername(t *testing.T) {
 	defer test.TeardownTestDB(db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil, nil, nil)
 
 	// 创建已存在的用户
 	test.CreateTestUser(db, "existinguser", "existing@example.com")
@@ -61,7 +62,7 @@ func TestUserService_Register_DuplicateEmail(t *testing.T) {
 	defer test.TeardownTestDB(db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil, nil, nil)
 
 	// 创建已存在的用户
 	test.CreateTestUser(db, "user1", "existing@example.com")
@@ -82,8 +83,12 @@ func TestUserService_Login(t *testing.T) {
 	db := test.SetupTestDB(t)
 	defer test.TeardownTestDB(db)
 
+	previous := config.GlobalConfig
+	config.GlobalConfig = &config.Config{JWT: config.JWTConfig{Secret: "test-secret-key-for-testing", ExpiresIn: 7200}}
+	defer func() { config.GlobalConfig = previous }()
+
 	userRepo := repository.NewUserRepository(db)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil, nil, nil)
 
 	// 创建测试用户（密码需要是bcrypt哈希）
 	req := &request.RegisterRequest{
@@ -110,6 +115,9 @@ func TestUserService_Login(t *testing.T) {
 	if result.Token == "" {
 		t.Error("Token应该被生成")
 	}
+	if result.ExpiresIn != 7200 {
+		t.Errorf("有效期应与 JWT 配置一致, 期望 7200, 得到 %d", result.ExpiresIn)
+	}
 
 	if result.User.Username != "testuser" {
 		t.Errorf("期望用户名 testuser, 得到 %s", result.User.Username)
@@ -121,7 +129,7 @@ func TestUserService_Login_InvalidCredentials(t *testing.T) {
 	defer test.TeardownTestDB(db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil, nil, nil)
 
 	// 创建测试用户
 	req := &request.RegisterRequest{
@@ -151,7 +159,7 @@ func TestUserService_GetByID(t *testing.T) {
 	defer test.TeardownTestDB(db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil, nil, nil)
 
 	// 创建测试用户
 	user := test.CreateTestUser(db, "testuser", "test@example.com")
@@ -172,7 +180,7 @@ func TestUserService_GetByID_NotFound(t *testing.T) {
 	defer test.TeardownTestDB(db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil, nil, nil)
 
 	// 查询不存在的用户
 	_, err := userService.GetByID(99999)
@@ -181,3 +189,41 @@ func TestUserService_GetByID_NotFound(t *testing.T) {
 	}
 }
 
+
+func TestUserService_EnsureAdmin(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	userRepo := repository.NewUserRepository(db)
+	userService := NewUserService(userRepo, nil, nil, nil)
+
+	created, err := userService.EnsureAdmin("admin", "admin@example.com", "password123")
+	if err != nil || !created {
+		t.Fatalf("没有管理员时应创建管理员: created=%v err=%v", created, err)
+	}
+	if _, err := userService.Login(&request.LoginRequest{Username: "admin", Password: "password123"}); err != nil {
+		t.Fatalf("管理员应能使用配置的密码登录: %v", err)
+	}
+
+	// 已有管理员时不再创建，也不修改已有账户的密码
+	created, err = userService.EnsureAdmin("admin2", "admin2@example.com", "otherpassword")
+	if err != nil || created {
+		t.Errorf("已有管理员时不应创建: created=%v err=%v", created, err)
+	}
+	if _, err := userRepo.GetByUsername("admin2"); err == nil {
+		t.Error("不应创建第二个管理员")
+	}
+}
+
+func TestUserService_EnsureAdmin_UsernameTaken(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	userRepo := repository.NewUserRepository(db)
+	userService := NewUserService(userRepo, nil, nil, nil)
+	test.CreateTestUser(db, "admin", "user@example.com")
+
+	if _, err := userService.EnsureAdmin("admin", "admin@example.com", "password123"); err == nil {
+		t.Error("用户名被普通用户占用时应返回错误，不能把普通用户提升为管理员")
+	}
+}
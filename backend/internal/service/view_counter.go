@@ -0,0 +1,137 @@
+package service
+
+import (
+	"dbapp/internal/repository"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// botUserAgent 常见爬虫和脚本客户端，不计入浏览数
+var botUserAgent = regexp.MustCompile(`(?i)bot|crawler|spider|slurp|curl|wget|python-requests|go-http-client|headless`)
+
+// ViewCounter 在内存中累计文章浏览数并定期批量写入数据库。
+// 同一用户（未登录时按IP）在去重窗口内重复浏览同一篇文章只计一次。
+// 多实例部署时去重只在单个实例内生效。
+type ViewCounter struct {
+	articleRepo *repository.ArticleRepository
+	window      time.Duration
+
+	mu      sync.Mutex
+	pending map[uint64]int
+	seen    map[string]time.Time
+
+	started bool
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func NewViewCounter(articleRepo *repository.ArticleRepository, window time.Duration) *ViewCounter {
+	return &ViewCounter{
+		articleRepo: articleRepo,
+		window:      window,
+		pending:     make(map[uint64]int),
+		seen:        make(map[string]time.Time),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Record 记录一次浏览，返回是否被计数
+func (v *ViewCounter) Record(articleID uint64, userID uint64, ip string, userAgent string) bool {
+	if userAgent == "" || botUserAgent.MatchString(userAgent) {
+		return false
+	}
+
+	viewer := "ip:" + ip
+	if userID > 0 {
+		viewer = fmt.Sprintf("user:%d", userID)
+	}
+	key := fmt.Sprintf("%d|%s", articleID, viewer)
+	now := time.Now()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if last, ok := v.seen[key]; ok && now.Sub(last) < v.window {
+		return false
+	}
+	v.seen[key] = now
+	v.pending[articleID]++
+	return true
+}
+
+// Flush 将累计的浏览数写入数据库，写入失败时合并回待写入数据等待下次重试
+func (v *ViewCounter) Flush() error {
+	v.mu.Lock()
+	if len(v.pending) == 0 {
+		v.pruneSeen()
+		v.mu.Unlock()
+		return nil
+	}
+	counts := v.pending
+	v.pending = make(map[uint64]int)
+	v.pruneSeen()
+	v.mu.Unlock()
+
+	if err := v.articleRepo.IncrementViewCounts(counts); err != nil {
+		v.mu.Lock()
+		for id, n := range counts {
+			v.pending[id] += n
+		}
+		v.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// pruneSeen 删除已超出去重窗口的记录，调用方需持有锁
+func (v *ViewCounter) pruneSeen() {
+	now := time.Now()
+	for key, last := range v.seen {
+		if now.Sub(last) >= v.window {
+			delete(v.seen, key)
+		}
+	}
+}
+
+// Start 按 interval 定期写入浏览数
+func (v *ViewCounter) Start(interval time.Duration) {
+	v.mu.Lock()
+	v.started = true
+	v.mu.Unlock()
+
+	go func() {
+		defer close(v.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := v.Flush(); err != nil {
+					zap.L().Error("写入浏览数失败", zap.String("error", err.Error()))
+				}
+			case <-v.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定期写入并把剩余的浏览数写入数据库
+func (v *ViewCounter) Stop() error {
+	v.once.Do(func() {
+		close(v.stop)
+	})
+
+	v.mu.Lock()
+	started := v.started
+	v.mu.Unlock()
+	if started {
+		<-v.done
+	}
+	return v.Flush()
+}
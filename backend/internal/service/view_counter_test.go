@@ -0,0 +1,66 @@
+package service
+
+import (
+	"dbapp/internal/model"
+	"dbapp/internal/repository"
+	"dbapp/internal/test"
+	"testing"
+	"time"
+)
+
+const browserUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64)"
+
+func TestViewCounter_DedupeAndFlush(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleRepo := repository.NewArticleRepository(db)
+	counter := NewViewCounter(articleRepo, time.Minute)
+
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "测试文章")
+
+	// 同一用户重复浏览只计一次
+	counter.Record(article.ID, user.ID, "1.1.1.1", browserUA)
+	counter.Record(article.ID, user.ID, "2.2.2.2", browserUA)
+	// 未登录用户按IP去重
+	counter.Record(article.ID, 0, "3.3.3.3", browserUA)
+	counter.Record(article.ID, 0, "3.3.3.3", browserUA)
+	// 爬虫不计数
+	if counter.Record(article.ID, 0, "4.4.4.4", "Googlebot/2.1") {
+		t.Error("爬虫浏览不应计数")
+	}
+
+	if err := counter.Flush(); err != nil {
+		t.Fatalf("写入浏览数失败: %v", err)
+	}
+
+	var found model.Article
+	db.First(&found, article.ID)
+	if found.ViewCount != 2 {
+		t.Errorf("期望浏览数 2, 得到 %d", found.ViewCount)
+	}
+}
+
+func TestViewCounter_StopFlushesPending(t *testing.T) {
+	db := test.SetupTestDB(t)
+	defer test.TeardownTestDB(db)
+
+	articleRepo := repository.NewArticleRepository(db)
+	counter := NewViewCounter(articleRepo, time.Minute)
+	counter.Start(time.Hour)
+
+	user := test.CreateTestUser(db, "testuser", "test@example.com")
+	article := test.CreateTestArticle(db, user.ID, "测试文章")
+	counter.Record(article.ID, user.ID, "1.1.1.1", browserUA)
+
+	if err := counter.Stop(); err != nil {
+		t.Fatalf("停止浏览计数器失败: %v", err)
+	}
+
+	var found model.Article
+	db.First(&found, article.ID)
+	if found.ViewCount != 1 {
+		t.Errorf("停止时应写入剩余浏览数, 得到 %d", found.ViewCount)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"dbapp/pkg/logger"
 	"fmt"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,7 +16,7 @@ import (
 var TestDB *gorm.DB
 
 // SetupTestDB 设置测试数据库
-func SetupTestDB(t *testing.T) *gorm.DB {
+func SetupTestDB(t testing.TB) *gorm.DB {
 	// 使用SQLite内存数据库进行测试
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
@@ -23,14 +24,7 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// 自动迁移
-	err = db.AutoMigrate(
-		&model.User{},
-		&model.Article{},
-		&model.Category{},
-		&model.Tag{},
-		&model.Comment{},
-		&model.Like{},
-	)
+	err = db.AutoMigrate(model.AllModels()...)
 	if err != nil {
 		t.Fatalf("数据库迁移失败: %v", err)
 	}
@@ -49,6 +43,17 @@ func TeardownTestDB(db *gorm.DB) {
 	}
 }
 
+// CountQueries 统计 db 之后执行的查询语句数（包括预加载），返回读取当前计数的函数
+func CountQueries(db *gorm.DB) func() int64 {
+	var count int64
+	db.Callback().Query().After("gorm:query").Register("test:count_queries", func(*gorm.DB) {
+		atomic.AddInt64(&count, 1)
+	})
+	return func() int64 {
+		return atomic.LoadInt64(&count)
+	}
+}
+
 // InitTestLogger 初始化测试日志
 func InitTestLogger() {
 	logger.Init("debug")
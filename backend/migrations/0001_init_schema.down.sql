@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS files;
+DROP TABLE IF EXISTS article_images;
+DROP TABLE IF EXISTS likes;
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS article_tags;
+DROP TABLE IF EXISTS article_categories;
+DROP TABLE IF EXISTS articles;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS users;
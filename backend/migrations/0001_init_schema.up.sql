@@ -0,0 +1,161 @@
+-- 初始表结构，与此前 AutoMigrate 生成的结构一致。
+-- 使用 IF NOT EXISTS，已由 AutoMigrate 建好表的数据库可以直接接入迁移。
+
+CREATE TABLE IF NOT EXISTS users (
+    id             BIGSERIAL PRIMARY KEY,
+    username       VARCHAR(50)  NOT NULL,
+    email          VARCHAR(255) NOT NULL,
+    password_hash  VARCHAR(255) NOT NULL,
+    nickname       VARCHAR(100),
+    avatar_url     VARCHAR(500),
+    bio            TEXT,
+    role           VARCHAR(20)  NOT NULL DEFAULT 'user',
+    status         VARCHAR(20)  NOT NULL DEFAULT 'active',
+    email_verified BOOLEAN DEFAULT FALSE,
+    last_login_at  TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ,
+    deleted_at     TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id            BIGSERIAL PRIMARY KEY,
+    name          VARCHAR(100) NOT NULL,
+    slug          VARCHAR(100) NOT NULL,
+    description   TEXT,
+    parent_id     BIGINT REFERENCES categories (id),
+    icon_url      VARCHAR(500),
+    sort_order    BIGINT DEFAULT 0,
+    article_count BIGINT DEFAULT 0,
+    is_active     BOOLEAN DEFAULT TRUE,
+    created_at    TIMESTAMPTZ,
+    updated_at    TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_slug ON categories (slug);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id            BIGSERIAL PRIMARY KEY,
+    name          VARCHAR(50) NOT NULL,
+    slug          VARCHAR(50) NOT NULL,
+    description   TEXT,
+    color         VARCHAR(20),
+    article_count BIGINT DEFAULT 0,
+    created_at    TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_slug ON tags (slug);
+
+CREATE TABLE IF NOT EXISTS articles (
+    id              BIGSERIAL PRIMARY KEY,
+    title           VARCHAR(500) NOT NULL,
+    slug            VARCHAR(500) NOT NULL,
+    content         TEXT NOT NULL,
+    content_html    TEXT,
+    summary         TEXT,
+    cover_image_url VARCHAR(500),
+    author_id       BIGINT NOT NULL REFERENCES users (id),
+    editor_id       BIGINT REFERENCES users (id),
+    status          VARCHAR(20) NOT NULL DEFAULT 'draft',
+    view_count      BIGINT DEFAULT 0,
+    like_count      BIGINT DEFAULT 0,
+    comment_count   BIGINT DEFAULT 0,
+    edit_count      BIGINT DEFAULT 0,
+    is_featured     BOOLEAN DEFAULT FALSE,
+    is_locked       BOOLEAN DEFAULT FALSE,
+    published_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ,
+    deleted_at      TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_articles_slug ON articles (slug);
+CREATE INDEX IF NOT EXISTS idx_articles_author_id ON articles (author_id);
+CREATE INDEX IF NOT EXISTS idx_articles_editor_id ON articles (editor_id);
+CREATE INDEX IF NOT EXISTS idx_articles_deleted_at ON articles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS article_categories (
+    article_id  BIGINT NOT NULL REFERENCES articles (id),
+    category_id BIGINT NOT NULL REFERENCES categories (id),
+    PRIMARY KEY (article_id, category_id)
+);
+
+CREATE TABLE IF NOT EXISTS article_tags (
+    article_id BIGINT NOT NULL REFERENCES articles (id),
+    tag_id     BIGINT NOT NULL REFERENCES tags (id),
+    PRIMARY KEY (article_id, tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id           BIGSERIAL PRIMARY KEY,
+    article_id   BIGINT NOT NULL REFERENCES articles (id),
+    user_id      BIGINT NOT NULL REFERENCES users (id),
+    parent_id    BIGINT REFERENCES comments (id),
+    content      TEXT NOT NULL,
+    content_html TEXT,
+    like_count   BIGINT DEFAULT 0,
+    reply_count  BIGINT DEFAULT 0,
+    status       VARCHAR(20) DEFAULT 'published',
+    ip_address   VARCHAR(45),
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    deleted_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_comments_article_id ON comments (article_id);
+CREATE INDEX IF NOT EXISTS idx_comments_user_id ON comments (user_id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments (deleted_at);
+
+CREATE TABLE IF NOT EXISTS likes (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT NOT NULL REFERENCES users (id),
+    target_type VARCHAR(20) NOT NULL,
+    target_id   BIGINT NOT NULL,
+    created_at  TIMESTAMPTZ
+);
+-- 旧版本没有唯一约束，建索引前清理重复点赞
+DELETE FROM likes WHERE id NOT IN (
+    SELECT MIN(id) FROM likes GROUP BY user_id, target_type, target_id
+);
+DROP INDEX IF EXISTS idx_likes_user_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_likes_user_target ON likes (user_id, target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_likes_target_type ON likes (target_type);
+CREATE INDEX IF NOT EXISTS idx_likes_target_id ON likes (target_id);
+
+CREATE TABLE IF NOT EXISTS article_images (
+    id         BIGSERIAL PRIMARY KEY,
+    article_id BIGINT NOT NULL REFERENCES articles (id),
+    image_url  VARCHAR(500) NOT NULL,
+    image_path VARCHAR(500),
+    file_size  BIGINT DEFAULT 0,
+    mime_type  VARCHAR(100),
+    width      BIGINT,
+    height     BIGINT,
+    alt        VARCHAR(500),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_article_images_article_id ON article_images (article_id);
+CREATE INDEX IF NOT EXISTS idx_article_images_image_url ON article_images (image_url);
+CREATE INDEX IF NOT EXISTS idx_article_images_deleted_at ON article_images (deleted_at);
+
+CREATE TABLE IF NOT EXISTS files (
+    id              BIGSERIAL PRIMARY KEY,
+    filename        VARCHAR(255) NOT NULL,
+    stored_filename VARCHAR(255) NOT NULL,
+    file_path       VARCHAR(500) NOT NULL,
+    file_url        VARCHAR(500) NOT NULL,
+    file_size       BIGINT NOT NULL,
+    mime_type       VARCHAR(100) NOT NULL,
+    file_type       VARCHAR(20) NOT NULL,
+    uploader_id     BIGINT NOT NULL REFERENCES users (id),
+    usage_count     BIGINT DEFAULT 0,
+    is_public       BOOLEAN DEFAULT TRUE,
+    created_at      TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_files_uploader_id ON files (uploader_id);
+CREATE INDEX IF NOT EXISTS idx_files_file_type ON files (file_type);
+CREATE INDEX IF NOT EXISTS idx_files_created_at ON files (created_at);
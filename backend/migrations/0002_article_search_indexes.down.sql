@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_articles_status_created_at;
+DROP INDEX IF EXISTS idx_articles_content_trgm;
+DROP INDEX IF EXISTS idx_articles_title_trgm;
@@ -0,0 +1,7 @@
+-- 文章关键词搜索使用 ILIKE '%keyword%'，普通B树索引无法使用，改用 pg_trgm 的GIN索引
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_articles_title_trgm ON articles USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_articles_content_trgm ON articles USING GIN (content gin_trgm_ops);
+
+-- 文章列表默认按状态过滤、按创建时间倒序
+CREATE INDEX IF NOT EXISTS idx_articles_status_created_at ON articles (status, created_at DESC);
@@ -0,0 +1,22 @@
+-- 管理员可修改的站点设置，未保存的设置使用代码中的默认值
+CREATE TABLE IF NOT EXISTS settings (
+    key        VARCHAR(100) PRIMARY KEY,
+    value      TEXT NOT NULL,
+    updated_by BIGINT REFERENCES users (id),
+    updated_at TIMESTAMPTZ
+);
+
+-- 站点设置修改记录
+CREATE TABLE IF NOT EXISTS setting_changes (
+    id         BIGSERIAL PRIMARY KEY,
+    key        VARCHAR(100) NOT NULL,
+    old_value  TEXT,
+    new_value  TEXT NOT NULL,
+    changed_by BIGINT NOT NULL REFERENCES users (id),
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_setting_changes_key ON setting_changes (key);
+CREATE INDEX IF NOT EXISTS idx_setting_changes_created_at ON setting_changes (created_at);
+
+-- 评论先审后发时新评论为 pending，审核列表按状态和时间查询
+CREATE INDEX IF NOT EXISTS idx_comments_status_created_at ON comments (status, created_at);
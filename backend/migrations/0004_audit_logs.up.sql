@@ -0,0 +1,16 @@
+-- 写操作审计日志，只追加不修改；不引用 users 外键，用户被删除后日志仍保留
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT,
+    ip          VARCHAR(45),
+    action      VARCHAR(50) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_id   BIGINT,
+    changes     TEXT,
+    request_id  VARCHAR(64),
+    created_at  TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target ON audit_logs (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_articles_status_visibility;
+ALTER TABLE articles DROP COLUMN IF EXISTS share_token;
+ALTER TABLE articles DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,6 @@
+-- 文章可见范围：public 所有人、members 登录用户、private 仅作者和编辑、unlisted 持有分享链接的人
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public';
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS share_token VARCHAR(64);
+
+-- 列表按状态和可见范围过滤
+CREATE INDEX IF NOT EXISTS idx_articles_status_visibility ON articles (status, visibility);
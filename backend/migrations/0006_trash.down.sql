@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_likes_deleted_at;
+ALTER TABLE likes DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+-- 文章、评论被删除时，相关点赞一起软删除，恢复时一起恢复
+ALTER TABLE likes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_likes_deleted_at ON likes (deleted_at);
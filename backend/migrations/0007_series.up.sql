@@ -0,0 +1,19 @@
+-- 文章系列：作者把多篇文章按顺序组织成一个专题，一篇文章最多属于一个系列
+CREATE TABLE IF NOT EXISTS series (
+    id          BIGSERIAL PRIMARY KEY,
+    title       VARCHAR(200) NOT NULL,
+    description TEXT,
+    author_id   BIGINT NOT NULL REFERENCES users (id),
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_series_author_id ON series (author_id);
+
+CREATE TABLE IF NOT EXISTS series_articles (
+    series_id  BIGINT NOT NULL REFERENCES series (id),
+    article_id BIGINT NOT NULL REFERENCES articles (id),
+    position   INTEGER NOT NULL,
+    PRIMARY KEY (series_id, article_id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_series_articles_article_id ON series_articles (article_id);
+CREATE INDEX IF NOT EXISTS idx_series_articles_position ON series_articles (series_id, position);
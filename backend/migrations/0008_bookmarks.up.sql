@@ -0,0 +1,23 @@
+-- 收藏和阅读列表：用户收藏文章并整理到命名的阅读列表中，公开的列表其他用户也可以查看
+CREATE TABLE IF NOT EXISTS reading_lists (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT NOT NULL REFERENCES users (id),
+    name        VARCHAR(100) NOT NULL,
+    description TEXT,
+    is_public   BOOLEAN DEFAULT FALSE,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reading_lists_user_name ON reading_lists (user_id, name);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT NOT NULL REFERENCES users (id),
+    article_id      BIGINT NOT NULL REFERENCES articles (id),
+    reading_list_id BIGINT REFERENCES reading_lists (id),
+    position        INTEGER NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_user_article ON bookmarks (user_id, article_id);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_article_id ON bookmarks (article_id);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_list_position ON bookmarks (reading_list_id, position);
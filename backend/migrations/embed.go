@@ -0,0 +1,9 @@
+// Package migrations 内嵌版本化的SQL迁移文件。
+//
+// 文件命名: {版本号}_{名称}.up.sql / {版本号}_{名称}.down.sql，版本号递增且不可修改已发布的文件。
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
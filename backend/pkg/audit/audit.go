@@ -0,0 +1,121 @@
+// Package audit 提供审计日志所需的操作者上下文和字段差异计算
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"unicode/utf8"
+)
+
+// Actor 执行写操作的用户和请求信息，UserID 为 0 表示未登录用户或系统操作
+type Actor struct {
+	UserID    uint64
+	IP        string
+	RequestID string
+}
+
+type contextKey struct{}
+
+// NewContext 返回携带操作者信息的 context
+func NewContext(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, contextKey{}, actor)
+}
+
+// WithUser 在 context 已有的操作者信息上设置用户ID，用于认证通过之后
+func WithUser(ctx context.Context, userID uint64) context.Context {
+	actor := FromContext(ctx)
+	actor.UserID = userID
+	return NewContext(ctx, actor)
+}
+
+// FromContext 返回 context 中的操作者信息，没有时返回零值
+func FromContext(ctx context.Context) Actor {
+	if ctx != nil {
+		if actor, ok := ctx.Value(contextKey{}).(Actor); ok {
+			return actor
+		}
+	}
+	return Actor{}
+}
+
+// Change 一个字段修改前后的值，新增时 Old 为空，删除时 New 为空
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// MaxValueLength 字符串值超过该长度时截断，避免文章正文等大字段撑大审计日志
+const MaxValueLength = 1000
+
+// ignoredFields 每次更新都会变化、没有审计意义的字段
+var ignoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// Diff 比较两个对象按JSON序列化后的字段，返回发生变化的字段。
+// before 为 nil 表示新增，after 为 nil 表示删除。
+// 标记为 json:"-" 的字段（如密码哈希）不会出现在结果中；关联对象不参与比较。
+func Diff(before, after interface{}) map[string]Change {
+	old := toFields(before)
+	new := toFields(after)
+
+	changes := make(map[string]Change)
+	for _, fields := range []map[string]interface{}{old, new} {
+		for key := range fields {
+			oldValue, newValue := old[key], new[key]
+			// 关联对象在一侧未加载时为 null，两侧都要判断
+			if ignoredFields[key] || isNested(oldValue) || isNested(newValue) {
+				continue
+			}
+			if !reflect.DeepEqual(oldValue, newValue) {
+				changes[key] = Change{Old: truncate(oldValue), New: truncate(newValue)}
+			}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func toFields(v interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if v == nil {
+		return fields
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return fields
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// isNested 判断是否为关联对象或关联对象列表，标量数组（如ID列表）仍参与比较
+func isNested(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return true
+	case []interface{}:
+		for _, item := range v {
+			if _, ok := item.(map[string]interface{}); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func truncate(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok || utf8.RuneCountInString(s) <= MaxValueLength {
+		return value
+	}
+	return string([]rune(s)[:MaxValueLength]) + "...(已截断)"
+}
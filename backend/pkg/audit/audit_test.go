@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testUser struct {
+	ID        uint64     `json:"id"`
+	Name      string     `json:"name"`
+	Password  string     `json:"-"`
+	TagIDs    []uint64   `json:"tag_ids"`
+	Parent    *testUser  `json:"parent"`
+	Children  []testUser `json:"children"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func TestDiff_Update(t *testing.T) {
+	before := &testUser{ID: 1, Name: "old", Password: "a", TagIDs: []uint64{1}, UpdatedAt: time.Unix(1, 0)}
+	after := &testUser{ID: 1, Name: "new", Password: "b", TagIDs: []uint64{1, 2}, UpdatedAt: time.Unix(2, 0),
+		Parent: &testUser{ID: 2}, Children: []testUser{{ID: 3}}}
+
+	changes := Diff(before, after)
+	if len(changes) != 2 {
+		t.Fatalf("期望 2 个字段变化, 得到 %v", changes)
+	}
+	if changes["name"].Old != "old" || changes["name"].New != "new" {
+		t.Errorf("name 变化不正确: %+v", changes["name"])
+	}
+	if _, ok := changes["tag_ids"]; !ok {
+		t.Error("标量数组变化应被记录")
+	}
+	for _, key := range []string{"password", "updated_at", "parent", "children"} {
+		if _, ok := changes[key]; ok {
+			t.Errorf("字段 %s 不应参与比较", key)
+		}
+	}
+}
+
+func TestDiff_CreateAndDelete(t *testing.T) {
+	user := &testUser{ID: 1, Name: "u"}
+
+	created := Diff(nil, user)
+	if created["name"].Old != nil || created["name"].New != "u" {
+		t.Errorf("新增时旧值应为空: %+v", created["name"])
+	}
+
+	var nilUser *testUser
+	deleted := Diff(user, nilUser)
+	if deleted["name"].Old != "u" || deleted["name"].New != nil {
+		t.Errorf("删除时新值应为空: %+v", deleted["name"])
+	}
+
+	if changes := Diff(user, user); changes != nil {
+		t.Errorf("没有变化时应返回 nil, 得到 %v", changes)
+	}
+}
+
+func TestDiff_Truncate(t *testing.T) {
+	long := strings.Repeat("文", MaxValueLength+10)
+	changes := Diff(nil, &testUser{Name: long})
+
+	value := changes["name"].New.(string)
+	if !strings.HasSuffix(value, "...(已截断)") {
+		t.Errorf("超长字符串应被截断")
+	}
+	if !strings.HasPrefix(value, strings.Repeat("文", MaxValueLength)) {
+		t.Errorf("截断应按字符而不是字节")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if actor := FromContext(context.Background()); actor != (Actor{}) {
+		t.Errorf("没有操作者时应返回零值, 得到 %+v", actor)
+	}
+
+	ctx := NewContext(context.Background(), Actor{IP: "1.2.3.4", RequestID: "req"})
+	ctx = WithUser(ctx, 7)
+
+	actor := FromContext(ctx)
+	if actor.UserID != 7 || actor.IP != "1.2.3.4" || actor.RequestID != "req" {
+		t.Errorf("操作者信息不正确: %+v", actor)
+	}
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrMiss 缓存未命中
+var ErrMiss = errors.New("cache: key not found")
+
+// Cache 缓存接口，值以字节形式存取，由调用方负责序列化
+type Cache interface {
+	Get(key string) ([]byte, error)
+	// Set 写入缓存，ttl<=0 时使用实现的默认过期时间
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(keys ...string) error
+	// Incr 原子地把 key 的整数值加1并返回新值，键不存在时从0开始；
+	// 每次调用都把过期时间重置为 ttl，ttl<=0 时使用实现的默认过期时间
+	Incr(key string, ttl time.Duration) (int64, error)
+	// Expire 重新设置 key 的过期时间，键不存在时忽略
+	Expire(key string, ttl time.Duration) error
+	// DeletePrefix 删除所有以 prefix 开头的键，用于让一组列表缓存同时失效
+	DeletePrefix(prefix string) error
+}
+
+// WithContext 返回使用 ctx 访问后端的缓存，实现不支持 context 时原样返回
+func WithContext(c Cache, ctx context.Context) Cache {
+	if cc, ok := c.(interface {
+		WithContext(ctx context.Context) Cache
+	}); ok {
+		return cc.WithContext(ctx)
+	}
+	return c
+}
+
+var loads = &group{calls: make(map[string]*call)}
+
+// Remember 从缓存读取 key 并反序列化到 dest；未命中时调用 load 加载并写入缓存。
+// 同一进程内对同一个 key 的并发加载只会执行一次，避免缓存失效瞬间大量请求击穿到数据库。
+// 缓存读写失败不影响结果，只退化为直接调用 load。
+func Remember(c Cache, key string, ttl time.Duration, dest interface{}, load func() (interface{}, error)) error {
+	if c == nil {
+		value, err := load()
+		if err != nil {
+			return err
+		}
+		return assign(value, dest)
+	}
+
+	if data, err := c.Get(key); err == nil {
+		if err := json.Unmarshal(data, dest); err == nil {
+			return nil
+		}
+	}
+
+	data, err := loads.do(key, func() ([]byte, error) {
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		c.Set(key, data, ttl)
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// assign 通过JSON把 value 复制到 dest，保证有无缓存时行为一致
+func assign(value interface{}, dest interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// group 合并对同一个 key 的并发调用
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	wg   sync.WaitGroup
+	data []byte
+	err  error
+}
+
+func (g *group) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.data, c.err
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.data, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return c.data, c.err
+}
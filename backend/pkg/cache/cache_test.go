@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_Expire(t *testing.T) {
+	c := NewMemoryCache(time.Minute)
+	c.Set("a", []byte("1"), 10*time.Millisecond)
+
+	if _, err := c.Get("a"); err != nil {
+		t.Fatalf("读取缓存失败: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := c.Get("a"); err != ErrMiss {
+		t.Errorf("期望缓存过期, 得到 %v", err)
+	}
+}
+
+func TestMemoryCache_PrunesExpiredKeys(t *testing.T) {
+	c := NewMemoryCache(time.Minute)
+	c.Set("expired", []byte("1"), time.Millisecond)
+	c.Incr("login:failures:unknown", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// 过期的键不再被访问，也应在后续写入时被清理
+	for i := 0; i < 1000; i++ {
+		c.Set("key", []byte("1"), 0)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.items) != 1 {
+		t.Errorf("期望只剩 1 个键, 得到 %d", len(c.items))
+	}
+}
+
+func TestMemoryCache_DeletePrefix(t *testing.T) {
+	c := NewMemoryCache(time.Minute)
+	c.Set("tags:list:a", []byte("1"), 0)
+	c.Set("tags:list:b", []byte("2"), 0)
+	c.Set("article:1", []byte("3"), 0)
+
+	c.DeletePrefix("tags:list:")
+
+	if _, err := c.Get("tags:list:a"); err != ErrMiss {
+		t.Error("tags:list:a 应被删除")
+	}
+	if _, err := c.Get("article:1"); err != nil {
+		t.Error("article:1 不应被删除")
+	}
+}
+
+func TestMemoryCache_IncrConcurrent(t *testing.T) {
+	c := NewMemoryCache(time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Incr("counter", time.Minute)
+		}()
+	}
+	wg.Wait()
+
+	if n, err := c.Incr("counter", time.Minute); err != nil || n != 51 {
+		t.Errorf("期望计数 51, 得到 %d (%v)", n, err)
+	}
+}
+
+func TestRemember_LoadOnce(t *testing.T) {
+	c := NewMemoryCache(time.Minute)
+	var calls int32
+
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return []string{"x"}, nil
+	}
+
+	// 并发未命中时只加载一次
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var dest []string
+			if err := Remember(c, "k", 0, &dest, load); err != nil || len(dest) != 1 {
+				t.Errorf("读取失败: %v %v", dest, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 再次读取命中缓存
+	var dest []string
+	Remember(c, "k", 0, &dest, load)
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("期望加载 1 次, 得到 %d", n)
+	}
+}
+
+func TestRemember_ErrorNotCached(t *testing.T) {
+	c := NewMemoryCache(time.Minute)
+	wantErr := errors.New("db down")
+
+	var dest string
+	if err := Remember(c, "k", 0, &dest, func() (interface{}, error) { return nil, wantErr }); err != wantErr {
+		t.Fatalf("期望返回加载错误, 得到 %v", err)
+	}
+	if _, err := c.Get("k"); err != ErrMiss {
+		t.Error("加载失败时不应写入缓存")
+	}
+}
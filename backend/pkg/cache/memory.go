@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache 进程内缓存，用于未配置Redis的单实例部署和测试。
+// 过期的键在读取时删除，另外每写入1000次清理一遍，避免不再访问的键一直占用内存
+type MemoryCache struct {
+	mu         sync.RWMutex
+	items      map[string]memoryItem
+	defaultTTL time.Duration
+	writes     int
+}
+
+// NewMemoryCache 创建进程内缓存，Set 时 ttl<=0 使用 defaultTTL
+func NewMemoryCache(defaultTTL time.Duration) *MemoryCache {
+	return &MemoryCache{items: make(map[string]memoryItem), defaultTTL: defaultTTL}
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, error) {
+	c.mu.RLock()
+	item, ok := c.items[key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, ErrMiss
+	}
+	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		c.mu.Lock()
+		// 重新检查，避免删除并发写入的新值
+		if current, ok := c.items[key]; ok && current.expiresAt.Equal(item.expiresAt) {
+			delete(c.items, key)
+		}
+		c.mu.Unlock()
+		return nil, ErrMiss
+	}
+	return item.value, nil
+}
+
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	item := memoryItem{value: value}
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+	c.mu.Lock()
+	c.items[key] = item
+	c.written(time.Now())
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *MemoryCache) Incr(key string, ttl time.Duration) (int64, error) {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var n int64
+	if item, ok := c.items[key]; ok && (item.expiresAt.IsZero() || now.Before(item.expiresAt)) {
+		var err error
+		if n, err = strconv.ParseInt(string(item.value), 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	n++
+	item := memoryItem{value: []byte(strconv.FormatInt(n, 10))}
+	if ttl > 0 {
+		item.expiresAt = now.Add(ttl)
+	}
+	c.items[key] = item
+	c.written(now)
+	return n, nil
+}
+
+func (c *MemoryCache) Expire(key string, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.items[key]
+	if !ok || (!item.expiresAt.IsZero() && time.Now().After(item.expiresAt)) {
+		return nil
+	}
+	item.expiresAt = time.Time{}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+	c.items[key] = item
+	return nil
+}
+
+func (c *MemoryCache) Delete(keys ...string) error {
+	c.mu.Lock()
+	for _, key := range keys {
+		delete(c.items, key)
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *MemoryCache) DeletePrefix(prefix string) error {
+	c.mu.Lock()
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.items, key)
+		}
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// written 记录一次写入，每1000次清理过期的键，调用方需持有锁
+func (c *MemoryCache) written(now time.Time) {
+	c.writes++
+	if c.writes%1000 == 0 {
+		c.prune(now)
+	}
+}
+
+// prune 删除已过期的键，调用方需持有锁
+func (c *MemoryCache) prune(now time.Time) {
+	for key, item := range c.items {
+		if !item.expiresAt.IsZero() && now.After(item.expiresAt) {
+			delete(c.items, key)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache 基于Redis的缓存，多实例部署时共享
+type RedisCache struct {
+	client     *redis.Client
+	prefix     string
+	defaultTTL time.Duration
+	ctx        context.Context
+}
+
+// NewRedisCache 创建Redis缓存，所有键会加上 prefix 以免与其他数据冲突，
+// Set 时 ttl<=0 使用 defaultTTL
+func NewRedisCache(client *redis.Client, prefix string, defaultTTL time.Duration) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix, defaultTTL: defaultTTL, ctx: context.Background()}
+}
+
+// WithContext 返回使用 ctx 访问Redis的副本，用于链路追踪
+func (c *RedisCache) WithContext(ctx context.Context) Cache {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+func (c *RedisCache) Get(key string) ([]byte, error) {
+	data, err := c.client.Get(c.ctx, c.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrMiss
+	}
+	return data, err
+}
+
+func (c *RedisCache) Set(key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	return c.client.Set(c.ctx, c.prefix+key, value, ttl).Err()
+}
+
+// Incr 用 MULTI/EXEC 同时执行 INCR 和 PEXPIRE，多实例并发计数不会丢失
+func (c *RedisCache) Incr(key string, ttl time.Duration) (int64, error) {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	var incr *redis.IntCmd
+	_, err := c.client.TxPipelined(c.ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(c.ctx, c.prefix+key)
+		if ttl > 0 {
+			pipe.PExpire(c.ctx, c.prefix+key, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (c *RedisCache) Expire(key string, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	if ttl <= 0 {
+		return c.client.Persist(c.ctx, c.prefix+key).Err()
+	}
+	return c.client.PExpire(c.ctx, c.prefix+key, ttl).Err()
+}
+
+func (c *RedisCache) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.client.Del(c.ctx, prefixed...).Err()
+}
+
+func (c *RedisCache) DeletePrefix(prefix string) error {
+	ctx := c.ctx
+	iter := c.client.Scan(ctx, 0, c.prefix+prefix+"*", 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) >= 100 {
+			if err := c.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return c.client.Del(ctx, keys...).Err()
+	}
+	return nil
+}
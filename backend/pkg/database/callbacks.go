@@ -0,0 +1,29 @@
+package database
+
+import "gorm.io/gorm"
+
+// registerAround 在GORM每类操作的执行前后注册回调，after 按操作名区分
+func registerAround(db *gorm.DB, name string, before func(tx *gorm.DB), after func(operation string) func(tx *gorm.DB)) error {
+	cb := db.Callback()
+	steps := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, step := range steps {
+		if err := step.before(name+":before_"+step.operation, before); err != nil {
+			return err
+		}
+		if err := step.after(name+":after_"+step.operation, after(step.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"dbapp/pkg/metrics"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const metricsStartKey = "metrics:start"
+
+// RegisterMetrics 注册连接池状态指标，并通过GORM回调统计SQL耗时
+func RegisterMetrics(db *gorm.DB, registry *metrics.Registry) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	registry.NewGaugeFunc("db_max_open_connections", "连接池最大连接数", func() float64 {
+		return float64(sqlDB.Stats().MaxOpenConnections)
+	})
+	registry.NewGaugeFunc("db_open_connections", "已建立的连接数", func() float64 {
+		return float64(sqlDB.Stats().OpenConnections)
+	})
+	registry.NewGaugeFunc("db_in_use_connections", "正在使用的连接数", func() float64 {
+		return float64(sqlDB.Stats().InUse)
+	})
+	registry.NewGaugeFunc("db_idle_connections", "空闲连接数", func() float64 {
+		return float64(sqlDB.Stats().Idle)
+	})
+	registry.NewCounterFunc("db_wait_count_total", "等待空闲连接的总次数", func() float64 {
+		return float64(sqlDB.Stats().WaitCount)
+	})
+	registry.NewCounterFunc("db_wait_duration_seconds_total", "等待空闲连接的总耗时（秒）", func() float64 {
+		return sqlDB.Stats().WaitDuration.Seconds()
+	})
+
+	duration := registry.NewHistogramVec("db_query_duration_seconds", "SQL执行耗时（秒）",
+		[]float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}, "operation")
+	errorsTotal := registry.NewCounterVec("db_query_errors_total", "SQL执行失败数（不含记录不存在）", "operation")
+
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(metricsStartKey, time.Now())
+	}
+	after := func(operation string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			start, ok := tx.InstanceGet(metricsStartKey)
+			if !ok {
+				return
+			}
+			duration.WithLabelValues(operation).Observe(time.Since(start.(time.Time)).Seconds())
+			if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+				errorsTotal.WithLabelValues(operation).Inc()
+			}
+		}
+	}
+
+	return registerAround(db, "metrics", before, after)
+}
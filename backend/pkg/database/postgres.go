@@ -56,3 +56,15 @@ func GetDB() *gorm.DB {
 	return DB
 }
 
+
+// Close 关闭数据库连接池，应在所有后台任务停止后调用
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -35,6 +35,14 @@ func GetRedis() *redis.Client {
 	return RedisClient
 }
 
+// CloseRedis 关闭Redis连接
+func CloseRedis() error {
+	if RedisClient == nil {
+		return nil
+	}
+	return RedisClient.Close()
+}
+
 // Cache操作封装
 func Set(key string, value interface{}, expiration time.Duration) error {
 	return RedisClient.Set(ctx, key, value, expiration).Err()
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type tracedItem struct {
+	ID   uint
+	Name string
+}
+
+func TestRegisterTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	old := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(old)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接测试数据库: %v", err)
+	}
+	if err := db.AutoMigrate(&tracedItem{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	if err := RegisterTracing(db); err != nil {
+		t.Fatalf("注册SQL追踪失败: %v", err)
+	}
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	db.WithContext(ctx).Create(&tracedItem{Name: "a"})
+	var items []tracedItem
+	db.WithContext(ctx).Where("name = ?", "a").Find(&items)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("期望 3 个span, 得到 %d", len(spans))
+	}
+	for _, span := range spans[:2] {
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("SQL span %s 应挂在请求span下", span.Name)
+		}
+	}
+	if spans[1].Name != "gorm.query traced_items" {
+		t.Errorf("期望span名称 gorm.query traced_items, 得到 %s", spans[1].Name)
+	}
+}
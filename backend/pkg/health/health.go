@@ -0,0 +1,116 @@
+// Package health 执行存活/就绪检查。
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+)
+
+// CheckFunc 检查一个依赖，返回附加信息（可为空）和错误
+type CheckFunc func(ctx context.Context) (string, error)
+
+type check struct {
+	name     string
+	critical bool
+	fn       CheckFunc
+}
+
+// Result 单个检查的结果
+type Result struct {
+	Status     string `json:"status"`
+	Critical   bool   `json:"critical"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report 汇总的检查结果
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Ready 是否可以接收流量，只有关键依赖失败时才返回 false
+func (r *Report) Ready() bool {
+	return r.Status != StatusDown
+}
+
+// Checker 管理一组依赖检查
+type Checker struct {
+	timeout time.Duration
+	checks  []check
+}
+
+// NewChecker 创建检查器，timeout 为单个检查的超时时间
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add 添加检查。critical 为 true 时检查失败视为不可用，
+// 否则只标记为降级（例如 Redis 不可用时会退化为内存缓存）
+func (c *Checker) Add(name string, critical bool, fn CheckFunc) {
+	c.checks = append(c.checks, check{name: name, critical: critical, fn: fn})
+}
+
+// Run 并发执行所有检查
+func (c *Checker) Run(ctx context.Context) *Report {
+	report := &Report{Status: StatusUp, Checks: make(map[string]Result, len(c.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, ch := range c.checks {
+		wg.Add(1)
+		go func(ch check) {
+			defer wg.Done()
+			result := c.run(ctx, ch)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[ch.name] = result
+			if result.Status == StatusDown {
+				if ch.critical {
+					report.Status = StatusDown
+				} else if report.Status == StatusUp {
+					report.Status = StatusDegraded
+				}
+			}
+		}(ch)
+	}
+	wg.Wait()
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, ch check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan Result, 1)
+	go func() {
+		detail, err := ch.fn(ctx)
+		result := Result{Status: StatusUp, Detail: detail}
+		if err != nil {
+			result.Status = StatusDown
+			result.Error = err.Error()
+		}
+		done <- result
+	}()
+
+	var result Result
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		// 检查函数未响应 context 时也不阻塞整个请求
+		result = Result{Status: StatusDown, Error: "检查超时"}
+	}
+	result.Critical = ch.critical
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result
+}
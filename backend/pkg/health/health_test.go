@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChecker_AllUp(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Add("db", true, func(ctx context.Context) (string, error) { return "", nil })
+	c.Add("migrations", true, func(ctx context.Context) (string, error) { return "version 2", nil })
+
+	report := c.Run(context.Background())
+	if report.Status != StatusUp || !report.Ready() {
+		t.Fatalf("期望状态 up, 得到 %s", report.Status)
+	}
+	if report.Checks["migrations"].Detail != "version 2" {
+		t.Errorf("期望附加信息 version 2, 得到 %q", report.Checks["migrations"].Detail)
+	}
+}
+
+func TestChecker_NonCriticalFailureDegrades(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Add("db", true, func(ctx context.Context) (string, error) { return "", nil })
+	c.Add("redis", false, func(ctx context.Context) (string, error) { return "", errors.New("connection refused") })
+
+	report := c.Run(context.Background())
+	if report.Status != StatusDegraded {
+		t.Fatalf("期望状态 degraded, 得到 %s", report.Status)
+	}
+	if !report.Ready() {
+		t.Error("非关键依赖失败时仍应就绪")
+	}
+	if report.Checks["redis"].Error != "connection refused" {
+		t.Errorf("期望返回错误信息, 得到 %q", report.Checks["redis"].Error)
+	}
+}
+
+func TestChecker_CriticalFailure(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Add("db", true, func(ctx context.Context) (string, error) { return "", errors.New("down") })
+	c.Add("redis", false, func(ctx context.Context) (string, error) { return "", errors.New("down") })
+
+	report := c.Run(context.Background())
+	if report.Status != StatusDown || report.Ready() {
+		t.Fatalf("期望状态 down, 得到 %s", report.Status)
+	}
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	c := NewChecker(20 * time.Millisecond)
+	block := make(chan struct{})
+	defer close(block)
+	c.Add("db", true, func(ctx context.Context) (string, error) {
+		<-block
+		return "", nil
+	})
+
+	start := time.Now()
+	report := c.Run(context.Background())
+	if time.Since(start) > time.Second {
+		t.Fatal("检查超时后不应继续阻塞")
+	}
+	if report.Checks["db"].Status != StatusDown {
+		t.Errorf("超时的检查应为 down, 得到 %s", report.Checks["db"].Status)
+	}
+}
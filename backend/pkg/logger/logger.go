@@ -1,23 +1,29 @@
 package logger
 
 import (
+	"context"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 var Log *zap.Logger
 
+// level 当前日志级别，可在运行时通过 SetLevel 修改
+var level = zap.NewAtomicLevel()
+
 func Init(mode string) {
 	var config zap.Config
 
 	if mode == "production" || mode == "release" {
 		config = zap.NewProductionConfig()
-		config.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+		level.SetLevel(zap.InfoLevel)
 	} else {
 		config = zap.NewDevelopmentConfig()
-		config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+		level.SetLevel(zap.DebugLevel)
 		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
+	config.Level = level
 
 	config.EncoderConfig.TimeKey = "timestamp"
 	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
@@ -30,6 +36,8 @@ func Init(mode string) {
 	if err != nil {
 		panic("初始化日志失败: " + err.Error())
 	}
+	// 使 zap.L() 输出到同一个 logger
+	zap.ReplaceGlobals(Log)
 
 	Log.Info("日志系统初始化成功")
 }
@@ -54,3 +62,50 @@ func Fatal(msg string, fields ...zap.Field) {
 	Log.Fatal(msg, fields...)
 }
 
+
+// SetLevel 修改日志级别，name 为空时使用 mode 对应的默认级别
+func SetLevel(name, mode string) error {
+	if name == "" {
+		if mode == "production" || mode == "release" {
+			name = "info"
+		} else {
+			name = "debug"
+		}
+	}
+	l, err := zapcore.ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.SetLevel(l)
+	return nil
+}
+
+// Sync 刷新缓冲的日志，进程退出前调用
+func Sync() {
+	if Log != nil {
+		_ = Log.Sync()
+	}
+}
+
+// RequestIDKey 请求ID在 gin.Context 中的键名，也是日志字段名
+const RequestIDKey = "request_id"
+
+type contextKey struct{}
+
+// NewContext 返回携带 logger 的 context
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext 返回 context 中的请求级 logger，没有时返回全局 logger
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+			return l
+		}
+	}
+	if Log != nil {
+		return Log
+	}
+	return zap.L()
+}
@@ -0,0 +1,338 @@
+// Package metrics 提供计数器、直方图和采集函数，并以 Prometheus 文本格式输出。
+//
+// 只实现了本项目用到的指标类型，不依赖 Prometheus 客户端库。
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBuckets 请求耗时直方图的默认分桶（秒）
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// collector 一个指标族
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry 指标注册表
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+// NewRegistry 创建空的注册表
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// Default 全局注册表，/metrics 输出其中的指标
+var Default = NewRegistry()
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.collectors[c.name()]; ok {
+		panic("metrics: 重复注册指标 " + c.name())
+	}
+	r.collectors[c.name()] = c
+}
+
+// WriteText 以 Prometheus 文本格式输出全部指标，按名称排序
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collectors := make([]collector, 0, len(names))
+	for _, name := range names {
+		collectors = append(collectors, r.collectors[name])
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler 返回输出指标的 http.Handler
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// ---- Counter ----
+
+// Counter 只增不减的计数器
+type Counter struct {
+	bits uint64
+}
+
+// Inc 加1
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add 增加 v，v 不能为负数
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		return
+	}
+	addFloat(&c.bits, v)
+}
+
+// Value 当前值
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&c.bits))
+}
+
+// CounterVec 按标签区分的一组计数器
+type CounterVec struct {
+	vec[*Counter]
+}
+
+// NewCounterVec 创建并注册计数器，labels 为空时可直接调用 WithLabelValues()
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{vec[*Counter]{
+		metricName: name,
+		help:       help,
+		typ:        "counter",
+		labels:     labels,
+		newMetric:  func() *Counter { return &Counter{} },
+	}}
+	v.writeSamples = func(w *bufio.Writer, labelPairs string, c *Counter) {
+		writeSample(w, name, labelPairs, c.Value())
+	}
+	r.register(v)
+	return v
+}
+
+// NewCounter 创建并注册不带标签的计数器
+func (r *Registry) NewCounter(name, help string) *Counter {
+	return r.NewCounterVec(name, help).WithLabelValues()
+}
+
+// ---- Histogram ----
+
+// Histogram 直方图，记录观测值的分布
+type Histogram struct {
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sumBits uint64
+}
+
+// Observe 记录一次观测值
+func (h *Histogram) Observe(v float64) {
+	for i, upper := range h.buckets {
+		if v <= upper {
+			atomic.AddUint64(&h.counts[i], 1)
+			break
+		}
+	}
+	atomic.AddUint64(&h.count, 1)
+	addFloat(&h.sumBits, v)
+}
+
+// HistogramVec 按标签区分的一组直方图
+type HistogramVec struct {
+	vec[*Histogram]
+}
+
+// NewHistogramVec 创建并注册直方图，buckets 为空时使用 DefaultBuckets
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	v := &HistogramVec{vec[*Histogram]{
+		metricName: name,
+		help:       help,
+		typ:        "histogram",
+		labels:     labels,
+		newMetric: func() *Histogram {
+			return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+		},
+	}}
+	v.writeSamples = func(w *bufio.Writer, labelPairs string, h *Histogram) {
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += atomic.LoadUint64(&h.counts[i])
+			writeSample(w, name+"_bucket", joinLabels(labelPairs, `le="`+formatFloat(upper)+`"`), float64(cumulative))
+		}
+		count := atomic.LoadUint64(&h.count)
+		writeSample(w, name+"_bucket", joinLabels(labelPairs, `le="+Inf"`), float64(count))
+		writeSample(w, name+"_sum", labelPairs, math.Float64frombits(atomic.LoadUint64(&h.sumBits)))
+		writeSample(w, name+"_count", labelPairs, float64(count))
+	}
+	r.register(v)
+	return v
+}
+
+// ---- Func ----
+
+// funcCollector 在输出时调用函数取值，用于连接池状态等已有的统计数据
+type funcCollector struct {
+	metricName string
+	help       string
+	typ        string
+	fn         func() float64
+}
+
+func (f *funcCollector) name() string { return f.metricName }
+
+func (f *funcCollector) write(w *bufio.Writer) {
+	writeHeader(w, f.metricName, f.help, f.typ)
+	writeSample(w, f.metricName, "", f.fn())
+}
+
+// NewGaugeFunc 注册一个输出时取值的 gauge
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcCollector{metricName: name, help: help, typ: "gauge", fn: fn})
+}
+
+// NewCounterFunc 注册一个输出时取值的 counter，fn 的返回值必须单调递增
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcCollector{metricName: name, help: help, typ: "counter", fn: fn})
+}
+
+// ---- 公共实现 ----
+
+// vec 按标签值保存指标实例
+type vec[T any] struct {
+	metricName   string
+	help         string
+	typ          string
+	labels       []string
+	newMetric    func() T
+	writeSamples func(w *bufio.Writer, labelPairs string, m T)
+
+	mu      sync.RWMutex
+	metrics map[string]T
+	values  map[string][]string
+}
+
+func (v *vec[T]) name() string { return v.metricName }
+
+// WithLabelValues 返回标签值对应的指标实例，不存在时创建
+func (v *vec[T]) WithLabelValues(values ...string) T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s 需要 %d 个标签值, 得到 %d", v.metricName, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	m, ok := v.metrics[key]
+	v.mu.RUnlock()
+	if ok {
+		return m
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if m, ok := v.metrics[key]; ok {
+		return m
+	}
+	if v.metrics == nil {
+		v.metrics = make(map[string]T)
+		v.values = make(map[string][]string)
+	}
+	m = v.newMetric()
+	v.metrics[key] = m
+	v.values[key] = append([]string(nil), values...)
+	return m
+}
+
+func (v *vec[T]) write(w *bufio.Writer) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.metrics))
+	for key := range v.metrics {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	metrics := make([]T, len(keys))
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		metrics[i] = v.metrics[key]
+		pairs[i] = formatLabels(v.labels, v.values[key])
+	}
+	v.mu.RUnlock()
+
+	writeHeader(w, v.metricName, v.help, v.typ)
+	for i := range metrics {
+		v.writeSamples(w, pairs[i], metrics[i])
+	}
+}
+
+func addFloat(bits *uint64, v float64) {
+	for {
+		old := atomic.LoadUint64(bits)
+		next := math.Float64bits(math.Float64frombits(old) + v)
+		if atomic.CompareAndSwapUint64(bits, old, next) {
+			return
+		}
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + `="` + labelEscaper.Replace(values[i]) + `"`
+	}
+	return strings.Join(parts, ",")
+}
+
+func joinLabels(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "," + b
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func writeHeader(w *bufio.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, helpEscaper.Replace(help), name, typ)
+}
+
+func writeSample(w *bufio.Writer, name, labelPairs string, value float64) {
+	if labelPairs == "" {
+		fmt.Fprintf(w, "%s %s\n", name, formatFloat(value))
+		return
+	}
+	fmt.Fprintf(w, "%s{%s} %s\n", name, labelPairs, formatFloat(value))
+}
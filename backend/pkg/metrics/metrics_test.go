@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, r *Registry) string {
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("输出指标失败: %v", err)
+	}
+	return b.String()
+}
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("requests_total", "请求数", "method", "status")
+	c.WithLabelValues("GET", "2xx").Inc()
+	c.WithLabelValues("GET", "2xx").Add(2)
+	c.WithLabelValues("POST", "5xx").Inc()
+
+	out := render(t, r)
+	for _, want := range []string{
+		"# TYPE requests_total counter",
+		`requests_total{method="GET",status="2xx"} 3`,
+		`requests_total{method="POST",status="5xx"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCounter_NoLabels(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("articles_created_total", "创建的文章数")
+	c.Inc()
+
+	if out := render(t, r); !strings.Contains(out, "articles_created_total 1\n") {
+		t.Errorf("输出不正确:\n%s", out)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("latency_seconds", "耗时", []float64{0.1, 1}, "route")
+	h.WithLabelValues("/a").Observe(0.05)
+	h.WithLabelValues("/a").Observe(0.5)
+	h.WithLabelValues("/a").Observe(3)
+
+	out := render(t, r)
+	for _, want := range []string{
+		"# TYPE latency_seconds histogram",
+		`latency_seconds_bucket{route="/a",le="0.1"} 1`,
+		`latency_seconds_bucket{route="/a",le="1"} 2`,
+		`latency_seconds_bucket{route="/a",le="+Inf"} 3`,
+		`latency_seconds_sum{route="/a"} 3.55`,
+		`latency_seconds_count{route="/a"} 3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGaugeFunc(t *testing.T) {
+	r := NewRegistry()
+	n := 3.0
+	r.NewGaugeFunc("open_connections", "打开的连接数", func() float64 { return n })
+	n = 5
+
+	if out := render(t, r); !strings.Contains(out, "open_connections 5\n") {
+		t.Errorf("输出时应重新取值:\n%s", out)
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("escaped_total", "转义", "path").WithLabelValues("a\"b\\c\n").Inc()
+
+	if out := render(t, r); !strings.Contains(out, `escaped_total{path="a\"b\\c\n"} 1`) {
+		t.Errorf("标签值未正确转义:\n%s", out)
+	}
+}
+
+func TestDuplicateRegistrationPanics(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("dup_total", "")
+	defer func() {
+		if recover() == nil {
+			t.Error("重复注册应 panic")
+		}
+	}()
+	r.NewCounter("dup_total", "")
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("handler_total", "").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type 不正确: %s", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "handler_total 1") {
+		t.Errorf("输出不正确:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,150 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrate.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("无法连接测试数据库: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库实例失败: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return sqlDB
+}
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_posts.up.sql":   {Data: []byte("CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT);")},
+		"0001_create_posts.down.sql": {Data: []byte("DROP TABLE posts;")},
+		"0002_add_body.up.sql":       {Data: []byte("ALTER TABLE posts ADD COLUMN body TEXT;\nCREATE INDEX idx_posts_title ON posts (title);")},
+		"0002_add_body.down.sql":     {Data: []byte("DROP INDEX idx_posts_title;\nALTER TABLE posts DROP COLUMN body;")},
+		"README.md":                  {Data: []byte("忽略非迁移文件")},
+	}
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	db := newTestDB(t)
+	m, err := New(db, "sqlite", testFS())
+	if err != nil {
+		t.Fatalf("创建迁移执行器失败: %v", err)
+	}
+
+	n, err := m.Up()
+	if err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("期望执行 2 个迁移, 得到 %d", n)
+	}
+	if _, err := db.Exec("INSERT INTO posts (title, body) VALUES ('a', 'b')"); err != nil {
+		t.Fatalf("迁移后的表结构不正确: %v", err)
+	}
+
+	// 重复执行不应有变化
+	if n, err := m.Up(); err != nil || n != 0 {
+		t.Errorf("重复执行应跳过已执行的迁移, 得到 %d, %v", n, err)
+	}
+
+	if n, err := m.Down(1); err != nil || n != 1 {
+		t.Fatalf("回滚失败: %d, %v", n, err)
+	}
+	if version, _ := m.Version(); version != 1 {
+		t.Errorf("期望回滚后版本 1, 得到 %d", version)
+	}
+	if _, err := db.Exec("INSERT INTO posts (title, body) VALUES ('a', 'b')"); err == nil {
+		t.Error("回滚后 body 字段应被删除")
+	}
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db := newTestDB(t)
+	fsys := testFS()
+	delete(fsys, "0002_add_body.up.sql")
+	delete(fsys, "0002_add_body.down.sql")
+	m, _ := New(db, "sqlite", fsys)
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	// 新增迁移文件后应显示为未执行
+	m, _ = New(db, "sqlite", testFS())
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatalf("查询迁移状态失败: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("期望 2 个迁移, 得到 %d", len(statuses))
+	}
+	if !statuses[0].Applied || statuses[0].AppliedAt == nil {
+		t.Error("版本 1 应已执行")
+	}
+	if statuses[1].Applied {
+		t.Error("版本 2 应未执行")
+	}
+}
+
+func TestMigrator_AppliedVersion(t *testing.T) {
+	db := newTestDB(t)
+	m, _ := New(db, "sqlite", testFS())
+
+	// 只读查询，未执行过迁移时不创建 schema_migrations 表
+	if _, err := m.AppliedVersion(context.Background()); err == nil {
+		t.Error("schema_migrations 表不存在时应返回错误")
+	}
+	var tables int
+	db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&tables)
+	if tables != 0 {
+		t.Error("就绪检查不应创建 schema_migrations 表")
+	}
+
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	if version, err := m.AppliedVersion(context.Background()); err != nil || version != 2 {
+		t.Errorf("期望版本 2, 得到 %d, %v", version, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.AppliedVersion(ctx); err == nil {
+		t.Error("context 已取消时应返回错误")
+	}
+}
+
+func TestMigrator_FailedMigrationRollsBack(t *testing.T) {
+	db := newTestDB(t)
+	fsys := testFS()
+	fsys["0003_broken.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE broken (id INTEGER);\nNOT VALID SQL;")}
+	m, _ := New(db, "sqlite", fsys)
+
+	if _, err := m.Up(); err == nil {
+		t.Fatal("错误的迁移应返回错误")
+	}
+	if version, _ := m.Version(); version != 2 {
+		t.Errorf("期望停在版本 2, 得到 %d", version)
+	}
+	if _, err := db.Exec("SELECT * FROM broken"); err == nil {
+		t.Error("失败的迁移应整体回滚")
+	}
+}
+
+func TestLoad_MissingUp(t *testing.T) {
+	_, err := Load(fstest.MapFS{
+		"0001_only_down.down.sql": {Data: []byte("SELECT 1;")},
+	})
+	if err == nil {
+		t.Error("缺少 up 文件时应返回错误")
+	}
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// idle 令牌补满所需时间，超过后桶与新建的等价，可以删除
+	idle time.Duration
+}
+
+// MemoryLimiter 进程内限流器，多实例部署时每个实例单独计数
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	calls   int
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (l *MemoryLimiter) Allow(key string, rule Rule) (Result, error) {
+	if !rule.Valid() {
+		return Result{Allowed: true}, nil
+	}
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: rule.capacity(), last: now}
+		l.buckets[key] = b
+	}
+	result, tokens := take(b.tokens, now.Sub(b.last), rule)
+	b.tokens = tokens
+	b.last = now
+	b.idle = time.Duration(rule.capacity() / rule.ratePerSecond() * float64(time.Second))
+
+	l.calls++
+	if l.calls%1000 == 0 {
+		l.prune(now)
+	}
+	return result, nil
+}
+
+// prune 删除已补满的桶，调用方需持有锁
+func (l *MemoryLimiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= b.idle {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Package ratelimit 实现令牌桶限流，支持进程内和Redis两种存储。
+package ratelimit
+
+import "time"
+
+// Rule 令牌桶规则：每 Period 补充 Requests 个令牌，桶容量为 Burst（为0时等于 Requests）
+type Rule struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (r Rule) capacity() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return float64(r.Requests)
+}
+
+// ratePerSecond 每秒补充的令牌数
+func (r Rule) ratePerSecond() float64 {
+	return float64(r.Requests) / r.Period.Seconds()
+}
+
+// Valid 规则是否有效，无效的规则表示不限流
+func (r Rule) Valid() bool {
+	return r.Requests > 0 && r.Period > 0
+}
+
+// Result 一次请求的限流结果
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter 被拒绝时距离下一个令牌可用的时间
+	RetryAfter time.Duration
+}
+
+// Limiter 限流器，key 由调用方区分路由组和用户/IP
+type Limiter interface {
+	Allow(key string, rule Rule) (Result, error)
+}
+
+// take 按令牌桶算法计算新的令牌数，返回结果和更新后的令牌数
+func take(tokens float64, elapsed time.Duration, rule Rule) (Result, float64) {
+	capacity := rule.capacity()
+	rate := rule.ratePerSecond()
+	if elapsed > 0 {
+		tokens += elapsed.Seconds() * rate
+	}
+	if tokens > capacity {
+		tokens = capacity
+	}
+
+	result := Result{Limit: int(capacity)}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+		result.Remaining = int(tokens)
+		return result, tokens
+	}
+	result.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	return result, tokens
+}
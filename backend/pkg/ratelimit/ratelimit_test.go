@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestLimiter(now *time.Time) *MemoryLimiter {
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestMemoryLimiter_Burst(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newTestLimiter(&now)
+	rule := Rule{Requests: 3, Period: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		result, _ := l.Allow("k", rule)
+		if !result.Allowed {
+			t.Fatalf("第 %d 次请求应被允许", i+1)
+		}
+		if result.Remaining != 2-i {
+			t.Errorf("期望剩余 %d, 得到 %d", 2-i, result.Remaining)
+		}
+	}
+
+	result, _ := l.Allow("k", rule)
+	if result.Allowed {
+		t.Fatal("超出限制的请求应被拒绝")
+	}
+	// 每20秒补充一个令牌
+	if result.RetryAfter != 20*time.Second {
+		t.Errorf("期望等待 20s, 得到 %s", result.RetryAfter)
+	}
+
+	// 其他key不受影响
+	if result, _ := l.Allow("other", rule); !result.Allowed {
+		t.Error("不同key应单独计数")
+	}
+}
+
+func TestMemoryLimiter_Refill(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newTestLimiter(&now)
+	rule := Rule{Requests: 2, Period: time.Second}
+
+	l.Allow("k", rule)
+	l.Allow("k", rule)
+	if result, _ := l.Allow("k", rule); result.Allowed {
+		t.Fatal("令牌用完后应被拒绝")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if result, _ := l.Allow("k", rule); !result.Allowed {
+		t.Fatal("补充令牌后应被允许")
+	}
+
+	// 长时间空闲后令牌不超过容量
+	now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		l.Allow("k", rule)
+	}
+	if result, _ := l.Allow("k", rule); result.Allowed {
+		t.Error("令牌数不应超过桶容量")
+	}
+}
+
+func TestMemoryLimiter_InvalidRuleAllows(t *testing.T) {
+	l := NewMemoryLimiter()
+	for i := 0; i < 10; i++ {
+		if result, _ := l.Allow("k", Rule{}); !result.Allowed {
+			t.Fatal("未配置的规则不应限流")
+		}
+	}
+}
+
+func TestMemoryLimiter_Prune(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newTestLimiter(&now)
+	rule := Rule{Requests: 1, Period: time.Second}
+
+	l.Allow("old", rule)
+	now = now.Add(time.Minute)
+	l.prune(now)
+	if _, ok := l.buckets["old"]; ok {
+		t.Error("已补满的桶应被删除")
+	}
+}
@@ -18,5 +18,5 @@ go mod download
 
 # 运行服务
 echo "正在启动服务..."
-go run cmd/api/main.go
+go run ./cmd/api
 